@@ -0,0 +1,16 @@
+package battle
+
+import (
+	"time"
+
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/battle/events"
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
+)
+
+// bridgeUnit forwards u's events into the simulator until the unit stops
+// or the simulator shuts down. Run one per unit as go bs.bridgeUnit(...)
+// after bs.wg.Add(1). The translation itself lives in package events.
+func (bs *BattleSimulator) bridgeUnit(battleID string, u *types.Unit, start time.Time) {
+	defer bs.wg.Done()
+	events.Forward(bs.ctx, u, battleID, start, bs.SendEvent) // A full queue drops the event, as for any sender
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// 🌉 EVENT BRIDGE: Unit Events In, Battle Events Out
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Units publish types.UnitEvent to their own subscribers; the simulator
+// works in BattleEvent. The bridge subscribes to each unit in a battle and
+// translates what it hears, so battle-side consumers (the event processor,
+// the logger, replays) see reloads, casts and deaths without polling units.
+//
+// 🎴 MTG ANALOGY: The judge relaying each player's triggers onto the shared
+// stack, in the stack's own terms.
+//
+// ⚔️ SC:BW ANALOGY: The observer feed turning one Reaver's "scarab built"
+// into a line in the caster's timeline.
+//
+// 🎓 LEARNING: Translation is a pure function (easy to test); the goroutine
+// around it only moves events from one channel to another.
+// ═══════════════════════════════════════════════════════════════════════════
+
+// unitEventKinds maps the unit events the simulator cares about to their
+// battle event type. Anything not listed stays on the unit.
+var unitEventKinds = map[types.UnitEventType]BattleEventType{
+	types.EventMoved:    UnitMoved,
+	types.EventDamaged:  UnitTookDamage,
+	types.EventDied:     UnitDestroyed,
+	types.EventReloaded: UnitReloaded,
+	types.EventCast:     SpecialAbilityUsed,
+}
+
+// TranslateUnitEvent turns ev into a BattleEvent for battleID. Timestamp is
+// simulation time, counted from start. ok is false for events the battle
+// doesn't track.
+func TranslateUnitEvent(ev types.UnitEvent, battleID string, start time.Time) (BattleEvent, bool) {
+	kind, ok := unitEventKinds[ev.Type]
+	if !ok || ev.Source == nil {
+		return BattleEvent{}, false
+	}
+	actors := []string{ev.Source.ID}
+	if ev.Target != nil {
+		actors = append(actors, ev.Target.ID)
+	}
+	return BattleEvent{
+		ID:        fmt.Sprintf("%s/%d", ev.Source.ID, ev.Seq), // Seq is unique per unit
+		Type:      kind,
+		BattleID:  battleID,
+		Timestamp: ev.Timestamp.Sub(start),
+		Actors:    actors,
+		Data:      ev.Data,
+	}, true
+}
+
+// Forward hands u's battle events to send, translated for battleID, until
+// the unit stops or ctx is done. It blocks; run it in its own goroutine.
+// An error from send drops that event and carries on.
+func Forward(ctx context.Context, u *types.Unit, battleID string, start time.Time, send func(BattleEvent) error) {
+	sub := u.Subscribe(func(ev types.UnitEvent) bool {
+		_, ok := unitEventKinds[ev.Type]
+		return ok
+	})
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return // The unit shut down
+			}
+			if be, ok := TranslateUnitEvent(ev, battleID, start); ok {
+				_ = send(be)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
+)
+
+func TestTranslateUnitEvent(t *testing.T) {
+	start := time.Date(1998, time.March, 31, 0, 0, 0, 0, time.UTC)
+	reaver := &types.Unit{ID: "reaver"}
+	ultra := &types.Unit{ID: "ultra"}
+
+	be, ok := TranslateUnitEvent(types.UnitEvent{
+		Seq:       7,
+		Type:      types.EventReloaded,
+		Source:    reaver,
+		Timestamp: start.Add(3 * time.Second),
+		Data:      2,
+	}, "b1", start)
+	require.True(t, ok)
+	require.Equal(t, BattleEvent{
+		ID:        "reaver/7",
+		Type:      UnitReloaded,
+		BattleID:  "b1",
+		Timestamp: 3 * time.Second,
+		Actors:    []string{"reaver"},
+		Data:      2,
+	}, be)
+
+	be, ok = TranslateUnitEvent(types.UnitEvent{Type: types.EventDamaged, Source: ultra, Target: reaver, Timestamp: start}, "b1", start)
+	require.True(t, ok)
+	require.Equal(t, UnitTookDamage, be.Type)
+	require.Equal(t, []string{"ultra", "reaver"}, be.Actors)
+
+	_, ok = TranslateUnitEvent(types.UnitEvent{Type: types.EventIdle, Source: reaver}, "b1", start)
+	require.False(t, ok, "not a battle event")
+	_, ok = TranslateUnitEvent(types.UnitEvent{Type: types.EventReloaded}, "b1", start)
+	require.False(t, ok, "no source to name")
+}
+
+func TestForward(t *testing.T) {
+	start := time.Date(1998, time.March, 31, 0, 0, 0, 0, time.UTC)
+	clock := types.NewManualClock(start)
+	var wg sync.WaitGroup
+	reaver := types.NewUnit("reaver", types.Reaver, types.Position{}, &wg, types.WithClock(clock))
+	ultra := types.NewUnit("ultra", types.Ultralisk, types.Position{X: 1}, &wg, types.WithClock(clock))
+
+	got := make(chan BattleEvent, 256)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Forward(context.Background(), reaver, "b1", start, func(be BattleEvent) error {
+			got <- be
+			return nil
+		})
+	}()
+	result := make(chan error, 1)
+	require.NoError(t, reaver.SendCommand(types.Command{Type: types.CmdAttack, Target: ultra, Result: result}))
+	require.NoError(t, <-result)
+
+	seen := make(map[BattleEventType]BattleEvent)
+	for i := 0; i < 60 && seen[UnitReloaded].ID == ""; i++ {
+		clock.Advance(time.Second)
+		for drained := false; !drained; {
+			select {
+			case be := <-got:
+				seen[be.Type] = be
+			case <-time.After(20 * time.Millisecond):
+				drained = true
+			}
+		}
+	}
+
+	hit := seen[UnitTookDamage]
+	require.Equal(t, []string{"reaver", "ultra"}, hit.Actors)
+	require.Equal(t, "b1", hit.BattleID)
+	reload := seen[UnitReloaded]
+	require.Equal(t, []string{"reaver"}, reload.Actors, "the Reaver's reload reaches the battle")
+	require.Positive(t, reload.Timestamp)
+
+	// The bridge stops with the unit
+	reaver.Shutdown()
+	ultra.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Forward outlived its unit")
+	}
+	wg.Wait()
+}
+
+func TestForward_StopsWithContext(t *testing.T) {
+	var wg sync.WaitGroup
+	reaver := types.NewUnit("reaver", types.Reaver, types.Position{}, &wg)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Forward(ctx, reaver, "b1", time.Now(), func(BattleEvent) error { return nil })
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Forward ignored its context")
+	}
+	reaver.Shutdown()
+	wg.Wait()
+}
@@ -0,0 +1,62 @@
+package events
+
+import "time"
+
+// ═══════════════════════════════════════════════════════════════════════════
+// 📜 BATTLE EVENTS: The Simulator's Vocabulary
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Everything the battle simulator processes, logs or replays is a
+// BattleEvent. The types live in their own package, apart from the
+// simulator, so the pieces that only speak events (the unit bridge, replay
+// tooling) build and test on their own. Package battle re-exports them.
+//
+// 🎴 MTG ANALOGY: The rules' glossary. "Dies", "deals damage" and "casts"
+// mean the same thing whichever card triggered them.
+//
+// ⚔️ SC:BW ANALOGY: The replay file format. The game writes it and any
+// replay viewer can read it without running the game.
+//
+// 🎓 LEARNING: Data types that many packages share belong in a small leaf
+// package; the big package that owns the behaviour imports it.
+// ═══════════════════════════════════════════════════════════════════════════
+
+// BattleEvent represents something that happens during battle
+// LEARNING: Event-driven architecture for real-time systems
+type BattleEvent struct {
+	ID        string          // Unique event ID
+	Type      BattleEventType // What kind of event
+	BattleID  string          // Which battle this belongs to
+	Timestamp time.Duration   // When in simulation time
+	Actors    []string        // Units involved in this event
+	Data      interface{}     // Event-specific data
+	Priority  int             // Processing priority (higher = more urgent)
+}
+
+// BattleEventType represents different types of battle events
+type BattleEventType int
+
+const (
+	// Unit actions
+	UnitMoved BattleEventType = iota
+	UnitAttacked
+	UnitTookDamage
+	UnitDestroyed
+	UnitReloaded
+
+	// Battle flow
+	BattleStarted
+	BattleEnded
+	ReinforcementsArrived
+	ObjectiveCaptured
+
+	// Environmental
+	TerrainChanged
+	WeatherChanged
+
+	// Special events
+	SpecialAbilityUsed // A unit cast a spell (types.EventCast; Data: types.Cast)
+	FormationChanged
+	SupplyDropped
+	// TODO: Add more event types as you expand the simulation
+)
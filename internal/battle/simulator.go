@@ -2,9 +2,12 @@ package battle
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/battle/events"
 	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
 )
 
@@ -28,8 +31,9 @@ type BattleSimulator struct {
 
 	// Real-time processing
 	tickRate time.Duration // Simulation tick interval
-	ticker   *time.Ticker
+	ticker   types.Ticker  // From clock.NewTicker(tickRate)
 	gameTime time.Duration // Current simulation time
+	clock    types.Clock   // Source of time; types.SystemClock unless overridden
 
 	// Battle coordination
 	battleResults chan BattleResult     // Results from completed battles
@@ -42,44 +46,36 @@ type BattleSimulator struct {
 	isRunning bool
 }
 
-// BattleEvent represents something that happens during battle
-// LEARNING: Event-driven architecture for real-time systems
-type BattleEvent struct {
-	ID        string          // Unique event ID
-	Type      BattleEventType // What kind of event
-	BattleID  string          // Which battle this belongs to
-	Timestamp time.Duration   // When in simulation time
-	Actors    []string        // Units involved in this event
-	Data      interface{}     // Event-specific data
-	Priority  int             // Processing priority (higher = more urgent)
-}
+// BattleEvent represents something that happens during battle. The event
+// types live in package events so code that only handles events builds
+// without the simulator.
+type BattleEvent = events.BattleEvent
 
 // BattleEventType represents different types of battle events
-type BattleEventType int
+type BattleEventType = events.BattleEventType
 
 const (
 	// Unit actions
-	UnitMoved BattleEventType = iota
-	UnitAttacked
-	UnitTookDamage
-	UnitDestroyed
-	UnitReloaded
+	UnitMoved      = events.UnitMoved
+	UnitAttacked   = events.UnitAttacked
+	UnitTookDamage = events.UnitTookDamage
+	UnitDestroyed  = events.UnitDestroyed
+	UnitReloaded   = events.UnitReloaded
 
 	// Battle flow
-	BattleStarted
-	BattleEnded
-	ReinforcementsArrived
-	ObjectiveCaptured
+	BattleStarted         = events.BattleStarted
+	BattleEnded           = events.BattleEnded
+	ReinforcementsArrived = events.ReinforcementsArrived
+	ObjectiveCaptured     = events.ObjectiveCaptured
 
 	// Environmental
-	TerrainChanged
-	WeatherChanged
+	TerrainChanged = events.TerrainChanged
+	WeatherChanged = events.WeatherChanged
 
 	// Special events
-	SpecialAbilityUsed
-	FormationChanged
-	SupplyDropped
-	// TODO: Add more event types as you expand the simulation
+	SpecialAbilityUsed = events.SpecialAbilityUsed
+	FormationChanged   = events.FormationChanged
+	SupplyDropped      = events.SupplyDropped
 )
 
 // BattleResult represents the outcome of a completed battle
@@ -142,15 +138,26 @@ const (
 	TickProcessed
 )
 
+// SimulatorOption configures a BattleSimulator in NewBattleSimulator
+type SimulatorOption func(*BattleSimulator)
+
+// WithClock drives the simulator from c instead of the wall clock
+// LEARNING: With a types.ManualClock, a test can play a whole battle tick by
+// tick without waiting on real time
+func WithClock(c types.Clock) SimulatorOption {
+	return func(bs *BattleSimulator) { bs.clock = c }
+}
+
 // NewBattleSimulator creates a new battle simulator
 // LEARNING: Complex system initialization with configurable parameters
-func NewBattleSimulator(ctx context.Context, tickRate time.Duration) *BattleSimulator {
+func NewBattleSimulator(ctx context.Context, tickRate time.Duration, opts ...SimulatorOption) *BattleSimulator {
 	// TODO: Implement battle simulator creation
 	// Steps:
 	// 1. Create child context with cancel
 	// 2. Initialize all maps and channels with appropriate buffer sizes
+	//    (clock defaults to types.SystemClock{}; then apply opts)
 	// 3. Create event processor and logger
-	// 4. Set up ticker for real-time processing
+	// 4. Set up ticker for real-time processing (bs.clock.NewTicker(tickRate))
 	// 5. Start background goroutines:
 	//    - Main simulation loop (go bs.simulationLoop())
 	//    - Event processor (go bs.processEvents())
@@ -170,13 +177,15 @@ func NewBattleSimulator(ctx context.Context, tickRate time.Duration) *BattleSimu
 func (bs *BattleSimulator) CreateBattle(config BattleConfig) (*types.Battle, error) {
 	// TODO: Implement battle creation
 	// Steps:
-	// 1. Validate battle configuration
+	// 1. Validate battle configuration (problems: ErrInvalidConfig with a Reason)
 	// 2. Generate unique battle ID
 	// 3. Create Battle instance with proper setup
 	// 4. Initialize battle state and participants
 	// 5. Add to battles map
-	// 6. Send BattleStarted event
-	// 7. Notify observers
+	// 6. Bridge every participant's events (reloads, casts, deaths) into the
+	//    queue: bs.wg.Add(1); go bs.bridgeUnit(battleID, u, bs.clock.Now())
+	// 7. Send BattleStarted event
+	// 8. Notify observers
 
 	return nil, nil
 }
@@ -197,6 +206,7 @@ type BattleConfig struct {
 type BattleObjective struct {
 	Type        ObjectiveType
 	Target      interface{}   // Specific target (position, unit, etc.)
+	Area        types.Area    // Region for HoldPosition/CaptureArea (Rectangle, Circle, Polygon)
 	Points      int           // Points awarded for completion
 	TimeLimit   time.Duration // Time limit for this objective
 	Description string
@@ -247,7 +257,7 @@ const (
 // EnvironmentalHazard represents dangers on the battlefield
 type EnvironmentalHazard struct {
 	Type     HazardType
-	Area     types.Rectangle
+	Area     types.Area // Units inside (Area.Contains) take the damage
 	Damage   int
 	Interval time.Duration
 }
@@ -264,19 +274,47 @@ const (
 
 // BattleRules defines special rules for battles
 type BattleRules struct {
-	FriendlyFire     bool    // Can units damage allies?
+	FriendlyFire     bool    // Can splash damage hit allies? (apply with World.SetFriendlyFire)
 	Reinforcements   bool    // Are reinforcements allowed?
-	SpecialAbilities bool    // Can units use special abilities?
+	SpecialAbilities bool    // Can units use special abilities? (refuse types.CmdCast when off)
 	TimeScale        float64 // Speed multiplier for simulation
 	PauseOnObjective bool    // Pause when objectives are met?
 }
 
+// Battle errors. Wrapped in a *BattleError naming the battle.
+var (
+	ErrUnknownBattle = errors.New("unknown battle")
+	ErrBattleOver    = errors.New("battle is over")
+	ErrInvalidConfig = errors.New("invalid battle config")
+
+	// Events or battles submitted after Shutdown
+	ErrShuttingDown = types.ErrShuttingDown
+)
+
+// BattleError explains why a battle operation failed
+type BattleError struct {
+	BattleID string
+	Reason   string // Optional detail ("no attackers", "already ended", ...)
+	Err      error
+}
+
+func (e *BattleError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("battle %s: %v", e.BattleID, e.Err)
+	}
+	return fmt.Sprintf("battle %s: %v: %s", e.BattleID, e.Err, e.Reason)
+}
+
+func (e *BattleError) Unwrap() error {
+	return e.Err
+}
+
 // JoinBattle adds units to an existing battle
 // LEARNING: Dynamic participation in ongoing events
 func (bs *BattleSimulator) JoinBattle(battleID string, units []*types.Unit, faction string) error {
 	// TODO: Implement battle joining
 	// Steps:
-	// 1. Find the battle
+	// 1. Find the battle (missing: &BattleError{BattleID: battleID, Err: ErrUnknownBattle})
 	// 2. Validate units can join
 	// 3. Add units to appropriate faction
 	// 4. Send ReinforcementsArrived event
@@ -290,7 +328,7 @@ func (bs *BattleSimulator) JoinBattle(battleID string, units []*types.Unit, fact
 func (bs *BattleSimulator) EndBattle(battleID string, reason string) error {
 	// TODO: Implement battle termination
 	// Steps:
-	// 1. Find the battle
+	// 1. Find the battle (missing: ErrUnknownBattle; already ended: ErrBattleOver)
 	// 2. Calculate final results
 	// 3. Send BattleEnded event
 	// 4. Clean up battle resources
@@ -304,6 +342,7 @@ func (bs *BattleSimulator) EndBattle(battleID string, reason string) error {
 // LEARNING: Thread-safe status reporting
 func (bs *BattleSimulator) GetBattleStatus(battleID string) (*BattleStatus, error) {
 	// TODO: Implement status retrieval with proper locking
+	// Unknown ID: &BattleError{BattleID: battleID, Err: ErrUnknownBattle}
 	return nil, nil
 }
 
@@ -2,6 +2,8 @@ package coordination
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -51,6 +53,7 @@ type Commander struct {
 	cancel   context.CancelFunc
 	wg       *sync.WaitGroup
 	isActive bool
+	clock    types.Clock // From CommanderConfig.Clock
 }
 
 // CommanderRank represents the hierarchy level
@@ -196,7 +199,8 @@ func NewCommander(ctx context.Context, id string, rank CommanderRank, config Com
 	// TODO: Implement commander creation
 	// Steps:
 	// 1. Create child context with cancel
-	// 2. Initialize all maps and channels
+	// 2. Initialize all maps and channels (clock: config.Clock, or
+	//    types.SystemClock{} if nil)
 	// 3. Set up decision engine and tactical AI
 	// 4. Configure communication parameters
 	// 5. Start background goroutines:
@@ -209,6 +213,37 @@ func NewCommander(ctx context.Context, id string, rank CommanderRank, config Com
 	return nil
 }
 
+// Coordination errors. Wrapped in a *CoordinationError naming the commander.
+var (
+	ErrUnknownSubordinate  = errors.New("unknown subordinate")
+	ErrRankTooHigh         = errors.New("subordinate must be of lower rank")
+	ErrTooManySubordinates = errors.New("too many subordinates")
+	ErrNoSuperior          = errors.New("no superior to report to")
+	ErrCommandExpired      = errors.New("command deadline passed")
+
+	// A commander that has stopped, or whose order queue is full
+	ErrShuttingDown = types.ErrShuttingDown
+	ErrBackpressure = types.ErrBackpressure
+)
+
+// CoordinationError explains why a commander refused an operation
+type CoordinationError struct {
+	CommanderID string
+	CommandID   string // Empty when no command is involved
+	Err         error
+}
+
+func (e *CoordinationError) Error() string {
+	if e.CommandID == "" {
+		return fmt.Sprintf("commander %s: %v", e.CommanderID, e.Err)
+	}
+	return fmt.Sprintf("commander %s: command %s: %v", e.CommanderID, e.CommandID, e.Err)
+}
+
+func (e *CoordinationError) Unwrap() error {
+	return e.Err
+}
+
 // CommanderConfig contains configuration for a commander
 type CommanderConfig struct {
 	ResponseTimeout    time.Duration
@@ -218,6 +253,7 @@ type CommanderConfig struct {
 	MaxUnitGroups      int
 	CommunicationRange float64
 	AutoAcknowledge    bool
+	Clock              types.Clock // Source of time; nil means types.SystemClock
 }
 
 // AddSubordinate adds a commander to this commander's hierarchy
@@ -225,8 +261,8 @@ type CommanderConfig struct {
 func (c *Commander) AddSubordinate(subordinate *Commander) error {
 	// TODO: Implement subordinate addition
 	// Steps:
-	// 1. Validate the subordinate
-	// 2. Check rank hierarchy (subordinate must be lower rank)
+	// 1. Validate the subordinate (room left? ErrTooManySubordinates)
+	// 2. Check rank hierarchy (subordinate must be lower rank: ErrRankTooHigh)
 	// 3. Add to subordinates map
 	// 4. Set this commander as subordinate's superior
 	// 5. Establish communication channels
@@ -240,6 +276,7 @@ func (c *Commander) AddSubordinate(subordinate *Commander) error {
 func (c *Commander) RemoveSubordinate(subordinateID string) error {
 	// TODO: Implement subordinate removal
 	// Clean up references and reassign responsibilities
+	// (not ours: &CoordinationError{CommanderID: c.id, Err: ErrUnknownSubordinate})
 	return nil
 }
 
@@ -256,7 +293,7 @@ func (c *Commander) AddUnitGroup(group *UnitGroup) error {
 func (c *Commander) IssueCommand(cmd Command) error {
 	// TODO: Implement command issuance
 	// Steps:
-	// 1. Validate command parameters
+	// 1. Validate command parameters (past Deadline: ErrCommandExpired)
 	// 2. Set command metadata (issued time, source, etc.)
 	// 3. Determine target recipients
 	// 4. Send command via appropriate channels
@@ -278,7 +315,8 @@ func (c *Commander) BroadcastMessage(msg BroadcastMessage) error {
 // LEARNING: Status aggregation and escalation
 func (c *Commander) SendStatusReport(report StatusReport) error {
 	// TODO: Implement status reporting
-	// Send report to superior commander
+	// Send report to superior commander (none: ErrNoSuperior; channel
+	// full: ErrBackpressure)
 	return nil
 }
 
@@ -0,0 +1,271 @@
+package dispatch
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
+)
+
+// ═════════════════════════════════════════════════════════════════════════════
+// 📋 PRIORITY DISPATCH: Who Gets the Next Free Worker
+// ═════════════════════════════════════════════════════════════════════════════
+//
+// A plain FIFO channel serves commands in arrival order, so a retreat order
+// queued behind 400 routine attack-moves waits for all of them. A Queue
+// hands workers the most urgent command instead, with two guarantees on top:
+//
+// 1️⃣ AGING (nobody starves)
+//    Every agingStep a command waits counts as one extra point of priority.
+//    A routine command that has waited long enough outranks a fresh
+//    Emergency one, so a steady stream of urgent traffic can only delay the
+//    routine commands, never bury them.
+//
+// 2️⃣ PER-UNIT ORDER (no overtaking)
+//    Commands for the same unit leave in the order they arrived, and a
+//    unit's next command isn't handed out until the worker holding its
+//    previous one calls Done. Two workers never race to deliver "Move" and
+//    "Stop" to the same Marine.
+//
+//    An urgent command stuck behind routine ones for its own unit lends
+//    them its priority (priority inheritance): the whole lane jumps ahead,
+//    in order, instead of the retreat waiting its turn at routine speed.
+//
+// 💡 MTG: The stack with split second. The most urgent spell resolves first,
+//    but a player's own spells still resolve in the order they were cast.
+//
+// 💡 SC:BW: Pulling workers when Zerglings run into your mineral line. That
+//    click has to land before the 12 queued rally commands—but an SCV told
+//    to "build, then mine" still builds first.
+//
+// 🎓 LEARNING: Aging normally means re-scoring everything in the heap as
+// time passes. Here every command ages at the same rate, so comparing
+// "priority + waited/step" for two commands gives the same answer at any
+// moment as comparing "priority·step − arrival". That key never changes
+// once a command is queued, so a plain container/heap does the job.
+// ═════════════════════════════════════════════════════════════════════════════
+
+// DefaultAgingStep is how long a command waits to gain one point of
+// priority. At 100ms a routine command (0) that has waited 10s outranks a
+// fresh one at priority 100.
+const DefaultAgingStep = 100 * time.Millisecond
+
+// lane is one unit's commands, oldest first. The lane sits in the heap
+// while it has a command to hand out and none out with a worker.
+type lane[T any] struct {
+	unitID  string
+	pending []entry[T]
+	busy    bool  // A worker holds this unit's previous command
+	key     int64 // Most urgent key among pending (priority inheritance)
+	index   int   // Position in the heap, -1 when not in it
+}
+
+// entry is a queued value plus its ordering keys
+type entry[T any] struct {
+	value T
+	key   int64  // Higher = more urgent; see Queue.keyFor
+	seq   uint64 // Arrival order, breaks ties
+}
+
+// laneHeap orders ready lanes most urgent first (container/heap.Interface)
+type laneHeap[T any] []*lane[T]
+
+func (h laneHeap[T]) Len() int { return len(h) }
+
+func (h laneHeap[T]) Less(i, j int) bool {
+	if h[i].key != h[j].key {
+		return h[i].key > h[j].key
+	}
+	return h[i].pending[0].seq < h[j].pending[0].seq
+}
+
+func (h laneHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *laneHeap[T]) Push(x any) {
+	l := x.(*lane[T])
+	l.index = len(*h)
+	*h = append(*h, l)
+}
+
+func (h *laneHeap[T]) Pop() any {
+	old := *h
+	l := old[len(old)-1]
+	old[len(old)-1] = nil
+	l.index = -1
+	*h = old[:len(old)-1]
+	return l
+}
+
+// Queue is a bounded work queue: highest effective priority first, FIFO and
+// one-at-a-time per unit. Safe for concurrent use.
+type Queue[T any] struct {
+	mu        sync.Mutex
+	lanes     map[string]*lane[T]
+	ready     laneHeap[T]
+	size      int // Values queued, not counting ones out with workers
+	capacity  int
+	seq       uint64
+	epoch     time.Time // Keys count time from here, keeping them small
+	agingStep time.Duration
+	clock     types.Clock
+	closed    bool
+	wake      chan struct{} // Signalled (never blocks) when ready may be non-empty
+}
+
+// New creates a queue holding up to capacity values. agingStep is how long
+// a value waits to gain one point of priority; zero or less turns aging off
+// (strict priority, FIFO within a priority).
+func New[T any](capacity int, clock types.Clock, agingStep time.Duration) *Queue[T] {
+	return &Queue[T]{
+		lanes:     make(map[string]*lane[T]),
+		capacity:  capacity,
+		epoch:     clock.Now(),
+		agingStep: agingStep,
+		clock:     clock,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// keyFor scores a value: priority·agingStep − time since epoch, in
+// nanoseconds. Without aging it's just the priority.
+func (q *Queue[T]) keyFor(priority int, at time.Time) int64 {
+	if q.agingStep <= 0 {
+		return int64(priority)
+	}
+	return int64(priority)*int64(q.agingStep) - int64(at.Sub(q.epoch))
+}
+
+// Push queues v for unitID behind any earlier values for the same unit. at
+// is when it was issued; zero means now on the queue's clock. Refuses with
+// types.ErrBackpressure when full and types.ErrShuttingDown once closed.
+func (q *Queue[T]) Push(unitID string, priority int, at time.Time, v T) error {
+	if at.IsZero() {
+		at = q.clock.Now()
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch {
+	case q.closed:
+		return types.ErrShuttingDown
+	case q.size >= q.capacity:
+		return types.ErrBackpressure
+	}
+
+	q.seq++
+	e := entry[T]{value: v, key: q.keyFor(priority, at), seq: q.seq}
+	l, ok := q.lanes[unitID]
+	if !ok {
+		l = &lane[T]{unitID: unitID, key: e.key, index: -1}
+		q.lanes[unitID] = l
+	}
+	l.pending = append(l.pending, e)
+	l.key = max(l.key, e.key)
+	q.size++
+
+	switch {
+	case l.busy:
+		// Becomes ready when the worker calls Done
+	case l.index >= 0:
+		heap.Fix(&q.ready, l.index)
+	default:
+		heap.Push(&q.ready, l)
+		q.signal()
+	}
+	return nil
+}
+
+// Pop takes the most urgent value whose unit has nothing out with a
+// worker, waiting for one if need be. The unit stays busy until Done is
+// called with its ID. ok is false once ctx is done or the queue is closed.
+func (q *Queue[T]) Pop(ctx context.Context) (v T, ok bool) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return v, false
+		}
+		if q.ready.Len() > 0 {
+			l := heap.Pop(&q.ready).(*lane[T])
+			e := l.pending[0]
+			l.pending = l.pending[1:]
+			l.busy = true
+			q.size--
+			if q.ready.Len() > 0 {
+				q.signal() // More work: wake the next popper too
+			}
+			q.mu.Unlock()
+			return e.value, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.wake:
+		case <-ctx.Done():
+			return v, false
+		}
+	}
+}
+
+// Done releases unitID's lane once its value has been handled, so its next
+// value (if any) can go out. A second Done for the same value does nothing.
+func (q *Queue[T]) Done(unitID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l, ok := q.lanes[unitID]
+	if !ok || !l.busy {
+		return // Already released: pushing it again would put it on the heap twice
+	}
+	l.busy = false
+	if len(l.pending) == 0 {
+		delete(q.lanes, unitID)
+		return
+	}
+	l.key = l.pending[0].key
+	for _, e := range l.pending[1:] {
+		l.key = max(l.key, e.key)
+	}
+	heap.Push(&q.ready, l)
+	q.signal()
+}
+
+// Close stops the queue and returns the values that never went out, in
+// arrival order per unit, so the caller can answer them
+func (q *Queue[T]) Close() []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	var left []T
+	for _, l := range q.lanes {
+		for _, e := range l.pending {
+			left = append(left, e.value)
+		}
+	}
+	q.lanes, q.ready, q.size = nil, nil, 0
+	close(q.wake)
+	return left
+}
+
+// Len returns how many values are waiting (not counting ones out with
+// workers)
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// signal wakes one waiting popper without blocking. Caller holds q.mu.
+func (q *Queue[T]) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,196 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
+)
+
+var clockStart = time.Date(1998, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+// order is a queued command in these tests: whose it is and its place in
+// that unit's sequence
+type order struct {
+	unit string
+	n    int
+}
+
+// popNow pops a value that must already be ready
+func popNow(t *testing.T, q *Queue[order]) order {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, ok := q.Pop(ctx)
+	require.True(t, ok, "nothing ready")
+	return v
+}
+
+func TestQueue_EmergencyJumpsAhead(t *testing.T) {
+	clock := types.NewManualClock(clockStart)
+	q := New[order](16, clock, DefaultAgingStep)
+	for i, unit := range []string{"m1", "m2", "m3"} {
+		require.NoError(t, q.Push(unit, 0, time.Time{}, order{unit, i}))
+	}
+	clock.Advance(time.Second) // The routine commands have aged 10 points
+	require.NoError(t, q.Push("scv", 100, time.Time{}, order{"scv", 0}))
+
+	require.Equal(t, order{"scv", 0}, popNow(t, q), "the emergency goes first")
+	require.Equal(t, order{"m1", 0}, popNow(t, q), "then FIFO among equals")
+	require.Equal(t, 2, q.Len())
+
+	// An urgent order behind a routine one for the same unit pulls both
+	// ahead, in order
+	require.NoError(t, q.Push("tank", 0, time.Time{}, order{"tank", 0}))
+	require.NoError(t, q.Push("tank", 100, time.Time{}, order{"tank", 1}))
+	require.Equal(t, order{"tank", 0}, popNow(t, q), "inherits its lane's urgency")
+	q.Done("tank")
+	require.Equal(t, order{"tank", 1}, popNow(t, q))
+	require.Equal(t, order{"m2", 1}, popNow(t, q))
+}
+
+func TestQueue_DoubleDoneKeepsOneInFlight(t *testing.T) {
+	clock := types.NewManualClock(clockStart)
+	q := New[order](16, clock, DefaultAgingStep)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Push("tank", 0, time.Time{}, order{"tank", i}))
+	}
+
+	require.Equal(t, order{"tank", 0}, popNow(t, q))
+	q.Done("tank")
+	q.Done("tank") // A worker reporting twice mustn't free the lane twice
+	require.Equal(t, order{"tank", 1}, popNow(t, q))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, ok := q.Pop(ctx)
+	require.False(t, ok, "tank 2 waits for tank 1's Done")
+	q.Done("tank")
+	require.Equal(t, order{"tank", 2}, popNow(t, q))
+	q.Done("tank")
+	q.Done("tank")
+	require.Zero(t, q.Len())
+}
+
+func TestQueue_AgingPreventsStarvation(t *testing.T) {
+	// Every 100ms an Urgent command arrives and a worker takes one. Without
+	// aging the routine command never gets a turn.
+	served := func(agingStep time.Duration) int {
+		clock := types.NewManualClock(clockStart)
+		q := New[order](16, clock, agingStep)
+		require.NoError(t, q.Push("probe", 0, time.Time{}, order{"probe", 0}))
+		for i := 1; i <= 200; i++ {
+			require.NoError(t, q.Push(fmt.Sprintf("zealot%d", i), 50, time.Time{}, order{"zealot", i}))
+			if v := popNow(t, q); v.unit == "probe" {
+				return i
+			}
+			clock.Advance(100 * time.Millisecond)
+		}
+		return -1
+	}
+	require.Equal(t, -1, served(0), "strict priority starves it")
+	n := served(DefaultAgingStep)
+	require.Positive(t, n)
+	require.LessOrEqual(t, n, 52, "50 points of priority is 5s of waiting")
+}
+
+func TestQueue_PerUnitOrderAcrossWorkers(t *testing.T) {
+	const (
+		units   = 4
+		perUnit = 200
+		workers = 8
+	)
+	q := New[order](units*perUnit, types.SystemClock{}, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		seen     = make(map[string][]int)
+		inFlight = make(map[string]*atomic.Bool)
+		overlaps atomic.Int32
+		handled  sync.WaitGroup
+		running  sync.WaitGroup
+	)
+	for u := 0; u < units; u++ {
+		inFlight[fmt.Sprintf("u%d", u)] = new(atomic.Bool)
+	}
+	handled.Add(units * perUnit)
+	for w := 0; w < workers; w++ {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			for {
+				v, ok := q.Pop(ctx)
+				if !ok {
+					return
+				}
+				if !inFlight[v.unit].CompareAndSwap(false, true) {
+					overlaps.Add(1)
+				}
+				mu.Lock()
+				seen[v.unit] = append(seen[v.unit], v.n)
+				mu.Unlock()
+				time.Sleep(time.Duration(rand.Intn(50)) * time.Microsecond)
+				inFlight[v.unit].Store(false)
+				q.Done(v.unit)
+				handled.Done()
+			}
+		}()
+	}
+
+	// Producers push concurrently with random priorities; each unit's own
+	// commands come from one producer, so their order is well defined
+	var producers sync.WaitGroup
+	for u := 0; u < units; u++ {
+		producers.Add(1)
+		go func(unit string) {
+			defer producers.Done()
+			for n := 0; n < perUnit; n++ {
+				if err := q.Push(unit, rand.Intn(101), time.Time{}, order{unit, n}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(fmt.Sprintf("u%d", u))
+	}
+	producers.Wait()
+	handled.Wait()
+	cancel()
+	running.Wait()
+
+	require.Zero(t, overlaps.Load(), "two workers held the same unit")
+	for unit, got := range seen {
+		require.Len(t, got, perUnit, unit)
+		for i, n := range got {
+			require.Equal(t, i, n, "%s ran out of order", unit)
+		}
+	}
+}
+
+func TestQueue_BackpressureAndClose(t *testing.T) {
+	q := New[order](2, types.SystemClock{}, DefaultAgingStep)
+	require.NoError(t, q.Push("a", 0, time.Time{}, order{"a", 0}))
+	require.NoError(t, q.Push("a", 0, time.Time{}, order{"a", 1}))
+	require.ErrorIs(t, q.Push("b", 100, time.Time{}, order{"b", 0}), types.ErrBackpressure)
+
+	require.Equal(t, order{"a", 0}, popNow(t, q))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, ok := q.Pop(ctx)
+	require.False(t, ok, "a's next waits for Done; the context gives up first")
+
+	require.Equal(t, []order{{"a", 1}}, q.Close())
+	require.Empty(t, q.Close())
+	require.ErrorIs(t, q.Push("b", 0, time.Time{}, order{"b", 0}), types.ErrShuttingDown)
+	_, ok = q.Pop(context.Background())
+	require.False(t, ok)
+	q.Done("a") // Harmless after Close
+}
@@ -2,6 +2,8 @@ package resources
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -41,6 +43,7 @@ type ResourceManager struct {
 	cancel    context.CancelFunc
 	wg        *sync.WaitGroup
 	isRunning bool
+	clock     types.Clock // Timestamps, expiries and generator ticks
 }
 
 // ResourceTransaction represents an atomic resource operation
@@ -125,13 +128,57 @@ const (
 	Critical
 )
 
+// Resource errors. Reported in TransactionResult.Error (or returned) as a
+// *ResourceError naming the requester and resource.
+var (
+	ErrInsufficientResources = errors.New("insufficient resources")
+	ErrUnknownResource       = errors.New("unknown resource")
+	ErrDuplicateResource     = errors.New("resource already exists")
+	ErrUnknownReservation    = errors.New("unknown reservation")
+	ErrReservationExpired    = errors.New("reservation expired")
+	ErrSpendTimeout          = errors.New("no answer to spend in time")
+
+	// Transactions sent after Shutdown
+	ErrShuttingDown = types.ErrShuttingDown
+)
+
+// ResourceError explains why a transaction or reservation failed
+type ResourceError struct {
+	Requester string // Who asked
+	Resource  string // Which resource ran short or wasn't found
+	Need      int    // For ErrInsufficientResources: amount asked for
+	Have      int    // For ErrInsufficientResources: amount available
+	Err       error
+}
+
+func (e *ResourceError) Error() string {
+	if errors.Is(e.Err, ErrInsufficientResources) {
+		return fmt.Sprintf("%s: %s: %v (need %d, have %d)", e.Requester, e.Resource, e.Err, e.Need, e.Have)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.Requester, e.Resource, e.Err)
+}
+
+func (e *ResourceError) Unwrap() error {
+	return e.Err
+}
+
+// ManagerOption configures a ResourceManager in NewResourceManager
+type ManagerOption func(*ResourceManager)
+
+// WithClock runs the manager on c instead of the wall clock
+// LEARNING: Transaction expiry and generator income become testable without sleeping
+func WithClock(c types.Clock) ManagerOption {
+	return func(rm *ResourceManager) { rm.clock = c }
+}
+
 // NewResourceManager creates a new resource manager
 // LEARNING: Complex system initialization with multiple concurrent components
-func NewResourceManager(ctx context.Context, initialResources map[string]int) *ResourceManager {
+func NewResourceManager(ctx context.Context, initialResources map[string]int, opts ...ManagerOption) *ResourceManager {
 	// TODO: Implement resource manager creation
 	// Steps:
 	// 1. Create child context with cancel
 	// 2. Initialize all maps and channels
+	//    (clock defaults to types.SystemClock{}; then apply opts)
 	// 3. Create initial resources from the map
 	// 4. Initialize rate limiter
 	// 5. Start background goroutines:
@@ -177,6 +224,41 @@ func (rm *ResourceManager) AllocateResources(requester string, resources map[str
 	return response
 }
 
+// SpendTimeout bounds how long Spend waits for its allocation
+const SpendTimeout = 5 * time.Second
+
+var _ types.Treasury = (*ResourceManager)(nil)
+
+// Spend pays cost all at once or not at all, waiting for the result.
+// This is how a Faction pays for upgrade research (types.Treasury).
+// A manager that isn't running refuses with ErrShuttingDown straight away,
+// and one that never answers gives up after SpendTimeout.
+// LEARNING: A blocking facade over the channel-based API for callers that
+// can't continue until they know whether they paid
+func (rm *ResourceManager) Spend(requester string, cost map[string]int) error {
+	rm.mu.RLock()
+	ctx, running, clock := rm.ctx, rm.isRunning, rm.clock
+	rm.mu.RUnlock()
+	if ctx == nil || !running {
+		return &ResourceError{Requester: requester, Err: ErrShuttingDown}
+	}
+	if clock == nil {
+		clock = types.SystemClock{}
+	}
+
+	select {
+	case result := <-rm.AllocateResources(requester, cost, SpendTimeout):
+		if !result.Success && result.Error == nil {
+			return &ResourceError{Requester: requester, Err: ErrInsufficientResources}
+		}
+		return result.Error
+	case <-ctx.Done():
+		return &ResourceError{Requester: requester, Err: ErrShuttingDown}
+	case <-clock.After(SpendTimeout):
+		return &ResourceError{Requester: requester, Err: ErrSpendTimeout}
+	}
+}
+
 // ReserveResources reserves resources for future use
 // LEARNING: Two-phase resource allocation pattern
 func (rm *ResourceManager) ReserveResources(requester string, resources map[string]int, timeout time.Duration) <-chan TransactionResult {
@@ -197,6 +279,7 @@ func (rm *ResourceManager) ReserveResources(requester string, resources map[stri
 func (rm *ResourceManager) ReleaseReservation(reservationID string) error {
 	// TODO: Implement reservation release
 	// Find the reservation and make resources available again
+	// (not found: *ResourceError wrapping ErrUnknownReservation)
 	return nil
 }
 
@@ -205,6 +288,7 @@ func (rm *ResourceManager) ReleaseReservation(reservationID string) error {
 func (rm *ResourceManager) ConsumeReserved(reservationID string) error {
 	// TODO: Implement reserved resource consumption
 	// Convert reservation to actual consumption
+	// (not found: ErrUnknownReservation; past ExpiresAt: ErrReservationExpired)
 	return nil
 }
 
@@ -420,7 +504,7 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 func (rm *ResourceManager) validateTransaction(tx ResourceTransaction) error {
 	// TODO: Implement transaction validation
 	// Check for:
-	// - Valid resource names
+	// - Valid resource names (ErrUnknownResource)
 	// - Reasonable amounts
 	// - Proper transaction type
 	// - Timeout values
@@ -432,6 +516,8 @@ func (rm *ResourceManager) executeTransaction(tx ResourceTransaction) Transactio
 	// TODO: Implement transaction execution
 	// This is where the actual resource changes happen
 	// Must be atomic and consistent
+	// Coming up short fails the whole transaction with a *ResourceError
+	// wrapping ErrInsufficientResources (Need/Have for the first shortfall)
 	return TransactionResult{}
 }
 
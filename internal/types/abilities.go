@@ -0,0 +1,395 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// ABILITIES - Spending Energy
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Casters spend energy on spells. Every spell has the same shape:
+//
+//	EnergyCost  Paid when the spell goes off, not when it's ordered
+//	Cooldown    How long before the same caster can cast it again
+//	Range       How close the caster must get, in tiles
+//	Targeting   A unit, a point on the ground, or the caster itself
+//	Duration    How long the effect lasts (0 = it happens at once)
+//
+// CmdCast{Ability, Target or Dest} checks everything it can up front—the
+// caster knows the spell, has the energy, is off cooldown, and can target
+// what it was pointed at—then walks into range and casts (state Casting).
+// Anything that changes on the way, like the target dying or an EMP
+// draining the energy, fails the order with EventOrderFailed. The spells
+// themselves live in spells.go.
+//
+// 🎴 MTG ANALOGY: Mana abilities with summoning sickness. The caster taps
+// energy instead of lands, and the cooldown is the "activate only once
+// each turn" clause.
+//
+// ⚔️ SC:BW ANALOGY: Hotkeying a Science Vessel, hitting I, clicking the
+// Mutalisk. The Vessel flies over on its own and the spell goes off the
+// moment it's close enough—if the Muta is still alive by then.
+//
+// 🎓 LEARNING: The spell table is plain data (abilities below), and the
+// order is a normal order: the handler validates, tick does the walking,
+// orderDone moves on. A new spell is a table entry plus its effect.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// AbilityType identifies a spell
+type AbilityType int
+
+const (
+	Heal AbilityType = iota
+	Irradiate
+	EMPShockwave
+	DefensiveMatrix
+	PsionicStorm
+	DarkSwarm
+	Plague
+	StasisField
+
+	abilityTypeCount
+)
+
+var _ fmt.Stringer = AbilityType(0)
+
+var abilityTypeNames = map[AbilityType]string{
+	Heal:            "Heal",
+	Irradiate:       "Irradiate",
+	EMPShockwave:    "EMPShockwave",
+	DefensiveMatrix: "DefensiveMatrix",
+	PsionicStorm:    "PsionicStorm",
+	DarkSwarm:       "DarkSwarm",
+	Plague:          "Plague",
+	StasisField:     "StasisField",
+}
+
+func (at AbilityType) String() string {
+	if name, ok := abilityTypeNames[at]; ok {
+		return name
+	}
+	return fmt.Sprintf("AbilityType(%d)", at)
+}
+
+func (at AbilityType) IsValid() bool {
+	return at >= Heal && at < abilityTypeCount
+}
+
+// parseAbilityType maps the stat sheet spelling to the enum
+func parseAbilityType(name string) (AbilityType, bool) {
+	for at, n := range abilityTypeNames {
+		if n == name {
+			return at, true
+		}
+	}
+	return Heal, false
+}
+
+// TargetMode is what a spell is aimed at
+type TargetMode int
+
+const (
+	TargetUnit  TargetMode = iota // Command.Target
+	TargetPoint                   // Command.Dest
+	TargetSelf                    // The caster; Target and Dest are ignored
+
+	targetModeCount
+)
+
+var _ fmt.Stringer = TargetMode(0)
+
+var targetModeNames = map[TargetMode]string{
+	TargetUnit:  "Unit",
+	TargetPoint: "Point",
+	TargetSelf:  "Self",
+}
+
+func (tm TargetMode) String() string {
+	if name, ok := targetModeNames[tm]; ok {
+		return name
+	}
+	return fmt.Sprintf("TargetMode(%d)", tm)
+}
+
+func (tm TargetMode) IsValid() bool {
+	return tm >= TargetUnit && tm < targetModeCount
+}
+
+// Ability describes a spell
+type Ability struct {
+	Type       AbilityType
+	EnergyCost int
+	Cooldown   time.Duration
+	Range      float64 // Tiles; edge to edge for unit targets
+	Targeting  TargetMode
+	Radius     float64       // Area of effect in tiles (0 = just the target)
+	Duration   time.Duration // 0 = instant
+	Amount     float64       // Healing, total damage, or damage absorbed—see spells.go
+}
+
+// abilities is the spell table
+var abilities = map[AbilityType]Ability{
+	Heal:            {Type: Heal, EnergyCost: 5, Cooldown: 500 * time.Millisecond, Range: 2, Targeting: TargetUnit, Amount: 10},
+	Irradiate:       {Type: Irradiate, EnergyCost: 75, Cooldown: time.Second, Range: 9, Targeting: TargetUnit, Radius: 1, Duration: 30 * time.Second, Amount: 250},
+	EMPShockwave:    {Type: EMPShockwave, EnergyCost: 100, Cooldown: time.Second, Range: 8, Targeting: TargetPoint, Radius: 2},
+	DefensiveMatrix: {Type: DefensiveMatrix, EnergyCost: 100, Cooldown: time.Second, Range: 10, Targeting: TargetUnit, Duration: 60 * time.Second, Amount: 250},
+	PsionicStorm:    {Type: PsionicStorm, EnergyCost: 75, Cooldown: time.Second, Range: 9, Targeting: TargetPoint, Radius: 1.5, Duration: 3 * time.Second, Amount: 112},
+	DarkSwarm:       {Type: DarkSwarm, EnergyCost: 100, Cooldown: time.Second, Range: 9, Targeting: TargetPoint, Radius: 3, Duration: 60 * time.Second},
+	Plague:          {Type: Plague, EnergyCost: 150, Cooldown: time.Second, Range: 9, Targeting: TargetPoint, Radius: 1.5, Duration: 25 * time.Second, Amount: 300},
+	StasisField:     {Type: StasisField, EnergyCost: 100, Cooldown: time.Second, Range: 9, Targeting: TargetPoint, Radius: 1.5, Duration: 30 * time.Second},
+}
+
+// LookupAbility returns the spell's costs and reach
+func LookupAbility(at AbilityType) (Ability, bool) {
+	a, ok := abilities[at]
+	return a, ok
+}
+
+// Ability errors. Wrapped in an *OrderError; a bad unit target is a
+// *TargetingError. Running short of energy is ErrNotEnoughEnergy (see
+// cloak.go).
+var (
+	ErrCannotCast = errors.New("cannot cast")
+	ErrOnCooldown = errors.New("ability on cooldown")
+)
+
+// Cast describes a spell going off (Data for EventCast)
+type Cast struct {
+	Ability AbilityType
+	Target  *Unit    // The unit aimed at (the caster for TargetSelf); nil for TargetPoint
+	Dest    Position // Where the spell landed
+}
+
+// validateAbilities checks every abilities entry names a spell, and that
+// the unit has the energy to cast them
+func validateAbilities(names []string, energy *EnergyStatsData) error {
+	var errs []error
+	for _, name := range names {
+		if _, ok := parseAbilityType(name); !ok {
+			errs = append(errs, fmt.Errorf("abilities: unknown ability %q", name))
+		}
+	}
+	if len(names) > 0 && energy == nil {
+		errs = append(errs, errors.New("abilities need energy"))
+	}
+	return errors.Join(errs...)
+}
+
+// applyAbilityStats sets up the spells a unit knows. Cooldowns start over.
+func applyAbilityStats(u *Unit, stats UnitStatsData) {
+	u.abilities = nil
+	for _, name := range stats.Abilities {
+		if at, ok := parseAbilityType(name); ok {
+			u.abilities = append(u.abilities, at)
+		}
+	}
+	u.castReady = nil
+}
+
+// Abilities returns the spells the unit knows
+func (u *Unit) Abilities() []AbilityType {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return append([]AbilityType(nil), u.abilities...)
+}
+
+// CanCast reports whether the unit knows the spell (energy and cooldown
+// aside)
+func (u *Unit) CanCast(at AbilityType) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.knowsLocked(at)
+}
+
+// knowsLocked is CanCast for callers that already hold u.mu
+func (u *Unit) knowsLocked(at AbilityType) bool {
+	for _, a := range u.abilities {
+		if a == at {
+			return true
+		}
+	}
+	return false
+}
+
+// CooldownRemaining returns how long until the unit can cast the spell
+// again; 0 if it can now
+func (u *Unit) CooldownRemaining(at AbilityType) time.Duration {
+	now := u.clock.Now()
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if left := u.castReady[at].Sub(now); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// castTarget is the unit cmd aims at: the caster itself for TargetSelf
+// spells, cmd.Target for unit spells, nil for point spells
+func (u *Unit) castTarget(spell Ability, cmd Command) *Unit {
+	switch spell.Targeting {
+	case TargetSelf:
+		return u
+	case TargetUnit:
+		return cmd.Target
+	default:
+		return nil
+	}
+}
+
+// castPoint is where the spell lands: the target's position, or cmd.Dest
+func (u *Unit) castPoint(spell Ability, cmd Command) Position {
+	if target := u.castTarget(spell, cmd); target != nil {
+		return target.GetPosition()
+	}
+	return cmd.Dest
+}
+
+// castError explains why the unit can't cast cmd right now, or returns nil.
+// Checked when the order arrives and again when the spell goes off.
+func (u *Unit) castError(cmd Command, now time.Time) error {
+	cannot := func(err error, format string, args ...interface{}) error {
+		return &OrderError{UnitID: u.ID, Order: CmdCast, Reason: fmt.Sprintf(format, args...), Err: err}
+	}
+	spell, ok := LookupAbility(cmd.Ability)
+	if !ok {
+		return cannot(ErrCannotCast, "unknown ability %s", cmd.Ability)
+	}
+
+	u.mu.RLock()
+	ut, knows, burrowed := u.Type, u.knowsLocked(cmd.Ability), u.elevationLayer == Burrowed
+	energy, ready := u.energy, u.castReady[cmd.Ability]
+	u.mu.RUnlock()
+	switch {
+	case !knows:
+		return cannot(ErrCannotCast, "%s can't cast %s", ut, cmd.Ability)
+	case burrowed:
+		return cannot(ErrCannotCast, "can't cast while burrowed")
+	}
+
+	if spell.Targeting == TargetUnit {
+		if err := u.spellTargetError(spell, cmd.Target); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case energy < float64(spell.EnergyCost):
+		return cannot(ErrNotEnoughEnergy, "need %d energy, have %.0f", spell.EnergyCost, energy)
+	case now.Before(ready):
+		return cannot(ErrOnCooldown, "%s ready in %s", cmd.Ability, ready.Sub(now))
+	}
+	return nil
+}
+
+// spellTargetError explains why target can't be the target of spell, or
+// returns nil. Hidden enemies need detecting, like they do for weapons.
+func (u *Unit) spellTargetError(spell Ability, target *Unit) error {
+	if target == nil {
+		return &TargetingError{UnitID: u.ID, Err: ErrNoTarget}
+	}
+	cannot := func(err error, reason string) error {
+		return &TargetingError{UnitID: u.ID, TargetID: target.ID, Layer: target.GetElevationLayer(), Reason: reason, Err: err}
+	}
+	switch {
+	case target.IsDead():
+		return cannot(ErrUnitDead, "target is dead")
+	case target.IsLoaded():
+		return cannot(ErrCannotTarget, "target is inside a transport")
+	case target.InStasis():
+		return cannot(ErrCannotTarget, "target is in stasis")
+	case IsEnemy(u, target) && target.hidden() && !u.detects(target):
+		return cannot(ErrCannotTarget, "target is not detected")
+	}
+
+	if spell.Type == Heal {
+		refuse := func(format string, args ...interface{}) error {
+			return &OrderError{UnitID: u.ID, Order: CmdCast, Reason: fmt.Sprintf(format, args...), Err: ErrCannotCast}
+		}
+		switch {
+		case target == u:
+			return refuse("can't heal itself")
+		case IsEnemy(u, target):
+			return refuse("%s is an enemy", target.ID)
+		case target.IsMechanical():
+			return refuse("%s is mechanical", target.GetType())
+		}
+	}
+	return u.checkVision(target)
+}
+
+// castInRange reports whether the caster is close enough to cast cmd
+func (u *Unit) castInRange(spell Ability, cmd Command) bool {
+	if target := u.castTarget(spell, cmd); target != nil {
+		return target == u || u.inReach(target, spell.Range)
+	}
+	reach := spell.Range + u.GetSize().Radius()
+	return u.GetPosition().DistanceSq(cmd.Dest) <= reach*reach
+}
+
+// handleCast starts a cast order. The caster walks into range on its own
+// (see continueCast); the energy is only spent once the spell goes off.
+func (u *Unit) handleCast(cmd Command) error {
+	now := u.clock.Now()
+	if err := u.castError(cmd, now); err != nil {
+		return err
+	}
+
+	spell, _ := LookupAbility(cmd.Ability)
+	u.mu.Lock()
+	if u.state != Moving {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Casting
+	u.target = u.castTarget(spell, cmd)
+	u.mu.Unlock()
+	return nil
+}
+
+// continueCast closes in on the target, then casts and moves on to the
+// next order. If the spell can no longer be cast the order fails with
+// EventOrderFailed.
+func (u *Unit) continueCast(now time.Time) {
+	u.mu.RLock()
+	cmd := u.order
+	u.mu.RUnlock()
+	spell, _ := LookupAbility(cmd.Ability)
+
+	if err := u.castError(cmd, now); err != nil {
+		u.emit(UnitEvent{Type: EventOrderFailed, Source: u, Target: cmd.Target, Timestamp: now, Data: err})
+		u.orderDone(now)
+		return
+	}
+	if !u.castInRange(spell, cmd) {
+		if err := u.approach(u.castPoint(spell, cmd), now); err != nil {
+			u.emit(UnitEvent{Type: EventOrderFailed, Source: u, Target: cmd.Target, Timestamp: now, Data: err})
+			u.orderDone(now)
+		}
+		return
+	}
+	u.halt(now)
+	u.cast(spell, cmd, now)
+	u.orderDone(now)
+}
+
+// cast pays for the spell and sets it off
+func (u *Unit) cast(spell Ability, cmd Command, now time.Time) {
+	target, at := u.castTarget(spell, cmd), u.castPoint(spell, cmd)
+	u.mu.Lock()
+	u.energy -= float64(spell.EnergyCost)
+	if spell.Cooldown > 0 {
+		if u.castReady == nil {
+			u.castReady = make(map[AbilityType]time.Time)
+		}
+		u.castReady[spell.Type] = now.Add(spell.Cooldown)
+	}
+	world := u.world
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventCast, Source: u, Target: target, Timestamp: now, Data: Cast{Ability: spell.Type, Target: target, Dest: at}})
+	u.resolveSpell(world, spell, target, at, now)
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setEnergy tops a caster up (or down) for a test
+func setEnergy(u *Unit, energy float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.energy = energy
+}
+
+func TestAbilities_Sheet(t *testing.T) {
+	var wg sync.WaitGroup
+	vessel := NewUnit("vessel", ScienceVessel, Position{}, &wg)
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+
+	require.Equal(t, []AbilityType{Irradiate, EMPShockwave, DefensiveMatrix}, vessel.Abilities())
+	require.True(t, vessel.CanCast(DefensiveMatrix))
+	require.False(t, vessel.CanCast(PsionicStorm))
+	require.Empty(t, marine.Abilities())
+	require.Equal(t, "StasisField", StasisField.String())
+	require.Equal(t, "Point", TargetPoint.String())
+
+	storm, ok := LookupAbility(PsionicStorm)
+	require.True(t, ok)
+	require.Equal(t, TargetPoint, storm.Targeting)
+	require.Equal(t, 75, storm.EnergyCost)
+
+	err := UnitStatsData{MaxHealth: 40, VisionRange: 7, Speed: 1, BuildTime: 1, ElevationLayer: "Ground", UnitSize: "Small",
+		Abilities: []string{"Lockdown"}}.Validate()
+	require.ErrorContains(t, err, `abilities: unknown ability "Lockdown"`)
+	require.ErrorContains(t, err, "abilities need energy")
+
+	cmd := Command{Type: CmdCast, Ability: Irradiate, Target: marine}
+	require.Equal(t, "Cast Irradiate on marine", cmd.String())
+	cmd = Command{Type: CmdCast, Ability: PsionicStorm, Dest: Position{X: 3, Y: 4}, Queue: true}
+	require.Equal(t, "Queue Cast PsionicStorm at (3.0, 4.0)", cmd.String())
+
+	vessel.Shutdown()
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestCast_Refusals(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	medic := NewUnit("medic", Medic, Position{}, &wg, WithClock(clock), WithOwner(red))
+	vessel := NewUnit("vessel", ScienceVessel, Position{X: 1}, &wg, WithClock(clock), WithOwner(red))
+	marine := NewUnit("marine", Marine, Position{X: 2}, &wg, WithClock(clock), WithOwner(red))
+	ling := NewUnit("ling", Zergling, Position{X: 3}, &wg, WithClock(clock), WithOwner(teal))
+	units := []*Unit{medic, vessel, marine, ling}
+	for _, u := range units {
+		world.Add(u)
+	}
+
+	cast := func(u *Unit, at AbilityType, target *Unit) error {
+		return sendOrder(t, u, Command{Type: CmdCast, Ability: at, Target: target})
+	}
+	err := cast(marine, Heal, medic)
+	require.ErrorIs(t, err, ErrCannotCast)
+	require.ErrorContains(t, err, "Marine can't cast Heal")
+	require.ErrorContains(t, cast(medic, Heal, medic), "can't heal itself")
+	require.ErrorContains(t, cast(medic, Heal, ling), "ling is an enemy")
+	require.ErrorContains(t, cast(medic, Heal, vessel), "ScienceVessel is mechanical")
+	require.ErrorIs(t, cast(vessel, Irradiate, nil), ErrNoTarget)
+
+	err = cast(vessel, Irradiate, ling)
+	require.ErrorIs(t, err, ErrNotEnoughEnergy)
+	require.ErrorContains(t, err, "need 75 energy, have 50")
+
+	require.NoError(t, sendOrder(t, ling, Command{Type: CmdBurrow}))
+	medic.SetPosition(Position{X: 50}) // The Vessel is the only detector around
+	vessel.SetPosition(Position{X: 50})
+	setEnergy(vessel, 200)
+	err = cast(vessel, Irradiate, ling)
+	require.ErrorIs(t, err, ErrCannotTarget, "burrowed and out of detection range")
+	require.Equal(t, Idle, vessel.GetState())
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestCast_WalksIntoRangeThenCasts(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	templar := NewUnit("templar", Templar, Position{}, &wg, WithClock(clock))
+	world.Add(templar)
+	setEnergy(templar, 200)
+	sub := templar.Subscribe(EventTypes(EventCast), WithBuffer(4))
+
+	dest := Position{X: 20}
+	require.NoError(t, sendOrder(t, templar, Command{Type: CmdCast, Ability: PsionicStorm, Dest: dest}))
+	require.Equal(t, Casting, templar.GetState())
+	require.Equal(t, 200, templar.GetEnergy(), "paid when the spell goes off")
+
+	advanceUntil(t, clock, func() bool { return templar.GetState() == Idle })
+	ev := waitForEvent(t, sub, EventCast)
+	require.Equal(t, Cast{Ability: PsionicStorm, Dest: dest}, ev.Data)
+	require.InDelta(t, 20-9-0.25, templar.GetPosition().X, 0.2, "stops once in range")
+	require.InDelta(t, 125, templar.GetEnergyExact(), 0.1)
+	require.Positive(t, templar.CooldownRemaining(PsionicStorm))
+
+	err := sendOrder(t, templar, Command{Type: CmdCast, Ability: PsionicStorm, Dest: dest})
+	require.ErrorIs(t, err, ErrOnCooldown)
+	clock.Advance(time.Second)
+	require.Zero(t, templar.CooldownRemaining(PsionicStorm))
+	require.NoError(t, sendOrder(t, templar, Command{Type: CmdCast, Ability: PsionicStorm, Dest: dest}))
+
+	templar.Shutdown()
+	wg.Wait()
+}
+
+func TestCast_TargetDiesOnTheWay(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	vessel := NewUnit("vessel", ScienceVessel, Position{}, &wg, WithClock(clock))
+	marine := NewUnit("marine", Marine, Position{X: 10}, &wg, WithClock(clock))
+	setEnergy(vessel, 200)
+	failed := vessel.Subscribe(EventTypes(EventOrderFailed), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, vessel, Command{Type: CmdCast, Ability: DefensiveMatrix, Target: marine}))
+	require.Equal(t, Casting, vessel.GetState())
+	marine.TakeDamage(100)
+
+	clock.Advance(TickRate)
+	ev := waitForEvent(t, failed, EventOrderFailed)
+	require.ErrorIs(t, ev.Data.(error), ErrUnitDead)
+	require.Eventually(t, func() bool { return vessel.GetState() == Idle }, time.Second, time.Millisecond)
+	require.GreaterOrEqual(t, vessel.GetEnergy(), 200, "nothing spent")
+
+	vessel.Shutdown()
+	wg.Wait()
+}
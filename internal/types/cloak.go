@@ -0,0 +1,207 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// CLOAK & DETECTION - You Can't Shoot What You Can't See
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Hidden units—cloaked or burrowed—can't be targeted unless the attacker's
+// side has them detected:
+//
+//   - Always cloaked: Dark Templar, Observer, Arbiter.
+//   - Cloak on demand: the Wraith. CmdCloak costs CloakEnergyCost up front
+//     and drains CloakEnergyDrain per second (energy doesn't regenerate
+//     while it's on); it drops off on its own when the energy runs out.
+//     CmdCloak and CmdDecloak take effect at once and don't interrupt
+//     what the unit is doing.
+//   - Burrowed units (see transform.go) are hidden the same way.
+//
+// Detectors—Observer, Science Vessel, Overlord, and any stat sheet entry
+// with "detector": true—detect hidden units within their vision range. In
+// a World detection belongs to the whole side: one Observer covers every
+// Dragoon around it, and sharing vision shares detection.
+//
+// 🎴 MTG ANALOGY: Shroud, and the cards that say "creatures you control
+// lose shroud". A hidden unit is there, it just can't be the target of
+// anything until a detector switches the protection off.
+//
+// ⚔️ SC:BW ANALOGY: The first Dark Templar walking into a Terran base
+// with no turret. Or the Observer over the Lurker line that lets the
+// Dragoons finally fight back—kill the Observer and they're blind again.
+//
+// 🎓 LEARNING: Detection is asked fresh every time an attack is checked,
+// including every tick of a running attack order. When the detector
+// dies mid-fight, the next tick fails the order. No subscriptions, no
+// invalidation: the question is cheap, so just ask it again.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Wraith cloak costs
+const (
+	CloakEnergyCost  = 25  // Energy to switch the cloak on
+	CloakEnergyDrain = 1.0 // Energy per second while it stays on
+)
+
+// Cloak errors. Wrapped in an *OrderError.
+var (
+	ErrCannotCloak     = errors.New("cannot cloak")
+	ErrNotEnoughEnergy = errors.New("not enough energy")
+)
+
+// applyCloakStats sets up cloaking and detection. A permanently cloaked
+// unit starts cloaked.
+func applyCloakStats(u *Unit, stats UnitStatsData) {
+	u.permanentCloak = stats.Cloaked
+	u.cloaked = stats.Cloaked
+	u.canCloak = stats.CanCloak
+	u.detector = stats.Detector
+}
+
+// instant reports whether the order takes effect at once without replacing
+// what the unit is doing
+func (ct CommandType) instant() bool {
+	return ct == CmdCloak || ct == CmdDecloak
+}
+
+// IsCloaked reports whether the unit is cloaked right now
+func (u *Unit) IsCloaked() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.cloaked
+}
+
+// IsDetector reports whether the unit detects cloaked and burrowed units
+func (u *Unit) IsDetector() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.detector
+}
+
+// hidden reports whether the unit needs detecting before it can be targeted
+func (u *Unit) hidden() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.cloaked || u.elevationLayer == Burrowed
+}
+
+// detectsDirectly reports whether u itself is a working detector with
+// target in range. Passengers don't detect anything.
+func (u *Unit) detectsDirectly(target *Unit) bool {
+	u.mu.RLock()
+	from, reach := u.position, float64(u.visionRange)
+	working := u.detector && u.state != Dead && u.carrier == nil
+	u.mu.RUnlock()
+	return working && from.DistanceSq(target.GetPosition()) <= reach*reach
+}
+
+// detects reports whether this unit can target hidden units at target's
+// position: it's a detector itself, or its side has one in range there.
+func (u *Unit) detects(target *Unit) bool {
+	if u.detectsDirectly(target) {
+		return true
+	}
+	if world := u.GetWorld(); world != nil {
+		return world.Detects(u.owner, target)
+	}
+	return false
+}
+
+// Detects reports whether faction f has target within range of one of
+// its detectors (or a detector of a faction sharing vision with it)
+func (w *World) Detects(f *Faction, target *Unit) bool {
+	if f == nil {
+		return false
+	}
+	for _, v := range w.Units() {
+		if f.SeesFor(v.owner) && v.detectsDirectly(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTargetable reports whether faction f could attack target right now: it
+// is alive, on the battlefield and not in stasis, f has vision of it, and
+// if it's cloaked or burrowed, f has it detected. This is the question an
+// AI strategy should ask before picking a target.
+func (w *World) IsTargetable(f *Faction, target *Unit) bool {
+	if target == nil || target.IsDead() || target.IsLoaded() || target.InStasis() {
+		return false
+	}
+	if !w.HasVision(f, target.GetPosition()) {
+		return false
+	}
+	return !target.hidden() || w.Detects(f, target)
+}
+
+// handleCloak switches on a Wraith's cloak
+func (u *Unit) handleCloak(cmd Command) error {
+	cannot := func(err error, reason string) error {
+		return &OrderError{UnitID: u.ID, Order: CmdCloak, Reason: reason, Err: err}
+	}
+	now := u.clock.Now()
+	u.mu.Lock()
+	switch {
+	case u.permanentCloak:
+		u.mu.Unlock()
+		return cannot(ErrCannotCloak, "always cloaked")
+	case !u.canCloak:
+		u.mu.Unlock()
+		return cannot(ErrCannotCloak, fmt.Sprintf("%s can't cloak", u.Type))
+	case u.cloaked:
+		u.mu.Unlock()
+		return cannot(ErrCannotCloak, "already cloaked")
+	case u.energy < CloakEnergyCost:
+		energy := u.energy
+		u.mu.Unlock()
+		return cannot(ErrNotEnoughEnergy, fmt.Sprintf("need %d energy, have %.0f", CloakEnergyCost, energy))
+	}
+	u.energy -= CloakEnergyCost
+	u.cloaked = true
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventCloaked, Source: u, Timestamp: now})
+	return nil
+}
+
+// handleDecloak switches a Wraith's cloak off
+func (u *Unit) handleDecloak(cmd Command) error {
+	now := u.clock.Now()
+	u.mu.Lock()
+	if !u.canCloak || !u.cloaked {
+		u.mu.Unlock()
+		return &OrderError{UnitID: u.ID, Order: CmdDecloak, Reason: "not cloaked", Err: ErrCannotCloak}
+	}
+	u.cloaked = false
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventDecloaked, Source: u, Timestamp: now})
+	return nil
+}
+
+// drainCloak charges a Wraith's cloak for the time since the last tick and
+// drops it when the energy runs out. Runs before regenerate, which is what
+// moves lastRegen forward.
+func (u *Unit) drainCloak(now time.Time) {
+	u.mu.Lock()
+	if !u.canCloak || !u.cloaked || u.state == Dead {
+		u.mu.Unlock()
+		return
+	}
+	u.energy -= CloakEnergyDrain * now.Sub(u.lastRegen).Seconds()
+	lapsed := u.energy <= 0
+	if lapsed {
+		u.energy = 0
+		u.cloaked = false
+	}
+	u.mu.Unlock()
+
+	if lapsed {
+		u.emit(UnitEvent{Type: EventDecloaked, Source: u, Timestamp: now, Data: ErrNotEnoughEnergy})
+	}
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloak_DetectorRevealsDarkTemplar(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	yellow := NewFaction("p4", "Boxer", Terran, Yellow)
+
+	var wg sync.WaitGroup
+	dt := NewUnit("dt", DarkTemplar, Position{}, &wg, WithClock(clock), WithOwner(blue))
+	marine := NewUnit("marine", Marine, Position{X: 3}, &wg, WithClock(clock), WithOwner(red))
+	vessel := NewUnit("vessel", ScienceVessel, Position{X: 40}, &wg, WithClock(clock), WithOwner(yellow))
+	units := []*Unit{dt, marine, vessel}
+	for _, u := range units {
+		world.Add(u)
+	}
+	require.True(t, dt.IsCloaked())
+	require.True(t, vessel.IsDetector())
+	require.False(t, marine.IsDetector())
+
+	err := marine.CanTarget(dt)
+	require.ErrorIs(t, err, ErrCannotTarget)
+	require.ErrorContains(t, err, "cloaked target is not detected")
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdAttack, Target: dt}), ErrCannotTarget)
+	require.False(t, world.IsTargetable(red, dt), "seen but not detected")
+	require.ErrorIs(t, sendOrder(t, dt, Command{Type: CmdDecloak}), ErrCannotCloak)
+
+	// Someone else's Science Vessel is no help, even in range...
+	vessel.SetPosition(Position{X: 5})
+	require.Error(t, marine.CanTarget(dt))
+	require.True(t, world.IsTargetable(yellow, dt))
+
+	// ...until they share vision
+	ShareVision(red, yellow)
+	require.NoError(t, marine.CanTarget(dt))
+	require.True(t, world.IsTargetable(red, dt))
+	require.True(t, world.IsTargetable(blue, vessel), "nothing hidden about a Science Vessel")
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestCloak_DetectorDiesMidFight(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	dragoon := NewUnit("dragoon", Dragoon, Position{}, &wg, WithClock(clock), WithOwner(blue))
+	observer := NewUnit("observer", Observer, Position{Y: 2}, &wg, WithClock(clock), WithOwner(blue))
+	ling := NewUnit("ling", Zergling, Position{X: 3}, &wg, WithClock(clock), WithOwner(teal))
+	units := []*Unit{dragoon, observer, ling}
+	for _, u := range units {
+		world.Add(u)
+	}
+	failed := dragoon.Subscribe(EventTypes(EventOrderFailed), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, ling, Command{Type: CmdBurrow}))
+	require.False(t, world.IsTargetable(teal, observer), "the Observer is cloaked too")
+	require.True(t, world.IsTargetable(blue, ling))
+	require.NoError(t, sendOrder(t, dragoon, Command{Type: CmdAttack, Target: ling}))
+
+	observer.TakeDamage(1000)
+	require.True(t, observer.IsDead())
+	require.False(t, world.IsTargetable(blue, ling))
+	clock.Advance(TickRate)
+	ev := waitForEvent(t, failed, EventOrderFailed)
+	require.ErrorIs(t, ev.Data.(error), ErrCannotTarget)
+	require.ErrorContains(t, ev.Data.(error), "burrowed target is not detected")
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestCloak_WraithEnergy(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	wraith := NewUnit("wraith", Wraith, Position{}, &wg, WithClock(clock))
+	marine := NewUnit("marine", Marine, Position{X: 2}, &wg, WithClock(clock))
+	sub := wraith.Subscribe(EventTypes(EventCloaked, EventDecloaked), WithBuffer(16))
+
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdCloak}), ErrCannotCloak)
+	require.ErrorIs(t, sendOrder(t, wraith, Command{Type: CmdDecloak}), ErrCannotCloak)
+
+	// Cloaking doesn't interrupt the move
+	require.NoError(t, sendOrder(t, wraith, Command{Type: CmdMove, Dest: Position{X: 200}}))
+	require.NoError(t, sendOrder(t, wraith, Command{Type: CmdCloak}))
+	waitForEvent(t, sub, EventCloaked)
+	require.True(t, wraith.IsCloaked())
+	require.Equal(t, 25, wraith.GetEnergy())
+	order, ok := wraith.CurrentOrder()
+	require.True(t, ok)
+	require.Equal(t, CmdMove, order.Type)
+	require.Equal(t, Moving, wraith.GetState())
+	require.ErrorContains(t, sendOrder(t, wraith, Command{Type: CmdCloak}), "already cloaked")
+
+	// No regeneration while cloaked: a second's drain per second
+	clock.Advance(10 * time.Second)
+	require.Eventually(t, func() bool { return wraith.GetEnergy() == 15 }, time.Second, time.Millisecond)
+	require.True(t, wraith.IsCloaked())
+
+	clock.Advance(15*time.Second + TickRate)
+	ev := waitForEvent(t, sub, EventDecloaked)
+	require.ErrorIs(t, ev.Data.(error), ErrNotEnoughEnergy)
+	require.False(t, wraith.IsCloaked())
+	err := sendOrder(t, wraith, Command{Type: CmdCloak})
+	require.ErrorIs(t, err, ErrNotEnoughEnergy)
+	require.ErrorContains(t, err, "need 25 energy, have 0")
+
+	// Energy comes back once the cloak is off
+	clock.Advance(40 * time.Second)
+	require.Eventually(t, func() bool { return wraith.GetEnergy() >= 29 }, time.Second, time.Millisecond)
+	require.NoError(t, sendOrder(t, wraith, Command{Type: CmdCloak}))
+	require.NoError(t, sendOrder(t, wraith, Command{Type: CmdDecloak}))
+	waitForEvent(t, sub, EventCloaked)
+	ev = waitForEvent(t, sub, EventDecloaked)
+	require.Nil(t, ev.Data)
+
+	wraith.Shutdown()
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestCloak_QueuedCloakDoesNotStallQueue(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	wraith := NewUnit("wraith", Wraith, Position{}, &wg, WithClock(clock))
+
+	require.NoError(t, sendOrder(t, wraith, Command{Type: CmdMove, Dest: Position{X: 1}}))
+	require.NoError(t, wraith.SendCommand(Command{Type: CmdCloak, Queue: true}))
+	require.NoError(t, wraith.SendCommand(Command{Type: CmdMove, Dest: Position{X: 5}, Queue: true}))
+	require.Eventually(t, func() bool { return len(wraith.PendingOrders()) == 2 }, time.Second, time.Millisecond)
+
+	advanceUntil(t, clock, func() bool { return wraith.GetPosition() == Position{X: 5} })
+	require.True(t, wraith.IsCloaked())
+
+	wraith.Shutdown()
+	wg.Wait()
+}
@@ -0,0 +1,231 @@
+package types
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// CLOCK - Whose Time Is It Anyway?
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Everything that cares about time—unit ticks, cooldowns, regeneration,
+// event timestamps, command timeouts—asks a Clock instead of the time
+// package. Production code gets SystemClock (the wall clock). Tests get a
+// ManualClock and move time forward themselves, so "wait three seconds for
+// the shields to come back" takes no real time and never flakes.
+//
+// 🎴 MTG ANALOGY: The turn structure. Nothing happens "eventually"—it happens
+// at upkeep, on the next turn, at end of combat. A ManualClock lets a test
+// say "pass the turn" instead of sleeping and hoping.
+//
+// ⚔️ SC:BW ANALOGY: Replays. The game runs off a frame counter, not the
+// wall clock, which is why a replay can be played at 8x speed or paused and
+// still come out identical.
+//
+// 🎓 LEARNING: Dependency injection for time. time.Now() and time.After()
+// are hidden global inputs; passing a Clock in makes them explicit, and a
+// fake implementation makes them controllable.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Clock is the source of time for units and managers
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at a fixed interval until stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the real wall clock
+type SystemClock struct{}
+
+var _ Clock = SystemClock{}
+
+func (SystemClock) Now() time.Time                         { return time.Now() }
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker adapts *time.Ticker (whose C is a field) to Ticker
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
+
+// ManualClock is a Clock that only moves when told to. Now() stays put
+// until Advance is called; After channels and tickers fire during Advance,
+// in deadline order, with Now() set to the moment they fire.
+//
+// Tick delivery blocks until the ticker's owner receives it (or stops the
+// ticker), so by the time Advance moves on to the next tick the previous
+// one has been picked up—though Now() may already have moved on, so
+// receivers should go by the tick's value. A ticker nobody reads and nobody
+// stops will hang Advance.
+//
+// 🔥 PRO TIP: After Advance returns, the last tick has been received but
+// may still be running. Check results with require.Eventually—it passes on
+// the first poll almost every time, and there's no sleep to tune.
+type ManualClock struct {
+	advanceMu sync.Mutex // One Advance at a time
+
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*manualWaiter
+}
+
+var _ Clock = (*ManualClock)(nil)
+
+// manualWaiter is a pending After channel (period 0) or a ticker
+type manualWaiter struct {
+	clock    *ManualClock
+	deadline time.Time
+	period   time.Duration
+	c        chan time.Time
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// NewManualClock returns a clock frozen at start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the time once the clock has been
+// advanced by d. A non-positive d fires immediately.
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &manualWaiter{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1), stopped: make(chan struct{})}
+	if d <= 0 {
+		w.c <- c.now
+		return w.c
+	}
+	c.waiters = append(c.waiters, w)
+	return w.c
+}
+
+// NewTicker returns a ticker that fires every d of simulated time
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("types: non-positive interval for ManualClock.NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &manualWaiter{clock: c, deadline: c.now.Add(d), period: d, c: make(chan time.Time), stopped: make(chan struct{})}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+// Advance moves the clock forward by d, firing everything that comes due
+// along the way
+func (c *ManualClock) Advance(d time.Duration) {
+	c.advanceMu.Lock()
+	defer c.advanceMu.Unlock()
+
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		w := c.nextDueLocked(end)
+		if w == nil {
+			if end.After(c.now) {
+				c.now = end
+			}
+			c.mu.Unlock()
+			return
+		}
+		at := w.deadline
+		c.now = at
+		if w.period > 0 {
+			w.deadline = at.Add(w.period)
+		} else {
+			c.removeLocked(w)
+		}
+		c.mu.Unlock()
+
+		// Deliver outside the lock: the receiver will probably call Now()
+		w.fire(at)
+	}
+}
+
+// Waiters reports how many After channels and tickers are pending. Tests
+// use it to know a goroutine has started waiting before advancing.
+func (c *ManualClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// nextDueLocked finds the earliest waiter due by end, or nil
+func (c *ManualClock) nextDueLocked(end time.Time) *manualWaiter {
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+	})
+	if len(c.waiters) == 0 || c.waiters[0].deadline.After(end) {
+		return nil
+	}
+	return c.waiters[0]
+}
+
+func (c *ManualClock) removeLocked(w *manualWaiter) {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// fire delivers one tick. After channels are buffered and never block;
+// tickers wait for the receiver unless stopped first.
+func (w *manualWaiter) fire(at time.Time) {
+	select {
+	case w.c <- at:
+	case <-w.stopped:
+	}
+}
+
+// C returns the tick channel
+func (w *manualWaiter) C() <-chan time.Time { return w.c }
+
+// Stop removes the ticker from the clock and releases a pending delivery
+func (w *manualWaiter) Stop() {
+	w.stopOnce.Do(func() {
+		w.clock.mu.Lock()
+		w.clock.removeLocked(w)
+		w.clock.mu.Unlock()
+		close(w.stopped)
+	})
+}
+
+// UnitOption configures a unit in NewUnit
+type UnitOption func(*Unit)
+
+// WithClock runs the unit on c instead of the wall clock. Units it builds
+// inherit the same clock.
+func WithClock(c Clock) UnitOption {
+	return func(u *Unit) { u.clock = c }
+}
+
+// GetClock returns the clock the unit runs on
+func (u *Unit) GetClock() Clock {
+	return u.clock
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var clockStart = time.Date(1998, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+func TestManualClock_After(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	require.Equal(t, clockStart, clock.Now())
+
+	ch := clock.After(time.Second)
+	require.Equal(t, 1, clock.Waiters())
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("fired early")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	require.Equal(t, clockStart.Add(time.Second), <-ch)
+	require.Equal(t, 0, clock.Waiters())
+
+	// Nothing to wait for
+	require.Equal(t, clock.Now(), <-clock.After(0))
+}
+
+func TestManualClock_Ticker(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	ticker := clock.NewTicker(10 * time.Millisecond)
+
+	var got []time.Time
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for tick := range ticker.C() {
+			got = append(got, tick)
+			if len(got) == 3 {
+				return
+			}
+		}
+	}()
+
+	clock.Advance(35 * time.Millisecond)
+	<-done
+	require.Equal(t, []time.Time{
+		clockStart.Add(10 * time.Millisecond),
+		clockStart.Add(20 * time.Millisecond),
+		clockStart.Add(30 * time.Millisecond),
+	}, got)
+	require.Equal(t, clockStart.Add(35*time.Millisecond), clock.Now())
+
+	// Nobody reads the ticker any more; Stop keeps Advance from hanging
+	ticker.Stop()
+	clock.Advance(time.Second)
+	require.Equal(t, 0, clock.Waiters())
+}
+
+func TestUnit_ManualClock(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithClock(clock))
+	events := marine.Subscribe(EventTypes(EventIdle))
+	require.Equal(t, clock, marine.GetClock())
+
+	result := make(chan error, 1)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{X: 1}, Result: result}))
+	require.NoError(t, <-result)
+
+	// No time passes, no distance covered
+	require.Equal(t, Position{}, marine.GetPosition())
+
+	clock.Advance(2 * time.Second)
+	ev := waitForEvent(t, events, EventIdle)
+	require.Equal(t, Position{X: 1}, marine.GetPosition())
+	require.True(t, ev.Timestamp.After(clockStart))
+	require.False(t, ev.Timestamp.After(clock.Now()), "stamped with simulated time")
+
+	// Units a worker builds run on the worker's clock
+	scv := NewUnit("scv", SCV, Position{}, &wg, WithClock(clock))
+	built := scv.Subscribe(EventTypes(EventBuilt))
+	require.NoError(t, scv.SendCommand(Command{Type: CmdBuild, Build: Marine, Result: result}))
+	require.NoError(t, <-result)
+	clock.Advance(time.Minute)
+	rookie := waitForEvent(t, built, EventBuilt).Data.(*Unit)
+	require.Equal(t, clock, rookie.GetClock())
+
+	for _, u := range []*Unit{marine, scv, rookie} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
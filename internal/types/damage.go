@@ -0,0 +1,198 @@
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// DAMAGE TYPES & UNIT SIZES - The Brood War Damage Matrix
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Flat "damage - armor" makes a Vulture shred Dragoons and a Siege Tank
+// one-shot Marines. Brood War scales every hit by weapon damage type versus
+// target size:
+//
+//	              Small   Medium   Large
+//	Normal        100%    100%     100%
+//	Concussive    100%     50%      25%
+//	Explosive      50%     75%     100%
+//	Spell         100%    100%     100%   (ignores armor)
+//
+// 🎴 MTG ANALOGY: Like "deals double damage to creatures with flying"—the
+// same spell hits different creatures differently.
+//
+// ⚔️ SC:BW ANALOGY: Vultures are Zergling-mulchers and useless against
+// Dragoons. Siege Tanks crush Dragoons and do half damage to Marines.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// MinimumDamage is the least a landed hit can do after armor. Brood War
+// never lets armor reduce a hit to zero, which is why health is tracked with
+// sub-integer precision.
+const MinimumDamage = 0.5
+
+type DamageType int
+
+const (
+	NormalDamage DamageType = iota
+	ConcussiveDamage
+	ExplosiveDamage
+	SpellDamage
+
+	damageTypeCount
+)
+
+var _ fmt.Stringer = DamageType(0)
+
+var damageTypeNames = map[DamageType]string{
+	NormalDamage:     "Normal",
+	ConcussiveDamage: "Concussive",
+	ExplosiveDamage:  "Explosive",
+	SpellDamage:      "Spell",
+}
+
+func (dt DamageType) String() string {
+	if name, ok := damageTypeNames[dt]; ok {
+		return name
+	}
+	return fmt.Sprintf("DamageType(%d)", dt)
+}
+
+func (dt DamageType) IsValid() bool {
+	return dt >= NormalDamage && dt < damageTypeCount
+}
+
+type UnitSize int
+
+const (
+	Small UnitSize = iota
+	Medium
+	Large
+
+	unitSizeCount
+)
+
+var _ fmt.Stringer = UnitSize(0)
+
+var unitSizeNames = map[UnitSize]string{
+	Small:  "Small",
+	Medium: "Medium",
+	Large:  "Large",
+}
+
+func (us UnitSize) String() string {
+	if name, ok := unitSizeNames[us]; ok {
+		return name
+	}
+	return fmt.Sprintf("UnitSize(%d)", us)
+}
+
+func (us UnitSize) IsValid() bool {
+	return us >= Small && us < unitSizeCount
+}
+
+// unitSizeRadii is how far a unit's body extends from its position, in
+// tiles. Ranges are measured edge to edge, so bigger units are hit from
+// further away.
+var unitSizeRadii = [unitSizeCount]float64{
+	Small:  0.25,
+	Medium: 0.5,
+	Large:  0.75,
+}
+
+// Radius returns the collision radius for this size class
+func (us UnitSize) Radius() float64 {
+	if !us.IsValid() {
+		return 0
+	}
+	return unitSizeRadii[us]
+}
+
+// damageMatrix holds the percentage (as a multiplier) each damage type deals
+// to each size class. Indexed [DamageType][UnitSize].
+var damageMatrix = [damageTypeCount][unitSizeCount]float64{
+	NormalDamage:     {1.0, 1.0, 1.0},
+	ConcussiveDamage: {1.0, 0.5, 0.25},
+	ExplosiveDamage:  {0.5, 0.75, 1.0},
+	SpellDamage:      {1.0, 1.0, 1.0},
+}
+
+// Multiplier returns how much of its damage this type deals to the given size
+func (dt DamageType) Multiplier(size UnitSize) float64 {
+	if !dt.IsValid() || !size.IsValid() {
+		return 1.0
+	}
+	return damageMatrix[dt][size]
+}
+
+// IgnoresArmor reports whether hits of this type skip the armor step
+func (dt DamageType) IgnoresArmor() bool {
+	return dt == SpellDamage
+}
+
+// ResolveHit applies the damage matrix, then armor, then the minimum-damage
+// rule to a single hit. A hit with no damage at all stays at zero—units
+// without a weapon don't get a free 0.5.
+func ResolveHit(damage float64, dt DamageType, size UnitSize, armor int) float64 {
+	if damage <= 0 {
+		return 0
+	}
+	result := damage * dt.Multiplier(size)
+	if !dt.IgnoresArmor() {
+		result -= float64(armor)
+	}
+	return math.Max(result, MinimumDamage)
+}
+
+// defense is everything a volley has to chew through on one target
+type defense struct {
+	shields     float64
+	shieldArmor int
+	armor       int
+	size        UnitSize
+}
+
+// absorb resolves one hit, shields first. Shields take the hit at full
+// strength—no size multiplier—minus shield armor. Whatever the shields
+// can't hold spills onto the hull and goes through ResolveHit. Updates
+// d.shields and returns the damage taken by each layer.
+func (d *defense) absorb(damage float64, dt DamageType) (toShields, toHull float64) {
+	if damage <= 0 {
+		return 0, 0
+	}
+	if d.shields > 0 {
+		onShields := damage
+		if !dt.IgnoresArmor() {
+			onShields = math.Max(onShields-float64(d.shieldArmor), MinimumDamage)
+		}
+		if onShields <= d.shields {
+			d.shields -= onShields
+			return onShields, 0
+		}
+		toShields = d.shields
+		damage = onShields - d.shields
+		d.shields = 0
+	}
+	return toShields, ResolveHit(damage, dt, d.size, d.armor)
+}
+
+// parseDamageType maps the stat sheet spelling to the enum
+func parseDamageType(name string) (DamageType, bool) {
+	for dt, n := range damageTypeNames {
+		if n == name {
+			return dt, true
+		}
+	}
+	return NormalDamage, false
+}
+
+// parseUnitSize maps the stat sheet spelling to the enum
+func parseUnitSize(name string) (UnitSize, bool) {
+	for size, n := range unitSizeNames {
+		if n == name {
+			return size, true
+		}
+	}
+	return Small, false
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDamageType_String(t *testing.T) {
+	require.Equal(t, "Normal", NormalDamage.String())
+	require.Equal(t, "Concussive", ConcussiveDamage.String())
+	require.Equal(t, "Explosive", ExplosiveDamage.String())
+	require.Equal(t, "Spell", SpellDamage.String())
+	require.Equal(t, "DamageType(999)", DamageType(999).String())
+	require.False(t, damageTypeCount.IsValid())
+}
+
+func TestUnitSize_String(t *testing.T) {
+	require.Equal(t, "Small", Small.String())
+	require.Equal(t, "Medium", Medium.String())
+	require.Equal(t, "Large", Large.String())
+	require.Equal(t, "UnitSize(999)", UnitSize(999).String())
+	require.False(t, unitSizeCount.IsValid())
+}
+
+func TestDamageType_Multiplier(t *testing.T) {
+	tests := []struct {
+		damageType DamageType
+		size       UnitSize
+		expected   float64
+	}{
+		{NormalDamage, Large, 1.0},
+		{ConcussiveDamage, Small, 1.0},
+		{ConcussiveDamage, Medium, 0.5},
+		{ConcussiveDamage, Large, 0.25},
+		{ExplosiveDamage, Small, 0.5},
+		{ExplosiveDamage, Medium, 0.75},
+		{ExplosiveDamage, Large, 1.0},
+		{SpellDamage, Small, 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.damageType.String()+"_vs_"+tt.size.String(), func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.damageType.Multiplier(tt.size))
+		})
+	}
+}
+
+func TestResolveHit(t *testing.T) {
+	require.Equal(t, 0.5, ResolveHit(5, NormalDamage, Large, 10), "armor can't push a hit below the minimum")
+	require.Equal(t, 0.0, ResolveHit(0, NormalDamage, Small, 0), "no weapon, no minimum damage")
+	require.Equal(t, 10.0, ResolveHit(10, SpellDamage, Large, 5), "spell damage ignores armor")
+}
+
+func TestCalculateDamageAgainst_DamageMatrix(t *testing.T) {
+	var wg sync.WaitGroup
+	units := map[UnitType]*Unit{}
+	for _, ut := range []UnitType{Vulture, Zergling, Dragoon, SiegeTank, Marine, Firebat, Ultralisk} {
+		units[ut] = NewUnit(ut.String(), ut, Position{}, &wg)
+	}
+	// The matrix applies to hull damage; strip the Dragoon's shields first
+	units[Dragoon].TakeDamage(80)
+
+	tests := []struct {
+		name     string
+		attacker UnitType
+		target   UnitType
+		expected float64
+	}{
+		{"Vulture shreds Zergling", Vulture, Zergling, 20},     // 20 × 100% - 0
+		{"Vulture bounces off Dragoon", Vulture, Dragoon, 4},   // 20 × 25% - 1
+		{"Siege Tank halved vs Marine", SiegeTank, Marine, 15}, // 30 × 50% - 0
+		{"Siege Tank full vs Dragoon", SiegeTank, Dragoon, 29}, // 30 × 100% - 1
+		{"Firebat weak vs Ultralisk", Firebat, Ultralisk, 2},   // 2 hits × (8 × 25% - 1)
+		{"Marine normal vs Zergling", Marine, Zergling, 6},     // 6 × 100% - 0
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dmg := units[tt.attacker].CalculateDamageAgainst(units[tt.target])
+			require.Equal(t, tt.expected, dmg)
+		})
+	}
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestUnit_TakeDamage_FractionalHealth(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+
+	unit.TakeDamage(39.5)
+	require.Equal(t, 0.5, unit.GetHealthExact())
+	require.Equal(t, 1, unit.GetHealth(), "HUD rounds fractional health up")
+
+	unit.Shutdown()
+	wg.Wait()
+}
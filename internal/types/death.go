@@ -0,0 +1,125 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// DEATH - Battlefield → Graveyard
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// The hit that takes a unit to zero health kills it, exactly once:
+//   1. The victim's state becomes Dead (final—SetState won't undo it)
+//   2. The victim emits EventDied (Target: the killer, nil if unknown)
+//   3. The killer emits EventKilled (Target: the victim)
+//   4. The victim's goroutine stops on its own; no Shutdown call needed
+//
+// 🎴 MTG ANALOGY: "Dies" triggers. The creature hits the graveyard, its
+// controller sees "when this dies", the opponent sees "whenever a creature
+// you control deals lethal damage".
+//
+// ⚔️ SC:BW ANALOGY: The kill counter on the score screen. Somebody has to
+// get credit for every dead Zergling.
+//
+// 🎓 LEARNING: The killing blow runs on the ATTACKER's goroutine. The
+// victim can't "notice" its own death in time, so death cancels the
+// victim's context and its run loop exits the next time it selects.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// ErrUnitDead is returned (wrapped) when ordering a dead unit or targeting one
+var ErrUnitDead = errors.New("unit is dead")
+
+// IsDead reports whether the unit has died
+func (u *Unit) IsDead() bool {
+	return u.GetState() == Dead
+}
+
+// takeHits resolves count hits against a Defensive Matrix, shields, then
+// hull (see defense.absorb); a unit in stasis takes nothing. Returns the total damage dealt, the health left and
+// whether these hits were the killing blow—in which case the caller must
+// call die once any events about the hit itself are out.
+func (u *Unit) takeHits(damage float64, dt DamageType, count int, now time.Time) (dealt, remaining float64, killed bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state == Dead || u.effects[StasisField] != nil {
+		return 0, u.health, false
+	}
+
+	d := u.defenseLocked()
+	for i := 0; i < count; i++ {
+		toShields, toHull := d.absorb(u.soakLocked(damage), dt)
+		dealt += toShields + toHull
+		u.health -= toHull
+	}
+	u.shields = d.shields
+	if dealt > 0 {
+		u.lastDamaged = now
+	}
+	if u.health < 0 {
+		u.health = 0
+	}
+
+	if u.health == 0 {
+		u.state = Dead
+		u.target = nil
+		u.velocity = 0
+		return dealt, 0, true
+	}
+	return dealt, u.health, false
+}
+
+// die announces the death and stops the unit's goroutine. Only the caller
+// that moved the unit to Dead gets here, so it runs once per unit. A
+// transport takes its passengers down with it (see transport.go).
+func (u *Unit) die(killer *Unit, now time.Time) {
+	u.emit(UnitEvent{Type: EventDied, Source: u, Target: killer, Timestamp: now})
+	if killer != nil {
+		killer.emit(UnitEvent{Type: EventKilled, Source: killer, Target: u, Timestamp: now})
+	}
+	u.sinkCargo(killer, now)
+	u.cancel()
+}
+
+// stoppedError explains why the unit no longer takes cmd: it died, or it
+// was shut down
+func (u *Unit) stoppedError(cmd Command) error {
+	err := ErrShuttingDown
+	if u.IsDead() {
+		err = ErrUnitDead
+	}
+	return &CommandError{UnitID: u.ID, Command: cmd, Err: err}
+}
+
+// stopOrders answers every queued order with the reason the unit stopped
+func (u *Unit) stopOrders() {
+	u.mu.Lock()
+	dropped := u.orders
+	u.orders = nil
+	u.mu.Unlock()
+
+	for _, cmd := range dropped {
+		cmd.reply(u.stoppedError(cmd))
+	}
+}
+
+// rejectPending answers commands still queued when the unit stopped (it
+// died or was shut down), so callers waiting on Result aren't left hanging.
+// The loop has exited and ctx is done, so once sendMu is held no sender can
+// slip another command in behind this drain.
+func (u *Unit) rejectPending() {
+	u.sendMu.Lock()
+	defer u.sendMu.Unlock()
+	for {
+		select {
+		case cmd, ok := <-u.commands:
+			if !ok {
+				return
+			}
+			cmd.reply(u.stoppedError(cmd))
+		default:
+			return
+		}
+	}
+}
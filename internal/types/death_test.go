@@ -0,0 +1,93 @@
+package types
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Death_EventsAndSelfShutdown(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("fast-marine", strings.NewReader(`{"Marine": {"groundWeapon": {"cooldown": 0.01}}}`)))
+
+	var marineWg, lingWg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &marineWg)
+	zergling := NewUnit("ling", Zergling, Position{X: 1}, &lingWg)
+	marineEvents := marine.Subscribe(nil)
+	lingEvents := zergling.Subscribe(nil)
+
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttack, Target: zergling}))
+
+	died := waitForEvent(t, lingEvents, EventDied)
+	require.Equal(t, zergling, died.Source)
+	require.Equal(t, marine, died.Target, "EventDied names the killer")
+
+	killed := waitForEvent(t, marineEvents, EventKilled)
+	require.Equal(t, marine, killed.Source)
+	require.Equal(t, zergling, killed.Target)
+
+	require.True(t, zergling.IsDead())
+	require.Equal(t, Dead, zergling.GetState())
+
+	// The victim's goroutine exits without anyone calling Shutdown
+	done := make(chan struct{})
+	go func() {
+		lingWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dead unit's goroutine is still running")
+	}
+
+	err := zergling.SendCommand(Command{Type: CmdMove, Dest: Position{X: 5}})
+	require.ErrorIs(t, err, ErrUnitDead)
+
+	marine.Shutdown()
+	zergling.Shutdown() // Still safe after death
+	marineWg.Wait()
+}
+
+func TestUnit_Death_IsFinal(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+	events := unit.Subscribe(nil)
+
+	require.Equal(t, 0.0, unit.TakeDamage(100))
+	died := waitForEvent(t, events, EventDied)
+	require.Nil(t, died.Target, "no attacker to credit")
+
+	unit.SetState(Idle)
+	require.Equal(t, Dead, unit.GetState(), "dead units stay dead")
+
+	// Overkill doesn't die twice
+	unit.TakeDamage(10)
+	wg.Wait()
+	for ev := range events.Events() {
+		require.NotEqual(t, EventDied, ev.Type)
+	}
+}
+
+func TestUnit_HandleAttack_RejectsDeadTarget(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	corpse := NewUnit("corpse", Zergling, Position{X: 1}, &wg)
+	corpse.TakeDamage(35)
+
+	result := make(chan error, 1)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttack, Target: corpse, Result: result}))
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrUnitDead)
+	case <-time.After(time.Second):
+		t.Fatal("no command result")
+	}
+	require.Equal(t, Idle, marine.GetState())
+
+	marine.Shutdown()
+	wg.Wait()
+}
@@ -0,0 +1,263 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// EVENT SUBSCRIPTIONS - Fan-Out Without Wedging the Unit
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// A unit publishes events; any number of listeners subscribe. Each
+// subscriber has its own buffer and decides what happens when it falls
+// behind:
+//
+//	DropOldest        - make room by discarding the oldest buffered event
+//	DropNewest        - discard the event being published
+//	BlockWithTimeout  - wait up to a timeout for room, then discard
+//
+// Every event carries a sequence number that increases by one per event the
+// unit publishes, so a subscriber that sees 41 then 44 knows it missed two
+// (dropped, or filtered out by its own filter).
+//
+// 🎴 MTG ANALOGY: Several permanents all watching for "whenever a creature
+// dies". Each trigger resolves on its own; one slow trigger doesn't stop
+// the others from seeing the event.
+//
+// ⚔️ SC:BW ANALOGY: The minimap, the score screen and the replay recorder all
+// watch the same battle. If the replay writer stalls, the game keeps going.
+//
+// 🎓 LEARNING: A channel with a single reader that nobody drains is a trap—
+// once the buffer fills, the sender blocks forever. Per-subscriber buffers
+// and explicit overflow policies keep a slow consumer from wedging the
+// producer.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// OverflowPolicy decides what a subscription does with events it has no room for
+type OverflowPolicy int
+
+const (
+	DropOldest OverflowPolicy = iota
+	DropNewest
+	BlockWithTimeout
+
+	overflowPolicyCount
+)
+
+var _ fmt.Stringer = OverflowPolicy(0)
+
+var overflowPolicyNames = map[OverflowPolicy]string{
+	DropOldest:       "DropOldest",
+	DropNewest:       "DropNewest",
+	BlockWithTimeout: "BlockWithTimeout",
+}
+
+func (op OverflowPolicy) String() string {
+	if name, ok := overflowPolicyNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", op)
+}
+
+func (op OverflowPolicy) IsValid() bool {
+	return op >= DropOldest && op < overflowPolicyCount
+}
+
+// Subscription defaults
+const (
+	DefaultSubscriptionBuffer = 64
+	DefaultBlockTimeout       = 50 * time.Millisecond
+)
+
+// EventFilter picks the events a subscriber wants. nil means everything.
+type EventFilter func(UnitEvent) bool
+
+// EventTypes builds a filter that accepts only the listed event types
+func EventTypes(types ...UnitEventType) EventFilter {
+	wanted := make(map[UnitEventType]bool, len(types))
+	for _, t := range types {
+		wanted[t] = true
+	}
+	return func(ev UnitEvent) bool {
+		return wanted[ev.Type]
+	}
+}
+
+// SubscribeOption tweaks a subscription (see WithBuffer, WithOverflow)
+type SubscribeOption func(*Subscription)
+
+// WithBuffer sets how many events the subscription holds before overflowing
+func WithBuffer(size int) SubscribeOption {
+	return func(s *Subscription) {
+		if size > 0 {
+			s.ch = make(chan UnitEvent, size)
+		}
+	}
+}
+
+// WithOverflow sets the overflow policy. timeout only matters for
+// BlockWithTimeout; zero keeps DefaultBlockTimeout. The timeout is wall
+// time, not the unit's clock: it bounds how long a real, slow reader can
+// stall the unit, which simulated time can't speak for.
+func WithOverflow(policy OverflowPolicy, timeout time.Duration) SubscribeOption {
+	return func(s *Subscription) {
+		if policy.IsValid() {
+			s.policy = policy
+		}
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// Subscription is one listener's view of a unit's events. Read from
+// Events(); the channel closes on Unsubscribe or when the unit stops.
+type Subscription struct {
+	unit    *Unit
+	filter  EventFilter
+	policy  OverflowPolicy
+	timeout time.Duration
+	dropped atomic.Uint64
+
+	mu     sync.Mutex // Serializes delivery with close
+	ch     chan UnitEvent
+	closed bool
+}
+
+// Events returns the channel events are delivered on
+func (s *Subscription) Events() <-chan UnitEvent {
+	return s.ch
+}
+
+// Policy returns the subscription's overflow policy
+func (s *Subscription) Policy() OverflowPolicy {
+	return s.policy
+}
+
+// Dropped returns how many events this subscription has discarded on overflow
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe stops delivery and closes the Events channel. Safe to call
+// more than once.
+func (s *Subscription) Unsubscribe() {
+	s.unit.removeSubscription(s)
+	s.close()
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// deliver hands ev to the subscriber according to its overflow policy
+func (s *Subscription) deliver(ev UnitEvent) {
+	if s.filter != nil && !s.filter(ev) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- ev:
+		return
+	default:
+	}
+
+	switch s.policy {
+	case DropOldest:
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- ev:
+		default:
+		}
+	case BlockWithTimeout:
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		select {
+		case s.ch <- ev:
+			return
+		case <-timer.C:
+		}
+	}
+	s.dropped.Add(1)
+}
+
+// Subscribe registers a listener for this unit's events. filter may be nil
+// to receive everything. Defaults: DefaultSubscriptionBuffer slots,
+// DropOldest. Subscribing to a unit that has already stopped returns a
+// subscription whose channel is closed.
+func (u *Unit) Subscribe(filter EventFilter, opts ...SubscribeOption) *Subscription {
+	s := &Subscription{
+		unit:    u,
+		filter:  filter,
+		policy:  DropOldest,
+		timeout: DefaultBlockTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.ch == nil {
+		s.ch = make(chan UnitEvent, DefaultSubscriptionBuffer)
+	}
+
+	u.pubMu.Lock()
+	defer u.pubMu.Unlock()
+	if u.subsClosed {
+		s.close()
+		return s
+	}
+	u.subs = append(u.subs, s)
+	return s
+}
+
+func (u *Unit) removeSubscription(s *Subscription) {
+	u.pubMu.Lock()
+	defer u.pubMu.Unlock()
+	for i, sub := range u.subs {
+		if sub == s {
+			u.subs = append(u.subs[:i], u.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// emit stamps ev with the next sequence number and fans it out. Publishing
+// is serialized per unit so every subscriber sees events in Seq order.
+func (u *Unit) emit(ev UnitEvent) {
+	u.pubMu.Lock()
+	defer u.pubMu.Unlock()
+	u.eventSeq++
+	ev.Seq = u.eventSeq
+	for _, s := range u.subs {
+		s.deliver(ev)
+	}
+}
+
+// closeSubscriptions ends every subscription once the unit has stopped
+func (u *Unit) closeSubscriptions() {
+	u.pubMu.Lock()
+	subs := u.subs
+	u.subs = nil
+	u.subsClosed = true
+	u.pubMu.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
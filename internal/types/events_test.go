@@ -0,0 +1,169 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// waitForEvent reads a subscription until an event of type want shows up
+func waitForEvent(t *testing.T, sub *Subscription, want UnitEventType) UnitEvent {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				t.Fatalf("subscription closed before event %d", want)
+			}
+			if ev.Type == want {
+				return ev
+			}
+		case <-deadline:
+			t.Fatalf("event %d never arrived", want)
+		}
+	}
+}
+
+// drain collects whatever is buffered right now
+func drain(sub *Subscription) []UnitEvent {
+	var events []UnitEvent
+	for {
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, ev)
+		default:
+			return events
+		}
+	}
+}
+
+func seqs(events []UnitEvent) []uint64 {
+	out := make([]uint64, len(events))
+	for i, ev := range events {
+		out[i] = ev.Seq
+	}
+	return out
+}
+
+func TestOverflowPolicy_String(t *testing.T) {
+	require.Equal(t, "DropOldest", DropOldest.String())
+	require.Equal(t, "DropNewest", DropNewest.String())
+	require.Equal(t, "BlockWithTimeout", BlockWithTimeout.String())
+	require.Equal(t, "OverflowPolicy(999)", OverflowPolicy(999).String())
+	require.False(t, overflowPolicyCount.IsValid())
+}
+
+func TestUnit_Subscribe_FanOutWithSequence(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+	all := unit.Subscribe(nil)
+	idleOnly := unit.Subscribe(EventTypes(EventIdle))
+
+	unit.emit(UnitEvent{Type: EventMoved})
+	unit.emit(UnitEvent{Type: EventIdle})
+	unit.emit(UnitEvent{Type: EventMoved})
+
+	require.Equal(t, []uint64{1, 2, 3}, seqs(drain(all)))
+
+	got := drain(idleOnly)
+	require.Len(t, got, 1)
+	require.Equal(t, uint64(2), got[0].Seq, "filtered subscribers see the unit's numbering")
+
+	unit.Shutdown()
+	wg.Wait()
+}
+
+func TestSubscription_OverflowPolicies(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+	oldest := unit.Subscribe(nil, WithBuffer(2), WithOverflow(DropOldest, 0))
+	newest := unit.Subscribe(nil, WithBuffer(2), WithOverflow(DropNewest, 0))
+
+	for i := 0; i < 5; i++ {
+		unit.emit(UnitEvent{Type: EventMoved})
+	}
+
+	require.Equal(t, []uint64{4, 5}, seqs(drain(oldest)), "keeps the latest events")
+	require.Equal(t, []uint64{1, 2}, seqs(drain(newest)), "keeps the earliest events")
+	require.Equal(t, uint64(3), oldest.Dropped())
+	require.Equal(t, uint64(3), newest.Dropped())
+
+	unit.Shutdown()
+	wg.Wait()
+}
+
+func TestSubscription_BlockWithTimeout(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+	sub := unit.Subscribe(nil, WithBuffer(1), WithOverflow(BlockWithTimeout, 200*time.Millisecond))
+	require.Equal(t, BlockWithTimeout, sub.Policy())
+
+	// A reader that shows up within the timeout gets everything
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-sub.Events()
+	}()
+	unit.emit(UnitEvent{Type: EventMoved})
+	unit.emit(UnitEvent{Type: EventMoved})
+	require.Equal(t, uint64(0), sub.Dropped())
+
+	// Nobody reading: the publisher gives up after the timeout
+	start := time.Now()
+	unit.emit(UnitEvent{Type: EventMoved})
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.Equal(t, uint64(1), sub.Dropped())
+
+	unit.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Subscribe_SlowSubscriberDoesNotWedgeUnit(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+	unit.Subscribe(nil, WithBuffer(1)) // Never read
+
+	for i := 0; i < 30; i++ {
+		result := make(chan error, 1)
+		require.NoError(t, unit.SendCommand(Command{Type: CmdMove, Dest: Position{X: float64(i)}, Result: result}))
+		select {
+		case err := <-result:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatalf("unit wedged after %d moves", i)
+		}
+	}
+
+	unit.Shutdown()
+	wg.Wait()
+}
+
+func TestSubscription_Close(t *testing.T) {
+	var wg sync.WaitGroup
+	unit := NewUnit("test", Marine, Position{}, &wg)
+	sub := unit.Subscribe(nil)
+	kept := unit.Subscribe(nil)
+
+	sub.Unsubscribe()
+	sub.Unsubscribe() // Idempotent
+	_, ok := <-sub.Events()
+	require.False(t, ok, "Unsubscribe closes the channel")
+
+	unit.emit(UnitEvent{Type: EventIdle})
+	require.Len(t, drain(kept), 1)
+
+	// Stopping the unit ends the remaining subscriptions
+	unit.Shutdown()
+	wg.Wait()
+	_, ok = <-kept.Events()
+	require.False(t, ok)
+
+	late := unit.Subscribe(nil)
+	_, ok = <-late.Events()
+	require.False(t, ok, "subscribing to a stopped unit yields a closed channel")
+}
@@ -0,0 +1,308 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// FACTIONS - Whose Side Are You On?
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Every player in a game is a Faction: a race, a player color, and a set of
+// diplomatic settings toward every other player. Each unit belongs to at
+// most one faction for its whole life (units without one are neutral—
+// critters, mineral fields, the odd Rhynadon).
+//
+// Diplomacy is one-way, exactly like the in-game Alliance screen:
+//   - Alliance:      "I won't shoot you." You may not feel the same way.
+//   - Shared vision: "You can see what I see." Doesn't mean I see what you see.
+//
+// Ally(a, b) and ShareVision(a, b) set both directions at once, which is
+// what team games do.
+//
+// 🎴 MTG ANALOGY: Two-Headed Giant. Your teammate's creatures aren't yours,
+// but you don't attack them, and you see each other's hands.
+//
+// ⚔️ SC:BW ANALOGY: The Alliance dialog (F11 in some versions): a row per
+// player with "Allied" and "Shared Vision" checkboxes. Unchecking Allied
+// mid-game on a teammate whose Siege Tanks are sieged next to your base is
+// a classic BGH betrayal.
+//
+// 🎓 LEARNING: The owner pointer on a Unit never changes, so reading it
+// needs no lock. The diplomacy maps on a Faction DO change mid-game, so
+// they sit behind the faction's own RWMutex—reads (every targeting
+// decision) vastly outnumber writes (a player clicking a checkbox).
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Race is one of the three playable races
+type Race int
+
+const (
+	Terran Race = iota
+	Zerg
+	Protoss
+
+	raceCount // Sentinel value for validation
+)
+
+var _ fmt.Stringer = Race(0)
+
+var raceNames = map[Race]string{
+	Terran:  "Terran",
+	Zerg:    "Zerg",
+	Protoss: "Protoss",
+}
+
+func (r Race) String() string {
+	if name, ok := raceNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Race(%d)", r)
+}
+
+// IsValid checks if a Race value is within valid range
+func (r Race) IsValid() bool {
+	return r >= Terran && r < raceCount
+}
+
+// Race returns the race that builds this unit type
+func (ut UnitType) Race() Race {
+	switch {
+	case ut >= SCV && ut <= Battlecruiser:
+		return Terran
+	case ut >= Drone && ut <= Defiler:
+		return Zerg
+	case ut >= Probe && ut < unitTypeCount:
+		return Protoss
+	}
+	return raceCount
+}
+
+// PlayerColor is a player's color in the lobby, in slot order
+type PlayerColor int
+
+const (
+	Red PlayerColor = iota
+	Blue
+	Teal
+	Purple
+	Orange
+	Brown
+	White
+	Yellow
+
+	playerColorCount // Sentinel value for validation
+)
+
+var _ fmt.Stringer = PlayerColor(0)
+
+var playerColorNames = map[PlayerColor]string{
+	Red:    "Red",
+	Blue:   "Blue",
+	Teal:   "Teal",
+	Purple: "Purple",
+	Orange: "Orange",
+	Brown:  "Brown",
+	White:  "White",
+	Yellow: "Yellow",
+}
+
+func (c PlayerColor) String() string {
+	if name, ok := playerColorNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("PlayerColor(%d)", c)
+}
+
+// IsValid checks if a PlayerColor value is within valid range
+func (c PlayerColor) IsValid() bool {
+	return c >= Red && c < playerColorCount
+}
+
+// Relation is how one faction regards another
+type Relation int
+
+const (
+	RelationSelf    Relation = iota // Same faction
+	RelationAlly                    // Allied: won't auto-attack, can't hurt with splash if friendly fire is off
+	RelationEnemy                   // Everybody else
+	RelationNeutral                 // One side has no faction
+
+	relationCount // Sentinel value for validation
+)
+
+var _ fmt.Stringer = Relation(0)
+
+var relationNames = map[Relation]string{
+	RelationSelf:    "Self",
+	RelationAlly:    "Ally",
+	RelationEnemy:   "Enemy",
+	RelationNeutral: "Neutral",
+}
+
+func (r Relation) String() string {
+	if name, ok := relationNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Relation(%d)", r)
+}
+
+// IsValid checks if a Relation value is within valid range
+func (r Relation) IsValid() bool {
+	return r >= RelationSelf && r < relationCount
+}
+
+// Faction is a player: identity, diplomacy toward other players, and
+// upgrades researched
+type Faction struct {
+	// Immutable after NewFaction
+	ID    string
+	Name  string
+	Race  Race
+	Color PlayerColor
+
+	mu           sync.RWMutex
+	allies       map[string]bool // Faction IDs this faction won't attack
+	sharedVision map[string]bool // Faction IDs this faction shows its vision to
+
+	// Upgrades (see upgrades.go)
+	levels      map[UpgradeType]int
+	researching map[UpgradeType]bool
+	watchers    []chan UpgradeEvent
+}
+
+// NewFaction creates a faction that is hostile to everyone
+func NewFaction(id, name string, race Race, color PlayerColor) *Faction {
+	return &Faction{
+		ID:           id,
+		Name:         name,
+		Race:         race,
+		Color:        color,
+		allies:       make(map[string]bool),
+		sharedVision: make(map[string]bool),
+		levels:       make(map[UpgradeType]int),
+		researching:  make(map[UpgradeType]bool),
+	}
+}
+
+func (f *Faction) String() string {
+	return fmt.Sprintf("%s (%s, %s)", f.Name, f.Race, f.Color)
+}
+
+// SetAlliance sets whether f treats other as an ally. One-way: other's
+// stance toward f is unchanged.
+func (f *Faction) SetAlliance(other *Faction, allied bool) {
+	if other == nil || other == f {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if allied {
+		f.allies[other.ID] = true
+	} else {
+		delete(f.allies, other.ID)
+	}
+}
+
+// SetSharedVision sets whether f shows other everything f's units see.
+// One-way: f doesn't get to see what other sees.
+func (f *Faction) SetSharedVision(other *Faction, shared bool) {
+	if other == nil || other == f {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if shared {
+		f.sharedVision[other.ID] = true
+	} else {
+		delete(f.sharedVision, other.ID)
+	}
+}
+
+// Ally makes a and b allies of each other
+func Ally(a, b *Faction) {
+	a.SetAlliance(b, true)
+	b.SetAlliance(a, true)
+}
+
+// ShareVision makes a and b share vision both ways
+func ShareVision(a, b *Faction) {
+	a.SetSharedVision(b, true)
+	b.SetSharedVision(a, true)
+}
+
+// RelationTo reports how f regards other. A nil faction on either side is
+// neutral.
+func (f *Faction) RelationTo(other *Faction) Relation {
+	switch {
+	case f == nil || other == nil:
+		return RelationNeutral
+	case f == other:
+		return RelationSelf
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.allies[other.ID] {
+		return RelationAlly
+	}
+	return RelationEnemy
+}
+
+// SeesFor reports whether f gets to see what other's units see: its own
+// units, or a faction sharing vision with f.
+func (f *Faction) SeesFor(other *Faction) bool {
+	switch {
+	case f == nil || other == nil:
+		return false
+	case f == other:
+		return true
+	}
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	return other.sharedVision[f.ID]
+}
+
+// WithOwner puts the unit under faction f for its whole life. Units it
+// builds belong to f too.
+func WithOwner(f *Faction) UnitOption {
+	return func(u *Unit) { u.owner = f }
+}
+
+// GetOwner returns the unit's faction, nil for neutral units. The owner is
+// fixed at NewUnit, so no lock is needed.
+func (u *Unit) GetOwner() *Faction {
+	return u.owner
+}
+
+// IsEnemy reports whether a's owner is hostile toward b's owner—whether a
+// would open fire on b of its own accord. Neutral units are nobody's enemy.
+func IsEnemy(a, b *Unit) bool {
+	return a.owner.RelationTo(b.owner) == RelationEnemy
+}
+
+// IsAlly reports whether b is on a's side: the same faction, or one a's
+// owner is allied with
+func IsAlly(a, b *Unit) bool {
+	switch a.owner.RelationTo(b.owner) {
+	case RelationSelf, RelationAlly:
+		return true
+	}
+	return false
+}
+
+// Enemies returns the units in candidates that u regards as enemies
+//
+// 🔥 PRO TIP: AI strategies get handed a big slice of units; filter with
+// this instead of re-deriving hostility, so every system agrees on who's
+// fair game.
+func Enemies(u *Unit, candidates []*Unit) []*Unit {
+	var enemies []*Unit
+	for _, c := range candidates {
+		if c != u && IsEnemy(u, c) {
+			enemies = append(enemies, c)
+		}
+	}
+	return enemies
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRace_String(t *testing.T) {
+	require.Equal(t, "Zerg", Zerg.String())
+	require.Equal(t, "Race(9)", Race(9).String())
+	require.False(t, raceCount.IsValid())
+
+	require.Equal(t, Terran, Battlecruiser.Race())
+	require.Equal(t, Zerg, Drone.Race())
+	require.Equal(t, Zerg, Lurker.Race())
+	require.Equal(t, Protoss, Arbiter.Race())
+	require.False(t, UnitType(999).Race().IsValid())
+}
+
+func TestPlayerColor_String(t *testing.T) {
+	require.Equal(t, "Teal", Teal.String())
+	require.Equal(t, "PlayerColor(42)", PlayerColor(42).String())
+	require.False(t, playerColorCount.IsValid())
+}
+
+func TestFaction_Relations(t *testing.T) {
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+	teal := NewFaction("p3", "Bisu", Protoss, Teal)
+	require.Equal(t, "Flash (Terran, Red)", red.String())
+
+	require.Equal(t, RelationSelf, red.RelationTo(red))
+	require.Equal(t, RelationEnemy, red.RelationTo(blue))
+	require.Equal(t, RelationNeutral, red.RelationTo(nil))
+	require.Equal(t, RelationNeutral, (*Faction)(nil).RelationTo(red))
+
+	// Alliances are one-way...
+	red.SetAlliance(teal, true)
+	require.Equal(t, RelationAlly, red.RelationTo(teal))
+	require.Equal(t, RelationEnemy, teal.RelationTo(red))
+
+	// ...unless both sides sign up
+	Ally(blue, teal)
+	require.Equal(t, RelationAlly, blue.RelationTo(teal))
+	require.Equal(t, RelationAlly, teal.RelationTo(blue))
+
+	red.SetAlliance(teal, false)
+	require.Equal(t, RelationEnemy, red.RelationTo(teal))
+}
+
+func TestFaction_SharedVision(t *testing.T) {
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+
+	require.True(t, red.SeesFor(red))
+	require.False(t, red.SeesFor(blue))
+
+	blue.SetSharedVision(red, true)
+	require.True(t, red.SeesFor(blue), "blue shows red its vision")
+	require.False(t, blue.SeesFor(red))
+
+	ShareVision(red, blue)
+	require.True(t, blue.SeesFor(red))
+	require.False(t, red.SeesFor(nil))
+}
+
+func TestUnit_Owner(t *testing.T) {
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+	teal := NewFaction("p3", "Bisu", Protoss, Teal)
+	Ally(red, teal)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithOwner(red))
+	medic := NewUnit("medic", Medic, Position{}, &wg, WithOwner(red))
+	ling := NewUnit("ling", Zergling, Position{}, &wg, WithOwner(blue))
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg, WithOwner(teal))
+	critter := NewUnit("rhynadon", Ultralisk, Position{}, &wg)
+
+	require.Equal(t, red, marine.GetOwner())
+	require.Nil(t, critter.GetOwner())
+
+	require.True(t, IsEnemy(marine, ling))
+	require.True(t, IsEnemy(ling, zealot))
+	require.False(t, IsEnemy(marine, medic))
+	require.False(t, IsEnemy(marine, zealot))
+	require.False(t, IsEnemy(marine, critter), "neutral units are nobody's enemy")
+
+	require.True(t, IsAlly(marine, medic))
+	require.True(t, IsAlly(marine, zealot))
+	require.False(t, IsAlly(marine, ling))
+	require.False(t, IsAlly(marine, critter))
+
+	all := []*Unit{marine, medic, ling, zealot, critter}
+	require.Equal(t, []*Unit{ling}, Enemies(marine, all))
+	require.Equal(t, []*Unit{marine, medic, zealot}, Enemies(ling, all))
+
+	for _, u := range all {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,436 @@
+package types
+
+import (
+	"math"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// GEOMETRY - Shapes, Areas and Line of Sight
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Positions are in tiles with Y growing downward (screen coordinates, like
+// the game). On top of that:
+//   - Area:  anything that can say whether a point is inside it. Rectangle,
+//            Circle and Polygon are Areas; hazards, objectives, capture
+//            zones and spell effects are all described by one.
+//   - Intersects(a, b): do two areas overlap?
+//   - TileCoord / WalkTiles / CastRay: walk a segment tile by tile, which
+//            is what line of sight is made of.
+//
+// 🎴 MTG ANALOGY: None—MTG has zones, not coordinates. The closest thing is
+// "each creature in target player's graveyard": a membership test, which is
+// all Contains is.
+//
+// ⚔️ SC:BW ANALOGY: Psionic Storm is a square, Dark Swarm a circle, a map
+// trigger's "location" a rectangle, and the edge of high ground a jagged
+// polygon. A Siege Tank on low ground can't see up the cliff because the
+// line to its target crosses a high-ground tile.
+//
+// 🎓 LEARNING: Everything here is a value type—no pointers, no mutexes.
+// Shapes are built once and read from any number of goroutines.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// geometryEpsilon absorbs floating-point error on boundaries
+const geometryEpsilon = 1e-9
+
+// Area is a region of the map
+type Area interface {
+	// Contains reports whether p is inside the area; the boundary counts
+	Contains(p Position) bool
+	// Bounds returns the smallest Rectangle enclosing the area
+	Bounds() Rectangle
+}
+
+var (
+	_ Area = Rectangle{}
+	_ Area = Circle{}
+	_ Area = Polygon{}
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Rectangle
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Rectangle is an axis-aligned box. TopLeft holds the smaller X and Y.
+type Rectangle struct {
+	TopLeft     Position
+	BottomRight Position
+}
+
+// NewRectangle builds a Rectangle from any two opposite corners
+func NewRectangle(a, b Position) Rectangle {
+	return Rectangle{
+		TopLeft:     Position{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y)},
+		BottomRight: Position{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y)},
+	}
+}
+
+func (r Rectangle) Width() float64  { return r.BottomRight.X - r.TopLeft.X }
+func (r Rectangle) Height() float64 { return r.BottomRight.Y - r.TopLeft.Y }
+
+// Center returns the middle of the rectangle
+func (r Rectangle) Center() Position {
+	return Position{X: (r.TopLeft.X + r.BottomRight.X) / 2, Y: (r.TopLeft.Y + r.BottomRight.Y) / 2}
+}
+
+// Contains reports whether p lies inside or on the edge of r
+func (r Rectangle) Contains(p Position) bool {
+	return p.X >= r.TopLeft.X && p.X <= r.BottomRight.X &&
+		p.Y >= r.TopLeft.Y && p.Y <= r.BottomRight.Y
+}
+
+// Bounds returns r itself
+func (r Rectangle) Bounds() Rectangle { return r }
+
+// Intersection returns the overlap of r and other; false if they don't
+// touch
+func (r Rectangle) Intersection(other Rectangle) (Rectangle, bool) {
+	out := Rectangle{
+		TopLeft:     Position{X: math.Max(r.TopLeft.X, other.TopLeft.X), Y: math.Max(r.TopLeft.Y, other.TopLeft.Y)},
+		BottomRight: Position{X: math.Min(r.BottomRight.X, other.BottomRight.X), Y: math.Min(r.BottomRight.Y, other.BottomRight.Y)},
+	}
+	if out.TopLeft.X > out.BottomRight.X || out.TopLeft.Y > out.BottomRight.Y {
+		return Rectangle{}, false
+	}
+	return out, true
+}
+
+// Clamp returns the point in r closest to p (p itself if it's inside)
+//
+// 🔥 PRO TIP: Clamp every move destination to the map bounds. A unit ordered
+// to walk off the edge of the map should stop at the edge, not vanish.
+func (r Rectangle) Clamp(p Position) Position {
+	return Position{
+		X: math.Max(r.TopLeft.X, math.Min(p.X, r.BottomRight.X)),
+		Y: math.Max(r.TopLeft.Y, math.Min(p.Y, r.BottomRight.Y)),
+	}
+}
+
+// Expand grows r by d on every side (shrinks it for negative d)
+func (r Rectangle) Expand(d float64) Rectangle {
+	return NewRectangle(
+		Position{X: r.TopLeft.X - d, Y: r.TopLeft.Y - d},
+		Position{X: r.BottomRight.X + d, Y: r.BottomRight.Y + d},
+	)
+}
+
+// Polygon returns r's corners clockwise (on screen) from the top left
+func (r Rectangle) Polygon() Polygon {
+	return Polygon{Points: []Position{
+		r.TopLeft,
+		{X: r.BottomRight.X, Y: r.TopLeft.Y},
+		r.BottomRight,
+		{X: r.TopLeft.X, Y: r.BottomRight.Y},
+	}}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Circle
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Circle is every point within Radius of Center
+type Circle struct {
+	Center Position
+	Radius float64
+}
+
+// Contains reports whether p lies inside or on the edge of c
+func (c Circle) Contains(p Position) bool {
+	return c.Center.DistanceSq(p) <= c.Radius*c.Radius+geometryEpsilon
+}
+
+// Bounds returns the square around c
+func (c Circle) Bounds() Rectangle {
+	return Rectangle{
+		TopLeft:     Position{X: c.Center.X - c.Radius, Y: c.Center.Y - c.Radius},
+		BottomRight: Position{X: c.Center.X + c.Radius, Y: c.Center.Y + c.Radius},
+	}
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Polygon
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Polygon is a simple (non-self-intersecting) polygon. The last point
+// connects back to the first.
+type Polygon struct {
+	Points []Position
+}
+
+// Contains reports whether p lies inside or on the edge of the polygon
+//
+// 🎓 LEARNING: The even-odd rule. Shoot a ray from p to the right and
+// count how many edges it crosses: odd means inside.
+func (pg Polygon) Contains(p Position) bool {
+	n := len(pg.Points)
+	if n == 0 {
+		return false
+	}
+	inside := false
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := pg.Points[i], pg.Points[j]
+		if distanceToSegmentSq(p, a, b) <= geometryEpsilon {
+			return true
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) &&
+			p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Bounds returns the smallest Rectangle around every point
+func (pg Polygon) Bounds() Rectangle {
+	if len(pg.Points) == 0 {
+		return Rectangle{}
+	}
+	r := Rectangle{TopLeft: pg.Points[0], BottomRight: pg.Points[0]}
+	for _, p := range pg.Points[1:] {
+		r.TopLeft.X = math.Min(r.TopLeft.X, p.X)
+		r.TopLeft.Y = math.Min(r.TopLeft.Y, p.Y)
+		r.BottomRight.X = math.Max(r.BottomRight.X, p.X)
+		r.BottomRight.Y = math.Max(r.BottomRight.Y, p.Y)
+	}
+	return r
+}
+
+// edges calls fn for each side of the polygon
+func (pg Polygon) edges(fn func(a, b Position) bool) bool {
+	n := len(pg.Points)
+	for i := 0; i < n; i++ {
+		if fn(pg.Points[i], pg.Points[(i+1)%n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Intersection
+// ─────────────────────────────────────────────────────────────────────────────
+
+// Intersects reports whether two areas overlap (touching counts). Areas
+// other than Rectangle, Circle and Polygon are compared by their Bounds.
+func Intersects(a, b Area) bool {
+	if _, ok := a.Bounds().Intersection(b.Bounds()); !ok {
+		return false
+	}
+	switch a := a.(type) {
+	case Rectangle:
+		switch b := b.(type) {
+		case Rectangle:
+			return true // Bounds already overlap
+		case Circle:
+			return b.Contains(a.Clamp(b.Center))
+		case Polygon:
+			return polygonsIntersect(a.Polygon(), b)
+		}
+	case Circle:
+		switch b := b.(type) {
+		case Rectangle:
+			return a.Contains(b.Clamp(a.Center))
+		case Circle:
+			r := a.Radius + b.Radius
+			return a.Center.DistanceSq(b.Center) <= r*r+geometryEpsilon
+		case Polygon:
+			return circleIntersectsPolygon(a, b)
+		}
+	case Polygon:
+		switch b := b.(type) {
+		case Rectangle:
+			return polygonsIntersect(a, b.Polygon())
+		case Circle:
+			return circleIntersectsPolygon(b, a)
+		case Polygon:
+			return polygonsIntersect(a, b)
+		}
+	}
+	return true // Bounds overlap; that's as precise as we can be
+}
+
+// InAnyArea reports whether p is inside at least one of the areas
+func InAnyArea(p Position, areas ...Area) bool {
+	for _, a := range areas {
+		if a.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func polygonsIntersect(a, b Polygon) bool {
+	if len(a.Points) == 0 || len(b.Points) == 0 {
+		return false
+	}
+	// One inside the other, or some pair of edges crossing
+	if a.Contains(b.Points[0]) || b.Contains(a.Points[0]) {
+		return true
+	}
+	return a.edges(func(p1, p2 Position) bool {
+		return b.edges(func(q1, q2 Position) bool {
+			return SegmentsIntersect(p1, p2, q1, q2)
+		})
+	})
+}
+
+func circleIntersectsPolygon(c Circle, pg Polygon) bool {
+	if pg.Contains(c.Center) {
+		return true
+	}
+	return pg.edges(func(a, b Position) bool {
+		return distanceToSegmentSq(c.Center, a, b) <= c.Radius*c.Radius+geometryEpsilon
+	})
+}
+
+// SegmentsIntersect reports whether segment p1-p2 touches segment q1-q2
+func SegmentsIntersect(p1, p2, q1, q2 Position) bool {
+	d1 := cross(q1, q2, p1)
+	d2 := cross(q1, q2, p2)
+	d3 := cross(p1, p2, q1)
+	d4 := cross(p1, p2, q2)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	// Collinear or touching at an endpoint
+	return (d1 == 0 && onSegment(q1, q2, p1)) ||
+		(d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) ||
+		(d4 == 0 && onSegment(p1, p2, q2))
+}
+
+// cross is the z of (b-a)×(c-a): which side of line a→b the point c is on
+func cross(a, b, c Position) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// onSegment reports whether c, known to be collinear with a-b, lies on it
+func onSegment(a, b, c Position) bool {
+	return c.X >= math.Min(a.X, b.X) && c.X <= math.Max(a.X, b.X) &&
+		c.Y >= math.Min(a.Y, b.Y) && c.Y <= math.Max(a.Y, b.Y)
+}
+
+// distanceToSegmentSq is the squared distance from p to the closest point
+// of segment a-b
+func distanceToSegmentSq(p, a, b Position) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lenSq := dx*dx + dy*dy
+	if lenSq == 0 {
+		return p.DistanceSq(a)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / lenSq
+	t = math.Max(0, math.Min(1, t))
+	return p.DistanceSq(Position{X: a.X + t*dx, Y: a.Y + t*dy})
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Tiles and line of sight
+// ─────────────────────────────────────────────────────────────────────────────
+
+// TileCoord identifies one map tile; tile (x, y) covers [x, x+1) × [y, y+1)
+type TileCoord struct {
+	X, Y int
+}
+
+// TileOf returns the tile p is standing on
+func TileOf(p Position) TileCoord {
+	return TileCoord{X: int(math.Floor(p.X)), Y: int(math.Floor(p.Y))}
+}
+
+// Center returns the middle of the tile
+func (t TileCoord) Center() Position {
+	return Position{X: float64(t.X) + 0.5, Y: float64(t.Y) + 0.5}
+}
+
+// Rect returns the tile's square
+func (t TileCoord) Rect() Rectangle {
+	return Rectangle{
+		TopLeft:     Position{X: float64(t.X), Y: float64(t.Y)},
+		BottomRight: Position{X: float64(t.X + 1), Y: float64(t.Y + 1)},
+	}
+}
+
+// WalkTiles visits, in order, every tile the segment from a to b passes
+// through, starting with a's tile and ending with b's. It stops early if
+// visit returns false and reports whether it got all the way to b.
+//
+// A segment passing exactly through a tile corner steps diagonally,
+// without visiting the two tiles that only touch the corner.
+//
+// 🎓 LEARNING: Amanatides & Woo's voxel traversal. Instead of sampling the
+// line every so often (and skipping corners), track how far along the
+// segment the next vertical and next horizontal grid line are, and always
+// cross whichever comes first.
+func WalkTiles(a, b Position, visit func(TileCoord) bool) bool {
+	cur, end := TileOf(a), TileOf(b)
+	if !visit(cur) {
+		return false
+	}
+
+	dx, dy := b.X-a.X, b.Y-a.Y
+	stepX, tMaxX, tDeltaX := traversalAxis(a.X, dx, cur.X)
+	stepY, tMaxY, tDeltaY := traversalAxis(a.Y, dy, cur.Y)
+
+	for cur != end {
+		// Never step past the end tile on an axis that's already there
+		if cur.X == end.X {
+			tMaxX = math.Inf(1)
+		}
+		if cur.Y == end.Y {
+			tMaxY = math.Inf(1)
+		}
+		switch {
+		case tMaxX < tMaxY:
+			cur.X += stepX
+			tMaxX += tDeltaX
+		case tMaxY < tMaxX:
+			cur.Y += stepY
+			tMaxY += tDeltaY
+		default:
+			cur.X += stepX
+			cur.Y += stepY
+			tMaxX += tDeltaX
+			tMaxY += tDeltaY
+		}
+		if !visit(cur) {
+			return false
+		}
+	}
+	return true
+}
+
+// traversalAxis sets up one axis of WalkTiles: which way to step, how far
+// along the segment (0..1) the first grid line is, and how far apart grid
+// lines are
+func traversalAxis(start, delta float64, tile int) (step int, tMax, tDelta float64) {
+	switch {
+	case delta > 0:
+		return 1, (float64(tile+1) - start) / delta, 1 / delta
+	case delta < 0:
+		return -1, (float64(tile) - start) / delta, -1 / delta
+	}
+	return 0, math.Inf(1), math.Inf(1)
+}
+
+// CastRay walks from a toward b and returns the first tile that blocks;
+// ok is false if nothing does. a's own tile never blocks—a unit can always
+// see out of the tile it stands on.
+func CastRay(a, b Position, blocks func(TileCoord) bool) (hit TileCoord, ok bool) {
+	start := TileOf(a)
+	WalkTiles(a, b, func(t TileCoord) bool {
+		if t != start && blocks(t) {
+			hit, ok = t, true
+			return false
+		}
+		return true
+	})
+	return hit, ok
+}
+
+// LineOfSight reports whether nothing between a and b blocks sight
+func LineOfSight(a, b Position, blocks func(TileCoord) bool) bool {
+	_, blocked := CastRay(a, b, blocks)
+	return !blocked
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRectangle(t *testing.T) {
+	r := NewRectangle(Position{X: 4, Y: 3}, Position{X: 0, Y: 1})
+	require.Equal(t, Rectangle{TopLeft: Position{X: 0, Y: 1}, BottomRight: Position{X: 4, Y: 3}}, r)
+	require.Equal(t, 4.0, r.Width())
+	require.Equal(t, 2.0, r.Height())
+	require.Equal(t, Position{X: 2, Y: 2}, r.Center())
+
+	require.True(t, r.Contains(Position{X: 2, Y: 2}))
+	require.True(t, r.Contains(Position{X: 4, Y: 3}), "edges count")
+	require.False(t, r.Contains(Position{X: 4.1, Y: 2}))
+
+	require.Equal(t, Position{X: 4, Y: 1}, r.Clamp(Position{X: 10, Y: -5}))
+	require.Equal(t, Position{X: 1, Y: 2}, r.Clamp(Position{X: 1, Y: 2}))
+
+	overlap, ok := r.Intersection(NewRectangle(Position{X: 3, Y: 0}, Position{X: 9, Y: 2}))
+	require.True(t, ok)
+	require.Equal(t, NewRectangle(Position{X: 3, Y: 1}, Position{X: 4, Y: 2}), overlap)
+	_, ok = r.Intersection(NewRectangle(Position{X: 5, Y: 5}, Position{X: 6, Y: 6}))
+	require.False(t, ok)
+
+	require.Equal(t, NewRectangle(Position{X: -1, Y: 0}, Position{X: 5, Y: 4}), r.Expand(1))
+}
+
+func TestCircle(t *testing.T) {
+	c := Circle{Center: Position{X: 1, Y: 1}, Radius: 2}
+	require.True(t, c.Contains(Position{X: 3, Y: 1}))
+	require.False(t, c.Contains(Position{X: 2.5, Y: 2.5}))
+	require.Equal(t, NewRectangle(Position{X: -1, Y: -1}, Position{X: 3, Y: 3}), c.Bounds())
+}
+
+func TestPolygon_Contains(t *testing.T) {
+	// An L-shaped ridge
+	ridge := Polygon{Points: []Position{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 4}, {X: 0, Y: 4}}}
+
+	require.True(t, ridge.Contains(Position{X: 0.5, Y: 3}))
+	require.True(t, ridge.Contains(Position{X: 3, Y: 0.5}))
+	require.True(t, ridge.Contains(Position{X: 1, Y: 2}), "edges count")
+	require.False(t, ridge.Contains(Position{X: 2, Y: 2}), "inside the corner of the L")
+	require.False(t, ridge.Contains(Position{X: -1, Y: 0.5}))
+	require.False(t, Polygon{}.Contains(Position{}))
+
+	require.Equal(t, NewRectangle(Position{}, Position{X: 4, Y: 4}), ridge.Bounds())
+}
+
+func TestIntersects(t *testing.T) {
+	rect := NewRectangle(Position{}, Position{X: 2, Y: 2})
+	triangle := Polygon{Points: []Position{{X: 3, Y: 0}, {X: 6, Y: 0}, {X: 3, Y: 3}}}
+
+	tests := []struct {
+		name string
+		a, b Area
+		want bool
+	}{
+		{"rect/rect", rect, NewRectangle(Position{X: 1, Y: 1}, Position{X: 5, Y: 5}), true},
+		{"rect/rect apart", rect, NewRectangle(Position{X: 3, Y: 3}, Position{X: 5, Y: 5}), false},
+		{"rect/circle", rect, Circle{Center: Position{X: 3, Y: 1}, Radius: 1}, true},
+		{"rect/circle corner gap", rect, Circle{Center: Position{X: 3, Y: 3}, Radius: 1.2}, false},
+		{"circle/circle", Circle{Radius: 1}, Circle{Center: Position{X: 2}, Radius: 1}, true},
+		{"circle/circle apart", Circle{Radius: 1}, Circle{Center: Position{X: 2.1}, Radius: 1}, false},
+		{"rect/polygon apart", rect, triangle, false},
+		{"polygon/rect crossing", triangle, NewRectangle(Position{X: 4, Y: -1}, Position{X: 5, Y: 4}), true},
+		{"polygon/circle inside", triangle, Circle{Center: Position{X: 3.5, Y: 0.5}, Radius: 0.1}, true},
+		{"polygon/circle near hypotenuse", triangle, Circle{Center: Position{X: 5, Y: 2}, Radius: 0.5}, false},
+		{"polygon/polygon nested", triangle, Polygon{Points: []Position{{X: 3.2, Y: 0.2}, {X: 3.6, Y: 0.2}, {X: 3.2, Y: 0.6}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Intersects(tt.a, tt.b))
+			require.Equal(t, tt.want, Intersects(tt.b, tt.a), "symmetric")
+		})
+	}
+
+	require.True(t, InAnyArea(Position{X: 4, Y: 0.5}, rect, triangle))
+	require.False(t, InAnyArea(Position{X: 10, Y: 10}, rect, triangle))
+}
+
+func TestSegmentsIntersect(t *testing.T) {
+	require.True(t, SegmentsIntersect(Position{}, Position{X: 2, Y: 2}, Position{X: 0, Y: 2}, Position{X: 2, Y: 0}))
+	require.True(t, SegmentsIntersect(Position{}, Position{X: 2}, Position{X: 2}, Position{X: 3}), "touching ends")
+	require.True(t, SegmentsIntersect(Position{}, Position{X: 2}, Position{X: 1}, Position{X: 3}), "collinear overlap")
+	require.False(t, SegmentsIntersect(Position{}, Position{X: 1}, Position{X: 2}, Position{X: 3}))
+	require.False(t, SegmentsIntersect(Position{}, Position{X: 2, Y: 2}, Position{X: 0, Y: 1}, Position{X: 0.4, Y: 3}))
+}
+
+func TestWalkTiles(t *testing.T) {
+	walk := func(a, b Position) []TileCoord {
+		var tiles []TileCoord
+		require.True(t, WalkTiles(a, b, func(tc TileCoord) bool {
+			tiles = append(tiles, tc)
+			return true
+		}))
+		return tiles
+	}
+
+	require.Equal(t, []TileCoord{{0, 0}}, walk(Position{X: 0.2, Y: 0.2}, Position{X: 0.8, Y: 0.9}))
+	require.Equal(t, []TileCoord{{0, 0}, {1, 0}, {2, 0}}, walk(Position{X: 0.5, Y: 0.5}, Position{X: 2.5, Y: 0.5}))
+	require.Equal(t, []TileCoord{{2, 0}, {1, 0}, {0, 0}}, walk(Position{X: 2.5, Y: 0.5}, Position{X: 0.5, Y: 0.5}))
+	require.Equal(t, []TileCoord{{0, 0}, {1, 1}, {2, 2}}, walk(Position{X: 0.5, Y: 0.5}, Position{X: 2.5, Y: 2.5}), "exact corners step diagonally")
+	require.Equal(t, []TileCoord{{0, 0}, {0, 1}, {1, 1}, {1, 2}}, walk(Position{X: 0.5, Y: 0.5}, Position{X: 1.5, Y: 2.5}))
+	require.Equal(t, []TileCoord{{-1, 0}, {-1, -1}}, walk(Position{X: -0.5, Y: 0.5}, Position{X: -0.5, Y: -0.5}))
+
+	visited := 0
+	require.False(t, WalkTiles(Position{}, Position{X: 5}, func(TileCoord) bool {
+		visited++
+		return visited < 2
+	}))
+	require.Equal(t, 2, visited)
+}
+
+func TestCastRay_LineOfSight(t *testing.T) {
+	wall := func(tc TileCoord) bool { return tc.X == 2 }
+
+	hit, ok := CastRay(Position{X: 0.5, Y: 0.5}, Position{X: 4.5, Y: 1.5}, wall)
+	require.True(t, ok)
+	require.Equal(t, 2, hit.X)
+	require.False(t, LineOfSight(Position{X: 0.5, Y: 0.5}, Position{X: 4.5, Y: 1.5}, wall))
+	require.True(t, LineOfSight(Position{X: 0.5, Y: 0.5}, Position{X: 1.5, Y: 3.5}, wall))
+	require.True(t, LineOfSight(Position{X: 2.5, Y: 0.5}, Position{X: 3.5, Y: 0.5}, wall), "a unit can see out of its own tile")
+
+	require.Equal(t, Position{X: 2.5, Y: -0.5}, TileCoord{X: 2, Y: -1}.Center())
+	require.Equal(t, TileCoord{X: -1, Y: 3}, TileOf(Position{X: -0.1, Y: 3.99}))
+}
@@ -0,0 +1,291 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// MAP - The Tile Grid Everyone Stands On
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// A Map is a rectangle of tiles, each with a TerrainType. Tile (x, y)
+// covers positions [x, x+1) × [y, y+1), so a Position's tile is just its
+// coordinates rounded down (see TileOf).
+//
+// Maps are loaded from two formats:
+//
+// ASCII - one character per tile, one line per row. Blank lines and lines
+// starting with '#' are skipped.
+//
+//	.  LowGround        /  LowMiddleRamp
+//	:  MiddleGround     \  MiddleHighRamp
+//	^  HighGround       ~  Water
+//
+// JSON - a name and a grid of terrain names, row by row:
+//
+//	{"name": "Lost Temple", "grid": [["LowGround", "Water"], ...]}
+//
+// 🎴 MTG ANALOGY: Landwalk. A Forest is just a card until something checks
+// "is there a Forest?"—then it matters a lot. Terrain is the same: inert
+// data that movement, vision and combat all ask questions of.
+//
+// ⚔️ SC:BW ANALOGY: The tileset. Water is pretty and impassable for a
+// Dragoon, irrelevant to a Mutalisk. High ground is just another tile until
+// a Siege Tank below tries to shoot up at it.
+//
+// 🎓 LEARNING: A Map never changes after it's loaded, so every goroutine
+// can query it without a lock. Immutability is the cheapest concurrency
+// control there is.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Level returns the tile's height: 0 low, 1 middle, 2 high. A ramp counts
+// as its lower end—a unit halfway up hasn't reached the top yet.
+func (tt TerrainType) Level() int {
+	switch tt {
+	case MiddleGround, MiddleHighRamp:
+		return 1
+	case HighGround:
+		return 2
+	}
+	return 0
+}
+
+// IsRamp reports whether the tile connects two levels
+func (tt TerrainType) IsRamp() bool {
+	return tt == LowMiddleRamp || tt == MiddleHighRamp
+}
+
+// IsWalkable reports whether ground units can stand on the tile
+func (tt TerrainType) IsWalkable() bool {
+	return tt.IsValid() && tt != Water
+}
+
+// terrainGlyphs is the ASCII map legend
+var terrainGlyphs = map[rune]TerrainType{
+	'.':  LowGround,
+	':':  MiddleGround,
+	'^':  HighGround,
+	'/':  LowMiddleRamp,
+	'\\': MiddleHighRamp,
+	'~':  Water,
+}
+
+// MapError describes a problem loading a map
+type MapError struct {
+	Source string // File path or "<reader>"
+	Line   int    // 1-based line in the source; 0 if not tied to one
+	Err    error
+}
+
+func (e *MapError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("map %s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("map %s: line %d: %v", e.Source, e.Line, e.Err)
+}
+
+func (e *MapError) Unwrap() error {
+	return e.Err
+}
+
+// Map is an immutable grid of terrain
+type Map struct {
+	Name    string
+	width   int
+	height  int
+	terrain []TerrainType // Row-major: terrain[y*width+x]
+}
+
+// NewMap builds a map from rows of terrain. Every row must be the same,
+// non-zero length.
+func NewMap(name string, rows [][]TerrainType) (*Map, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, &MapError{Source: name, Err: errors.New("map is empty")}
+	}
+	m := &Map{Name: name, width: len(rows[0]), height: len(rows)}
+	m.terrain = make([]TerrainType, 0, m.width*m.height)
+	for y, row := range rows {
+		if len(row) != m.width {
+			return nil, &MapError{Source: name, Err: fmt.Errorf("row %d has %d tiles, want %d", y, len(row), m.width)}
+		}
+		for x, tt := range row {
+			if !tt.IsValid() {
+				return nil, &MapError{Source: name, Err: fmt.Errorf("tile (%d, %d): invalid terrain %s", x, y, tt)}
+			}
+		}
+		m.terrain = append(m.terrain, row...)
+	}
+	return m, nil
+}
+
+// ParseMapASCII reads a map in the ASCII format
+func ParseMapASCII(name string, r io.Reader) (*Map, error) {
+	var rows [][]TerrainType
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		row := make([]TerrainType, 0, len(text))
+		for col, ch := range []rune(text) {
+			tt, ok := terrainGlyphs[ch]
+			if !ok {
+				return nil, &MapError{Source: name, Line: line, Err: fmt.Errorf("column %d: unknown tile %q", col+1, ch)}
+			}
+			row = append(row, tt)
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return nil, &MapError{Source: name, Line: line, Err: fmt.Errorf("row has %d tiles, want %d", len(row), len(rows[0]))}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &MapError{Source: name, Err: err}
+	}
+	return NewMap(name, rows)
+}
+
+// mapJSON is the on-disk JSON layout
+type mapJSON struct {
+	Name string     `json:"name"`
+	Grid [][]string `json:"grid"`
+}
+
+// ParseMapJSON reads a map in the JSON format. The map's name comes from
+// the file, falling back to source.
+func ParseMapJSON(source string, r io.Reader) (*Map, error) {
+	var data mapJSON
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, &MapError{Source: source, Err: err}
+	}
+	rows := make([][]TerrainType, len(data.Grid))
+	for y, names := range data.Grid {
+		rows[y] = make([]TerrainType, len(names))
+		for x, name := range names {
+			tt, ok := terrainTypeByName(name)
+			if !ok {
+				return nil, &MapError{Source: source, Err: fmt.Errorf("tile (%d, %d): unknown terrain %q", x, y, name)}
+			}
+			rows[y][x] = tt
+		}
+	}
+	if data.Name == "" {
+		data.Name = source
+	}
+	return NewMap(data.Name, rows)
+}
+
+// LoadMap reads a map in either format: anything starting with '{' is JSON
+func LoadMap(r io.Reader) (*Map, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &MapError{Source: "<reader>", Err: err}
+	}
+	return parseMap("<reader>", data, false)
+}
+
+// LoadMapFile reads a map from disk. .json files are JSON; anything else
+// is sniffed like LoadMap.
+func LoadMapFile(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &MapError{Source: path, Err: err}
+	}
+	return parseMap(path, data, strings.EqualFold(filepath.Ext(path), ".json"))
+}
+
+func parseMap(source string, data []byte, isJSON bool) (*Map, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		isJSON = true
+	}
+	if isJSON {
+		return ParseMapJSON(source, bytes.NewReader(data))
+	}
+	return ParseMapASCII(source, bytes.NewReader(data))
+}
+
+func terrainTypeByName(name string) (TerrainType, bool) {
+	for tt, n := range terrainTypeNames {
+		if strings.EqualFold(n, name) {
+			return tt, true
+		}
+	}
+	return 0, false
+}
+
+// Width returns the map width in tiles
+func (m *Map) Width() int { return m.width }
+
+// Height returns the map height in tiles
+func (m *Map) Height() int { return m.height }
+
+// Bounds returns the playable area. Positions on the far edge belong to no
+// tile; Clamp them a hair inside if you need a TerrainAt answer.
+func (m *Map) Bounds() Rectangle {
+	return Rectangle{BottomRight: Position{X: float64(m.width), Y: float64(m.height)}}
+}
+
+// InBounds reports whether the tile exists
+func (m *Map) InBounds(tc TileCoord) bool {
+	return tc.X >= 0 && tc.Y >= 0 && tc.X < m.width && tc.Y < m.height
+}
+
+// TileAt returns the tile at tc; false off the map
+func (m *Map) TileAt(tc TileCoord) (Tile, bool) {
+	if !m.InBounds(tc) {
+		return Tile{}, false
+	}
+	return Tile{
+		Position: Position{X: float64(tc.X), Y: float64(tc.Y)},
+		Terrain:  m.terrain[tc.Y*m.width+tc.X],
+	}, true
+}
+
+// TerrainAt returns the terrain under pos; false off the map
+func (m *Map) TerrainAt(pos Position) (TerrainType, bool) {
+	tile, ok := m.TileAt(TileOf(pos))
+	return tile.Terrain, ok
+}
+
+// LevelAt returns the height of the ground under pos (see
+// TerrainType.Level); 0 off the map
+func (m *Map) LevelAt(pos Position) int {
+	tt, _ := m.TerrainAt(pos)
+	return tt.Level()
+}
+
+// IsWalkable reports whether a unit on layer can be at pos. Air units go
+// anywhere on the map; ground and burrowed units need walkable terrain.
+func (m *Map) IsWalkable(pos Position, layer ElevationLayer) bool {
+	tt, ok := m.TerrainAt(pos)
+	if !ok {
+		return false
+	}
+	if layer == Air {
+		return true
+	}
+	return tt.IsWalkable()
+}
+
+// LineOfSight reports whether a viewer standing at from can see to: no
+// tile along the way (to's included) is higher than from's ground
+//
+// ⚔️ SC:BW: A Dragoon at the bottom of a ramp can't see the Siege Tank on
+// the high ground above it, but the tank can see the Dragoon just fine.
+func (m *Map) LineOfSight(from, to Position) bool {
+	level := m.LevelAt(from)
+	return LineOfSight(from, to, func(tc TileCoord) bool {
+		tile, ok := m.TileAt(tc)
+		return ok && tile.Terrain.Level() > level
+	})
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testMapASCII is a low-ground valley with a lake, a ramp up to the middle
+// level and a second ramp to high ground on the right
+const testMapASCII = `
+# valley.txt
+...~~.:::
+...~~/:\^
+......:^^
+`
+
+func TestParseMapASCII(t *testing.T) {
+	m, err := ParseMapASCII("valley", strings.NewReader(testMapASCII))
+	require.NoError(t, err)
+	require.Equal(t, 9, m.Width())
+	require.Equal(t, 3, m.Height())
+	require.Equal(t, NewRectangle(Position{}, Position{X: 9, Y: 3}), m.Bounds())
+
+	terrain := func(x, y float64) TerrainType {
+		tt, ok := m.TerrainAt(Position{X: x, Y: y})
+		require.True(t, ok)
+		return tt
+	}
+	require.Equal(t, LowGround, terrain(0.5, 0.5))
+	require.Equal(t, Water, terrain(3.9, 1.1))
+	require.Equal(t, LowMiddleRamp, terrain(5.5, 1.5))
+	require.Equal(t, MiddleHighRamp, terrain(7.5, 1.5))
+	require.Equal(t, HighGround, terrain(8.5, 2.5))
+
+	_, ok := m.TerrainAt(Position{X: -0.1, Y: 0})
+	require.False(t, ok)
+	_, ok = m.TerrainAt(Position{X: 9, Y: 0})
+	require.False(t, ok, "the far edge belongs to no tile")
+
+	tile, ok := m.TileAt(TileCoord{X: 6, Y: 0})
+	require.True(t, ok)
+	require.Equal(t, Tile{Position: Position{X: 6}, Terrain: MiddleGround}, tile)
+}
+
+func TestParseMapASCII_Errors(t *testing.T) {
+	_, err := ParseMapASCII("bad", strings.NewReader("...\n.x.\n"))
+	var mapErr *MapError
+	require.True(t, errors.As(err, &mapErr))
+	require.Equal(t, 2, mapErr.Line)
+	require.ErrorContains(t, err, `map bad: line 2: column 2: unknown tile 'x'`)
+
+	_, err = ParseMapASCII("ragged", strings.NewReader("...\n..\n"))
+	require.ErrorContains(t, err, "row has 2 tiles, want 3")
+
+	_, err = ParseMapASCII("blank", strings.NewReader("# nothing here\n"))
+	require.ErrorContains(t, err, "map is empty")
+}
+
+func TestParseMapJSON(t *testing.T) {
+	m, err := LoadMap(strings.NewReader(`{
+		"name": "Lost Temple",
+		"grid": [["LowGround", "water"], ["HighGround", "MiddleHighRamp"]]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, "Lost Temple", m.Name)
+
+	tt, _ := m.TerrainAt(Position{X: 1.5, Y: 0.5})
+	require.Equal(t, Water, tt)
+	require.Equal(t, 2, m.LevelAt(Position{X: 0.5, Y: 1.5}))
+
+	_, err = ParseMapJSON("bad.json", strings.NewReader(`{"grid": [["Lava"]]}`))
+	require.ErrorContains(t, err, `tile (0, 0): unknown terrain "Lava"`)
+	_, err = ParseMapJSON("ragged.json", strings.NewReader(`{"grid": [["Water", "Water"], ["Water"]]}`))
+	require.ErrorContains(t, err, "row 1 has 1 tiles, want 2")
+}
+
+func TestLoadMapFile(t *testing.T) {
+	dir := t.TempDir()
+	ascii := filepath.Join(dir, "valley.txt")
+	require.NoError(t, os.WriteFile(ascii, []byte(testMapASCII), 0o644))
+	jsonPath := filepath.Join(dir, "pond.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"grid": [["Water"]]}`), 0o644))
+
+	m, err := LoadMapFile(ascii)
+	require.NoError(t, err)
+	require.Equal(t, ascii, m.Name)
+	require.Equal(t, 9, m.Width())
+
+	m, err = LoadMapFile(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, jsonPath, m.Name, "no name in the file")
+
+	_, err = LoadMapFile(filepath.Join(dir, "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMap_IsWalkable(t *testing.T) {
+	m, err := ParseMapASCII("valley", strings.NewReader(testMapASCII))
+	require.NoError(t, err)
+
+	lake := Position{X: 3.5, Y: 0.5}
+	require.False(t, m.IsWalkable(lake, Ground), "ground units can't cross water")
+	require.False(t, m.IsWalkable(lake, Burrowed))
+	require.True(t, m.IsWalkable(lake, Air), "air units ignore terrain")
+	require.True(t, m.IsWalkable(Position{X: 5.5, Y: 1.5}, Ground), "ramps are walkable")
+	require.False(t, m.IsWalkable(Position{X: 20, Y: 0}, Air), "nobody leaves the map")
+}
+
+func TestTerrainType_Levels(t *testing.T) {
+	require.Equal(t, 0, LowGround.Level())
+	require.Equal(t, 0, LowMiddleRamp.Level())
+	require.Equal(t, 1, MiddleGround.Level())
+	require.Equal(t, 1, MiddleHighRamp.Level())
+	require.Equal(t, 2, HighGround.Level())
+	require.True(t, LowMiddleRamp.IsRamp())
+	require.False(t, HighGround.IsRamp())
+	require.False(t, TerrainType(99).IsWalkable())
+}
+
+func TestMap_LineOfSight(t *testing.T) {
+	m, err := ParseMapASCII("valley", strings.NewReader(testMapASCII))
+	require.NoError(t, err)
+
+	low := Position{X: 0.5, Y: 2.5}
+	middle := Position{X: 6.5, Y: 0.5}
+	high := Position{X: 8.5, Y: 2.5}
+
+	require.True(t, m.LineOfSight(low, Position{X: 5.5, Y: 2.5}), "across the valley floor")
+	require.False(t, m.LineOfSight(low, middle), "can't see up the cliff")
+	require.True(t, m.LineOfSight(middle, low), "but can see down it")
+	require.False(t, m.LineOfSight(middle, high))
+	require.True(t, m.LineOfSight(high, low))
+}
@@ -0,0 +1,229 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// MOVEMENT - Units Walk, They Don't Teleport
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// A move order only sets a destination. Every tick the unit speeds up by its
+// acceleration (capped at top speed) and steps toward the destination by
+// velocity × elapsed time. On arrival it stops and goes Idle.
+//
+// 🎴 MTG ANALOGY: Haste vs summoning sickness—some creatures get going right
+// away, others need a turn to wind up.
+//
+// ⚔️ SC:BW ANALOGY: A Zergling crosses the map before a Reaver has left its
+// natural. That gap is why retreats, run-bys and kiting work at all.
+//
+// In a world with terrain, ground and burrowed units can't cross water or
+// leave the map. A move to somewhere they couldn't stand is refused up
+// front; an order that would walk them into such a tile on the way stops
+// them at the edge and fails with EventOrderFailed. Air units fly over it
+// all.
+//
+// 🎓 LEARNING: Position only changes inside the unit's own goroutine (tick).
+// Other goroutines just read it through GetPosition—the mutex keeps those
+// reads consistent while the unit is mid-step.
+//
+// 🔥 PRO TIP FROM FLASH:
+// "Vultures win because they're faster than what chases them. Get the speed
+//  numbers right and the micro takes care of itself."
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// MoveEventInterval is how often a moving unit reports progress with
+// EventMoved. Ticks are much faster; reporting each one would flood listeners.
+const MoveEventInterval = 250 * time.Millisecond
+
+// ErrUnwalkable refuses (or fails) an order that would take a unit onto
+// terrain it can't stand on. Wrapped in an *OrderError.
+var ErrUnwalkable = errors.New("terrain not walkable")
+
+// MoveProgress is the Data carried by EventMoved
+type MoveProgress struct {
+	Position    Position // Where the unit is now
+	Destination Position // Where it's headed
+	Remaining   float64  // Distance left, 0 on arrival
+}
+
+// GetSpeed returns the unit's top speed in tiles per second
+func (u *Unit) GetSpeed() float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.speed
+}
+
+// GetVelocity returns how fast the unit is moving right now
+func (u *Unit) GetVelocity() float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.velocity
+}
+
+// GetDestination returns the target of the current move order. Only
+// meaningful while the unit is Moving.
+func (u *Unit) GetDestination() Position {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.destination
+}
+
+// startMove points the unit at dest. A unit that is already moving keeps its
+// momentum and just turns; anything else starts from a standstill.
+func (u *Unit) startMove(dest Position, now time.Time) {
+	u.mu.Lock()
+	if u.state != Moving {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Moving
+	u.target = nil
+	u.destination = dest
+	u.lastMoveEvent = now
+	progress := u.moveProgress()
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventMoved, Source: u, Timestamp: now, Data: progress})
+}
+
+// advanceMove moves the unit one tick along its order, reporting progress
+// every MoveEventInterval. Arrival completes the order (see orderDone);
+// terrain in the way fails it.
+func (u *Unit) advanceMove(now time.Time) {
+	u.mu.Lock()
+	arrived, err := u.stepToward(u.destination, now)
+	progress, report := u.progressDue(now, arrived || err != nil)
+	u.mu.Unlock()
+
+	if report {
+		u.emit(UnitEvent{Type: EventMoved, Source: u, Timestamp: now, Data: progress})
+	}
+	switch {
+	case err != nil:
+		u.failOrder(nil, err, now)
+	case arrived:
+		u.orderDone(now)
+	}
+}
+
+// approach takes one step toward dest without changing the unit's state.
+// Attack orders use it to close in on a target that is out of range. The
+// error is non-nil when terrain blocks the way; the caller decides what
+// happens to its order.
+func (u *Unit) approach(dest Position, now time.Time) error {
+	u.mu.Lock()
+	u.destination = dest
+	_, err := u.stepToward(dest, now)
+	progress, report := u.progressDue(now, err != nil)
+	u.mu.Unlock()
+
+	if report {
+		u.emit(UnitEvent{Type: EventMoved, Source: u, Timestamp: now, Data: progress})
+	}
+	return err
+}
+
+// halt stands the unit still so a later approach starts from zero speed
+func (u *Unit) halt(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.velocity = 0
+	u.lastStep = now
+}
+
+// stepToward advances position toward dest by the time since the last step
+// and reports whether the unit got there. If the step would cross terrain
+// the unit can't walk on, it stays put, stops, and gets an error instead.
+// Caller must hold u.mu.
+func (u *Unit) stepToward(dest Position, now time.Time) (bool, error) {
+	dt := now.Sub(u.lastStep).Seconds()
+	u.lastStep = now
+	if dt < 0 {
+		dt = 0
+	}
+
+	if u.acceleration > 0 {
+		u.velocity = math.Min(u.speed, u.velocity+u.acceleration*dt)
+	} else {
+		u.velocity = u.speed
+	}
+
+	remaining := u.position.Distance(dest)
+	step := u.velocity * dt
+	next, arrived := dest, step >= remaining
+	if !arrived {
+		next.X = u.position.X + (dest.X-u.position.X)/remaining*step
+		next.Y = u.position.Y + (dest.Y-u.position.Y)/remaining*step
+	}
+	if err := u.terrainErrorLocked(u.order.Type, next); err != nil {
+		u.velocity = 0
+		return false, err
+	}
+	u.position = next
+	if arrived {
+		u.velocity = 0
+	}
+	return arrived, nil
+}
+
+// terrainErrorLocked explains why the unit can't walk from where it stands
+// to pos, or returns nil if it can. Its own tile never blocks, so a unit
+// dropped somewhere odd can still walk out. Caller must hold u.mu.
+func (u *Unit) terrainErrorLocked(order CommandType, pos Position) error {
+	if u.world == nil || u.world.terrain == nil || u.elevationLayer == Air {
+		return nil
+	}
+	m, layer := u.world.terrain, u.elevationLayer
+	tile, blocked := CastRay(u.position, pos, func(tc TileCoord) bool {
+		return !m.IsWalkable(tc.Center(), layer)
+	})
+	if !blocked {
+		return nil
+	}
+	return &OrderError{UnitID: u.ID, Order: order, Reason: terrainReason(m, tile), Err: ErrUnwalkable}
+}
+
+// destinationError refuses a move-type order to somewhere the unit couldn't
+// stand
+func (u *Unit) destinationError(order CommandType, dest Position) error {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.world == nil || u.world.terrain == nil || u.world.terrain.IsWalkable(dest, u.elevationLayer) {
+		return nil
+	}
+	return &OrderError{UnitID: u.ID, Order: order, Reason: terrainReason(u.world.terrain, TileOf(dest)), Err: ErrUnwalkable}
+}
+
+// terrainReason names what's in the way at tc
+func terrainReason(m *Map, tc TileCoord) string {
+	tile, ok := m.TileAt(tc)
+	if !ok {
+		return "off the map"
+	}
+	return fmt.Sprintf("%s at %v", tile.Terrain, tc)
+}
+
+// progressDue snapshots the move and reports whether an EventMoved is due:
+// always when forced, otherwise once per MoveEventInterval. Caller must hold u.mu.
+func (u *Unit) progressDue(now time.Time, force bool) (MoveProgress, bool) {
+	due := force || now.Sub(u.lastMoveEvent) >= MoveEventInterval
+	if due {
+		u.lastMoveEvent = now
+	}
+	return u.moveProgress(), due
+}
+
+// moveProgress snapshots the current move. Caller must hold u.mu.
+func (u *Unit) moveProgress() MoveProgress {
+	return MoveProgress{
+		Position:    u.position,
+		Destination: u.destination,
+		Remaining:   u.position.Distance(u.destination),
+	}
+}
@@ -0,0 +1,175 @@
+package types
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeStats_Speed(t *testing.T) {
+	var wg sync.WaitGroup
+	zergling := NewUnit("ling", Zergling, Position{}, &wg)
+	reaver := NewUnit("reaver", Reaver, Position{}, &wg)
+
+	require.Greater(t, zergling.GetSpeed(), reaver.GetSpeed(), "Zerglings outrun Reavers")
+	require.Equal(t, 0.0, zergling.GetVelocity(), "units spawn standing still")
+
+	zergling.Shutdown()
+	reaver.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Move_ArrivesAndGoesIdle(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	events := marine.Subscribe(EventTypes(EventMoved, EventIdle))
+
+	dest := Position{X: 1.5, Y: 0}
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: dest}))
+
+	var progress []MoveProgress
+	deadline := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case ev := <-events.Events():
+			switch ev.Type {
+			case EventMoved:
+				progress = append(progress, ev.Data.(MoveProgress))
+			case EventIdle:
+				done = true
+			}
+		case <-deadline:
+			t.Fatal("marine never arrived")
+		}
+	}
+
+	require.Equal(t, Idle, marine.GetState())
+	require.Equal(t, dest, marine.GetPosition())
+	require.Equal(t, 0.0, marine.GetVelocity())
+
+	// Start, at least one progress report on the way, then arrival
+	require.GreaterOrEqual(t, len(progress), 3)
+	require.Equal(t, 1.5, progress[0].Remaining)
+	require.Equal(t, 0.0, progress[len(progress)-1].Remaining)
+	for i := 1; i < len(progress); i++ {
+		require.Less(t, progress[i].Remaining, progress[i-1].Remaining)
+	}
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Move_Acceleration(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("slow-start", strings.NewReader(`{"Overlord": {"speed": 10, "acceleration": 1}}`)))
+
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	overlord := NewUnit("ovie", Overlord, Position{}, &wg, WithClock(clock))
+	require.NoError(t, sendOrder(t, overlord, Command{Type: CmdMove, Dest: Position{X: 100}}))
+
+	for i := 0; i < 5; i++ { // About 200ms
+		clock.Advance(TickRate)
+	}
+	require.Eventually(t, func() bool { return overlord.GetVelocity() > 0.1 }, time.Second, time.Millisecond)
+	velocity := overlord.GetVelocity()
+	require.Greater(t, velocity, 0.0)
+	require.Less(t, velocity, 1.0, "still winding up toward top speed")
+
+	overlord.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Move_InterruptedByNewOrder(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithClock(clock))
+
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 100}}))
+	advanceUntil(t, clock, func() bool { return marine.GetPosition().X > 0.1 })
+
+	// Redirect mid-move
+	back := Position{X: 0, Y: 0.5}
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: back}))
+	advanceUntil(t, clock, func() bool {
+		return marine.GetState() == Idle && marine.GetPosition() == back
+	})
+
+	// Stop mid-move: the marine stays where it was
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 100}}))
+	advanceUntil(t, clock, func() bool { return marine.GetPosition().X > 0.1 })
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdStop}))
+	require.Equal(t, Idle, marine.GetState())
+
+	stopped := marine.GetPosition()
+	clock.Advance(time.Second)
+	settle(t, marine)
+	require.Equal(t, stopped, marine.GetPosition())
+	require.Less(t, stopped.X, 100.0)
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnitStatsData_Validate_Movement(t *testing.T) {
+	stats, _, ok := unitStats.lookup(Marine)
+	require.True(t, ok)
+	stats.Speed = 0
+	stats.Acceleration = -1
+
+	err := stats.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "speed must be positive")
+	require.Contains(t, err.Error(), "acceleration must not be negative")
+}
+
+// lakeMapASCII has a lake across the top two rows and dry land below it
+const lakeMapASCII = `
+..~~..
+..~~..
+......
+`
+
+func TestUnit_Move_BlockedByWater(t *testing.T) {
+	m, err := ParseMapASCII("lake", strings.NewReader(lakeMapASCII))
+	require.NoError(t, err)
+	world := NewWorld(m, 1)
+	clock := NewManualClock(clockStart)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{X: 0.5, Y: 0.5}, &wg, WithClock(clock))
+	overlord := NewUnit("ovie", Overlord, Position{X: 0.5, Y: 0.5}, &wg, WithClock(clock))
+	world.Add(marine)
+	world.Add(overlord)
+	failed := marine.Subscribe(EventTypes(EventOrderFailed))
+
+	// Straight into the lake: refused, nothing changes
+	for _, order := range []CommandType{CmdMove, CmdPatrol, CmdAttackMove} {
+		err := sendOrder(t, marine, Command{Type: order, Dest: Position{X: 2.5, Y: 0.5}})
+		require.ErrorIs(t, err, ErrUnwalkable, order)
+		require.Equal(t, Idle, marine.GetState())
+	}
+
+	// Across it: the Marine stops at the shore and the order fails
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 5.5, Y: 0.5}}))
+	advanceUntil(t, clock, func() bool { return marine.GetState() == Idle })
+	ev := waitForEvent(t, failed, EventOrderFailed)
+	require.ErrorIs(t, ev.Data.(error), ErrUnwalkable)
+	require.Less(t, marine.GetPosition().X, 2.0, "never set foot in the water")
+
+	// Around it is fine, and the Overlord flies straight over
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 0.5, Y: 2.5}}))
+	advanceUntil(t, clock, func() bool { return marine.GetState() == Idle })
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 5.5, Y: 2.5}}))
+	require.NoError(t, sendOrder(t, overlord, Command{Type: CmdMove, Dest: Position{X: 5.5, Y: 0.5}}))
+	advanceUntil(t, clock, func() bool { return marine.GetState() == Idle && overlord.GetState() == Idle })
+	require.Equal(t, Position{X: 5.5, Y: 2.5}, marine.GetPosition())
+	require.Equal(t, Position{X: 5.5, Y: 0.5}, overlord.GetPosition())
+
+	marine.Shutdown()
+	overlord.Shutdown()
+	wg.Wait()
+}
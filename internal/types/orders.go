@@ -0,0 +1,563 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// ORDER QUEUE - Shift-Click Everything
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Every unit carries out one order at a time and keeps a list of orders
+// waiting behind it:
+//
+//	Command{Queue: false}  - replace: drop the waiting orders, start this now
+//	Command{Queue: true}   - append: start after everything already queued
+//	                         (or right away if the unit has nothing to do)
+//
+// When an order completes—arrived, target dead, repair finished, unit
+// built—the next one starts. With nothing left the unit goes Idle and
+// emits EventIdle. Patrol never completes on its own.
+//
+// 🎴 MTG ANALOGY: The stack, but first-in-first-out. Queued orders resolve
+// in the order you put them there; casting without shift counters the lot.
+//
+// ⚔️ SC:BW ANALOGY: Shift-click waypoints for a Dropship, a worker told to
+// build a Supply Depot and then go back to mining, attack-moving an army
+// across the map and letting them fight whatever they meet.
+//
+// 🎓 LEARNING: The queue is read and written by the unit's goroutine, but
+// PendingOrders/ClearOrders come from anywhere—so it lives under u.mu like
+// the rest of the mutable state.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Order errors. Capability problems are wrapped in an *OrderError.
+var (
+	ErrOrderCanceled  = errors.New("order canceled")
+	ErrCannotRepair   = errors.New("cannot repair")
+	ErrCannotBuild    = errors.New("cannot build")
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
+// Delivery errors: the command never got as far as the unit's goroutine,
+// or was still queued when the unit stopped. Wrapped in a *CommandError.
+// A dead unit refuses with ErrUnitDead (see death.go).
+//
+// 🎓 LEARNING: These two are shared by every layer. The unit manager,
+// resource manager, battle simulator and commanders re-export them rather
+// than minting their own, and each wraps them in its own error struct with
+// Unwrap. A caller branches with errors.Is(err, types.ErrShuttingDown) or
+// errors.As(err, &cmdErr) no matter which layer refused, instead of
+// matching error strings.
+var (
+	ErrShuttingDown = errors.New("unit is shutting down")
+	ErrBackpressure = errors.New("command queue full - backpressure")
+)
+
+// CommandError explains why a command was never carried out
+type CommandError struct {
+	UnitID  string
+	Command Command
+	Err     error // ErrShuttingDown, ErrBackpressure or ErrUnitDead
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.UnitID, e.Command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// OrderError explains why a unit refused an order it isn't able to carry out
+type OrderError struct {
+	UnitID string
+	Order  CommandType
+	Reason string
+	Err    error // ErrCannotRepair, ErrCannotBuild, or a transformation or transport error
+}
+
+func (e *OrderError) Error() string {
+	return fmt.Sprintf("%s: %s: %v: %s", e.UnitID, e.Order, e.Err, e.Reason)
+}
+
+func (e *OrderError) Unwrap() error {
+	return e.Err
+}
+
+// RepairRange is how close (edge to edge) a unit must be to repair its target
+const RepairRange = 1.0
+
+// TargetScanner finds something for a unit to fight while it attack-moves
+// or patrols. nil means nothing worth fighting nearby.
+type TargetScanner interface {
+	ScanForTarget(u *Unit) *Unit
+}
+
+// SetTargetScanner sets where the unit looks for enemies during attack-move
+// and patrol. Without one those orders travel like plain moves.
+func (u *Unit) SetTargetScanner(s TargetScanner) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.scanner = s
+}
+
+// CurrentOrder returns the order being carried out. ok is false when Idle.
+func (u *Unit) CurrentOrder() (Command, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.state == Idle || u.state == Dead {
+		return Command{}, false
+	}
+	return u.order, true
+}
+
+// PendingOrders returns a copy of the orders queued behind the current one
+func (u *Unit) PendingOrders() []Command {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return append([]Command(nil), u.orders...)
+}
+
+// ClearOrders drops every queued order (the current one keeps going) and
+// returns how many were dropped. Each dropped order's Result receives
+// ErrOrderCanceled.
+func (u *Unit) ClearOrders() int {
+	return u.cancelOrders(ErrOrderCanceled)
+}
+
+func (u *Unit) cancelOrders(reason error) int {
+	u.mu.Lock()
+	dropped := u.orders
+	u.orders = nil
+	u.mu.Unlock()
+
+	for _, cmd := range dropped {
+		cmd.reply(reason)
+	}
+	return len(dropped)
+}
+
+func (u *Unit) currentOrder() CommandType {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.order.Type
+}
+
+// receive applies queue semantics to an incoming command. A unit in the
+// middle of a transformation refuses anything but queued orders; a unit
+// riding in a transport or frozen in stasis refuses everything. Instant orders (cloak toggles)
+// run without touching the current order or the queue.
+func (u *Unit) receive(cmd Command) {
+	if err := u.loadedError(cmd); err != nil {
+		cmd.reply(err)
+		return
+	}
+	if err := u.stasisError(cmd); err != nil {
+		cmd.reply(err)
+		return
+	}
+	u.mu.Lock()
+	busy := u.state != Idle && u.state != HoldingPosition
+	if cmd.Queue && busy {
+		u.orders = append(u.orders, cmd)
+		u.mu.Unlock()
+		return
+	}
+	u.mu.Unlock()
+
+	if err := u.busyError(cmd); err != nil {
+		cmd.reply(err)
+		return
+	}
+	if cmd.Type.instant() || cmd.Queue {
+		u.execute(cmd)
+		return
+	}
+
+	// A fresh order replaces the queue, but only once it's accepted: hold
+	// the queue aside while it's checked and put it back if it's refused
+	u.mu.Lock()
+	held := u.orders
+	u.orders = nil
+	u.mu.Unlock()
+	if u.execute(cmd) != nil {
+		u.mu.Lock()
+		u.orders = append(held, u.orders...)
+		u.mu.Unlock()
+		return
+	}
+	for _, dropped := range held {
+		dropped.reply(ErrOrderCanceled)
+	}
+}
+
+// execute starts cmd right now and reports the outcome on its Result. A
+// rejected order leaves the unit doing whatever it was doing before.
+func (u *Unit) execute(cmd Command) error {
+	err := u.immobileError(cmd.Type)
+	if err == nil {
+		err = u.handle(cmd)
+	}
+	if err == nil && !cmd.Type.instant() {
+		u.mu.Lock()
+		u.order = cmd
+		u.mu.Unlock()
+	}
+	cmd.reply(err)
+	return err
+}
+
+// handle passes cmd to the handler for its type
+func (u *Unit) handle(cmd Command) error {
+	switch cmd.Type {
+	case CmdMove:
+		return u.handleMove(cmd)
+	case CmdAttack:
+		return u.handleAttack(cmd)
+	case CmdStop:
+		return u.handleStop(cmd)
+	case CmdHold:
+		return u.handleHold(cmd)
+	case CmdPatrol:
+		return u.handlePatrol(cmd)
+	case CmdRepair:
+		return u.handleRepair(cmd)
+	case CmdBuild:
+		return u.handleBuild(cmd)
+	case CmdAttackMove:
+		return u.handleAttackMove(cmd)
+	case CmdMorph:
+		return u.handleMorph(cmd)
+	case CmdSiege:
+		return u.handleSiege(cmd)
+	case CmdUnsiege:
+		return u.handleUnsiege(cmd)
+	case CmdBurrow:
+		return u.handleBurrow(cmd)
+	case CmdUnburrow:
+		return u.handleUnburrow(cmd)
+	case CmdLoad:
+		return u.handleLoad(cmd)
+	case CmdUnload:
+		return u.handleUnload(cmd)
+	case CmdCloak:
+		return u.handleCloak(cmd)
+	case CmdDecloak:
+		return u.handleDecloak(cmd)
+	case CmdCast:
+		return u.handleCast(cmd)
+	default:
+		return &OrderError{UnitID: u.ID, Order: cmd.Type, Reason: "no handler", Err: ErrUnknownCommand}
+	}
+}
+
+// orderDone finishes the current order and starts the next queued one that
+// the unit accepts. With nothing left to do the unit goes Idle.
+func (u *Unit) orderDone(now time.Time) {
+	for {
+		u.mu.Lock()
+		if u.state == Dead {
+			u.mu.Unlock()
+			return
+		}
+		if len(u.orders) == 0 {
+			u.state = Idle
+			u.target = nil
+			u.velocity = 0
+			u.order = Command{}
+			u.mu.Unlock()
+			u.emit(UnitEvent{Type: EventIdle, Source: u, Timestamp: now})
+			return
+		}
+		next := u.orders[0]
+		u.orders = u.orders[1:]
+		// Whatever the next order is, the previous one is over
+		u.state = Idle
+		u.target = nil
+		u.mu.Unlock()
+
+		if u.execute(next) == nil && !next.Type.instant() {
+			return
+		}
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// ATTACK-MOVE & PATROL - Travel, Fight, Carry On
+// ═══════════════════════════════════════════════════════════════════════════
+
+func (u *Unit) handleAttackMove(cmd Command) error {
+	if err := u.destinationError(cmd.Type, cmd.Dest); err != nil {
+		return err
+	}
+	u.startMove(cmd.Dest, u.clock.Now())
+	return nil
+}
+
+// handlePatrol walks between the unit's current position and cmd.Dest
+// until given another order
+func (u *Unit) handlePatrol(cmd Command) error {
+	if err := u.destinationError(cmd.Type, cmd.Dest); err != nil {
+		return err
+	}
+	now := u.clock.Now()
+	u.mu.Lock()
+	if u.state != Moving && u.state != Patrolling {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Patrolling
+	u.target = nil
+	u.patrolFrom = u.position
+	u.patrolTo = cmd.Dest
+	u.destination = cmd.Dest
+	u.lastMoveEvent = now
+	progress := u.moveProgress()
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventMoved, Source: u, Timestamp: now, Data: progress})
+	return nil
+}
+
+// advancePatrol walks the current leg and turns around at each end
+func (u *Unit) advancePatrol(now time.Time) {
+	u.mu.Lock()
+	arrived, err := u.stepToward(u.patrolTo, now)
+	if arrived {
+		u.patrolFrom, u.patrolTo = u.patrolTo, u.patrolFrom
+		u.destination = u.patrolTo
+	}
+	progress, report := u.progressDue(now, arrived || err != nil)
+	u.mu.Unlock()
+
+	if report {
+		u.emit(UnitEvent{Type: EventMoved, Source: u, Timestamp: now, Data: progress})
+	}
+	if err != nil {
+		u.failOrder(nil, err, now)
+	}
+}
+
+// failOrder abandons the current order, explaining why on the event
+// stream, and moves on to the next one
+func (u *Unit) failOrder(target *Unit, err error, now time.Time) {
+	u.emit(UnitEvent{Type: EventOrderFailed, Source: u, Target: target, Timestamp: now, Data: err})
+	u.orderDone(now)
+}
+
+// scanForTarget asks the scanner for something to fight and engages it.
+// Returns true if the unit is now Attacking.
+func (u *Unit) scanForTarget(now time.Time) bool {
+	u.mu.RLock()
+	scanner := u.scanner
+	u.mu.RUnlock()
+	if scanner == nil {
+		return false
+	}
+	target := scanner.ScanForTarget(u)
+	if target == nil || target == u || u.attackError(target) != nil {
+		return false
+	}
+	u.engage(target, now)
+	return true
+}
+
+// resumeTravel goes back to attack-moving or patrolling after a fight.
+// Returns false if the current order is something else.
+func (u *Unit) resumeTravel(now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	switch u.order.Type {
+	case CmdAttackMove:
+		u.state = Moving
+		u.destination = u.order.Dest
+	case CmdPatrol:
+		u.state = Patrolling
+		u.destination = u.patrolTo
+	default:
+		return false
+	}
+	u.target = nil
+	u.lastStep = now
+	return true
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// REPAIR & BUILD - Worker Orders
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// ⚔️ SC:BW ANALOGY: Repairing a unit from empty to full takes as long as
+// building it did, so a Battlecruiser takes far longer than a Vulture.
+
+// handleRepair starts repairing cmd.Target. Only units with canRepair can
+// repair, and only mechanical targets can be repaired.
+func (u *Unit) handleRepair(cmd Command) error {
+	u.mu.RLock()
+	canRepair := u.canRepair
+	u.mu.RUnlock()
+	if !canRepair {
+		return &OrderError{UnitID: u.ID, Order: CmdRepair, Reason: fmt.Sprintf("%s can't repair", u.GetType()), Err: ErrCannotRepair}
+	}
+	target := cmd.Target
+	switch {
+	case target == nil:
+		return &TargetingError{UnitID: u.ID, Err: ErrNoTarget}
+	case target == u:
+		return &OrderError{UnitID: u.ID, Order: CmdRepair, Reason: "can't repair itself", Err: ErrCannotRepair}
+	case target.IsDead():
+		return &TargetingError{UnitID: u.ID, TargetID: target.ID, Layer: target.GetElevationLayer(), Reason: "target is dead", Err: ErrUnitDead}
+	case !target.IsMechanical():
+		return &OrderError{UnitID: u.ID, Order: CmdRepair, Reason: fmt.Sprintf("%s is not mechanical", target.GetType()), Err: ErrCannotRepair}
+	}
+
+	now := u.clock.Now()
+	u.mu.Lock()
+	if u.state != Moving {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Repairing
+	u.target = target
+	u.lastWork = now
+	u.mu.Unlock()
+	return nil
+}
+
+// continueRepair walks up to the target, then restores health at
+// maxHealth / buildTime per second until it's full.
+func (u *Unit) continueRepair(now time.Time) {
+	target := u.GetTarget()
+	if target == nil || target.IsDead() || target.GetHealthExact() >= float64(target.GetMaxHealth()) {
+		u.orderDone(now)
+		return
+	}
+
+	reach := RepairRange + u.GetSize().Radius() + target.GetSize().Radius()
+	if u.GetPosition().DistanceSq(target.GetPosition()) > reach*reach {
+		if err := u.approach(target.GetPosition(), now); err != nil {
+			u.failOrder(target, err, now)
+			return
+		}
+		u.mu.Lock()
+		u.lastWork = now
+		u.mu.Unlock()
+		return
+	}
+
+	u.mu.Lock()
+	elapsed := now.Sub(u.lastWork).Seconds()
+	u.lastWork = now
+	u.velocity = 0
+	u.lastStep = now
+	u.mu.Unlock()
+
+	target.repair(float64(target.GetMaxHealth()) / target.GetBuildTime() * elapsed)
+}
+
+// repair restores health up to the maximum; dead units stay dead
+func (u *Unit) repair(amount float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state == Dead {
+		return
+	}
+	u.health = math.Min(u.health+amount, float64(u.maxHealth))
+}
+
+// handleBuild sends a worker to cmd.Dest to build a cmd.Build unit
+func (u *Unit) handleBuild(cmd Command) error {
+	u.mu.RLock()
+	worker := u.worker
+	u.mu.RUnlock()
+	if !worker {
+		return &OrderError{UnitID: u.ID, Order: CmdBuild, Reason: fmt.Sprintf("%s is not a worker", u.GetType()), Err: ErrCannotBuild}
+	}
+	if !cmd.Build.IsValid() {
+		return &OrderError{UnitID: u.ID, Order: CmdBuild, Reason: fmt.Sprintf("unknown unit type %s", cmd.Build), Err: ErrCannotBuild}
+	}
+
+	buildTime := 0.0
+	if stats, ok := LookupUnitStats(cmd.Build); ok {
+		buildTime = stats.BuildTime
+	}
+
+	now := u.clock.Now()
+	u.mu.Lock()
+	if u.state != Moving {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Building
+	u.target = nil
+	u.destination = cmd.Dest
+	u.workStarted = time.Time{}
+	u.workDuration = seconds(buildTime)
+	u.mu.Unlock()
+	return nil
+}
+
+// continueBuild walks to the site, then waits out the product's build time
+// and spawns it there with EventBuilt. The new unit counts against the
+// builder's WaitGroup, so whoever waits on that must shut it down too; a
+// builder in a world puts it in the same world, where World.Units finds
+// it whether or not anyone heard the event.
+func (u *Unit) continueBuild(now time.Time) {
+	u.mu.Lock()
+	order := u.order
+	if u.workStarted.IsZero() {
+		arrived, err := u.stepToward(order.Dest, now)
+		if arrived {
+			u.workStarted = now
+		}
+		progress, report := u.progressDue(now, arrived || err != nil)
+		u.mu.Unlock()
+		if report {
+			u.emit(UnitEvent{Type: EventMoved, Source: u, Timestamp: now, Data: progress})
+		}
+		if err != nil {
+			u.failOrder(nil, err, now)
+		}
+		return
+	}
+	if now.Sub(u.workStarted) < u.workDuration {
+		u.mu.Unlock()
+		return
+	}
+	u.builtCount++
+	id := fmt.Sprintf("%s/%s-%d", u.ID, order.Build, u.builtCount)
+	world := u.world
+	u.mu.Unlock()
+
+	built := NewUnit(id, order.Build, order.Dest, u.wg, WithClock(u.clock), WithOwner(u.owner))
+	if world != nil {
+		world.Add(built)
+	}
+	u.emit(UnitEvent{Type: EventBuilt, Source: u, Target: built, Timestamp: now, Data: built})
+	u.orderDone(now)
+}
+
+// IsMechanical reports whether the unit can be repaired
+func (u *Unit) IsMechanical() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.mechanical
+}
+
+// GetMaxHealth returns the unit's maximum health
+func (u *Unit) GetMaxHealth() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.maxHealth
+}
+
+// GetBuildTime returns how many seconds this unit type takes to build
+func (u *Unit) GetBuildTime() float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.buildTime
+}
@@ -0,0 +1,318 @@
+package types
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scannerFunc adapts a function to TargetScanner
+type scannerFunc func(u *Unit) *Unit
+
+func (f scannerFunc) ScanForTarget(u *Unit) *Unit { return f(u) }
+
+func TestCommandType_String(t *testing.T) {
+	require.Equal(t, "Move", CmdMove.String())
+	require.Equal(t, "AttackMove", CmdAttackMove.String())
+	require.Equal(t, "CommandType(999)", CommandType(999).String())
+	require.False(t, commandTypeCount.IsValid())
+}
+
+func TestCommand_String_NewOrders(t *testing.T) {
+	var wg sync.WaitGroup
+	vulture := NewUnit("vulture", Vulture, Position{}, &wg)
+
+	require.Equal(t, "Patrol to (1.0, 2.0)", Command{Type: CmdPatrol, Dest: Position{X: 1, Y: 2}}.String())
+	require.Equal(t, "Repair vulture", Command{Type: CmdRepair, Target: vulture}.String())
+	require.Equal(t, "Build Marine at (3.0, 4.0)", Command{Type: CmdBuild, Build: Marine, Dest: Position{X: 3, Y: 4}}.String())
+	require.Equal(t, "Attack-move to (5.0, 6.0)", Command{Type: CmdAttackMove, Dest: Position{X: 5, Y: 6}}.String())
+	require.Equal(t, "Queue Move to (1.0, 1.0)", Command{Type: CmdMove, Dest: Position{X: 1, Y: 1}, Queue: true}.String())
+
+	vulture.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Orders_QueueRunsInOrder(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	events := marine.Subscribe(EventTypes(EventMoved, EventIdle))
+
+	waypoints := []Position{{X: 1}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: waypoints[0]}))
+	for _, wp := range waypoints[1:] {
+		require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: wp, Queue: true}))
+	}
+
+	require.Eventually(t, func() bool { return len(marine.PendingOrders()) == 2 }, time.Second, 5*time.Millisecond)
+	current, ok := marine.CurrentOrder()
+	require.True(t, ok)
+	require.Equal(t, waypoints[0], current.Dest)
+
+	// Every waypoint is reached (Remaining 0) in order, then one EventIdle
+	var reached []Position
+	for ev := range events.Events() {
+		if ev.Type == EventIdle {
+			break
+		}
+		if p := ev.Data.(MoveProgress); p.Remaining == 0 {
+			reached = append(reached, p.Position)
+		}
+	}
+	require.Equal(t, waypoints, reached)
+	require.Equal(t, Idle, marine.GetState())
+	require.Empty(t, marine.PendingOrders())
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Orders_QueueOnIdleUnitStartsNow(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+
+	result := make(chan error, 1)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{X: 50}, Queue: true, Result: result}))
+	require.NoError(t, <-result)
+	require.Equal(t, Moving, marine.GetState())
+	require.Empty(t, marine.PendingOrders())
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Orders_ReplaceAndClear(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{X: 50}}))
+	dropped := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{Y: 50}, Queue: true, Result: dropped}))
+	}
+	require.Eventually(t, func() bool { return len(marine.PendingOrders()) == 2 }, time.Second, 5*time.Millisecond)
+
+	// A plain order replaces the queue
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{X: -50}}))
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, <-dropped, ErrOrderCanceled)
+	}
+	require.Eventually(t, func() bool { return marine.GetDestination() == Position{X: -50} }, time.Second, 5*time.Millisecond)
+	require.Empty(t, marine.PendingOrders())
+
+	// ClearOrders empties the queue but keeps the current order going
+	require.NoError(t, marine.SendCommand(Command{Type: CmdHold, Queue: true}))
+	require.Eventually(t, func() bool { return len(marine.PendingOrders()) == 1 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, 1, marine.ClearOrders())
+	require.Empty(t, marine.PendingOrders())
+	require.Equal(t, Moving, marine.GetState())
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Orders_RefusedOrderKeepsQueue(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithClock(clock))
+
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 50}}))
+	queued := make(chan error, 2)
+	for _, dest := range []Position{{Y: 50}, {X: 50, Y: 50}} {
+		require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: dest, Queue: true, Result: queued}))
+	}
+	require.Eventually(t, func() bool { return len(marine.PendingOrders()) == 2 }, time.Second, time.Millisecond)
+
+	// Marines can't repair: the refusal leaves the move and its queue alone
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdRepair, Target: marine}), ErrCannotRepair)
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdAttack}), ErrNoTarget)
+	require.Equal(t, Moving, marine.GetState())
+	require.Equal(t, Position{X: 50}, marine.GetDestination())
+	require.Len(t, marine.PendingOrders(), 2)
+	require.Empty(t, queued, "nobody was told their order was canceled")
+
+	// An accepted one still replaces them
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdHold}))
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, <-queued, ErrOrderCanceled)
+	}
+	require.Empty(t, marine.PendingOrders())
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Orders_ShutdownAnswersQueue(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+
+	err := sendOrder(t, marine, Command{Type: CommandType(99)})
+	require.ErrorIs(t, err, ErrUnknownCommand)
+	var orderErr *OrderError
+	require.ErrorAs(t, err, &orderErr)
+	require.Equal(t, "marine", orderErr.UnitID)
+
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{X: 50}}))
+	dropped := make(chan error, 1)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdHold, Queue: true, Result: dropped}))
+	require.Eventually(t, func() bool { return len(marine.PendingOrders()) == 1 }, time.Second, 5*time.Millisecond)
+
+	marine.Shutdown()
+	wg.Wait()
+	err = <-dropped
+	require.ErrorIs(t, err, ErrShuttingDown)
+	var cmdErr *CommandError
+	require.ErrorAs(t, err, &cmdErr)
+	require.Equal(t, CmdHold, cmdErr.Command.Type)
+	require.Equal(t, "marine: Queue Hold Position: unit is shutting down", err.Error())
+}
+
+func TestUnit_Patrol(t *testing.T) {
+	var wg sync.WaitGroup
+	zergling := NewUnit("ling", Zergling, Position{}, &wg)
+
+	require.NoError(t, zergling.SendCommand(Command{Type: CmdPatrol, Dest: Position{X: 1}}))
+	require.Eventually(t, func() bool { return zergling.GetState() == Patrolling }, time.Second, 5*time.Millisecond)
+
+	// Out to the far end and back again, still patrolling
+	require.Eventually(t, func() bool { return zergling.GetDestination() == Position{} }, 2*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return zergling.GetDestination() == Position{X: 1} }, 2*time.Second, 5*time.Millisecond)
+	require.Equal(t, Patrolling, zergling.GetState())
+
+	zergling.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_AttackMove_FightsThenCarriesOn(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("fast-marine", strings.NewReader(`{"Marine": {"groundWeapon": {"cooldown": 0.01}}}`)))
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{X: 6}, &wg)
+	marine.SetTargetScanner(scannerFunc(func(u *Unit) *Unit {
+		if zergling.IsDead() {
+			return nil
+		}
+		return zergling
+	}))
+
+	dest := Position{X: 1, Y: 1}
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttackMove, Dest: dest}))
+
+	require.Eventually(t, zergling.IsDead, 3*time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return marine.GetState() == Idle && marine.GetPosition() == dest
+	}, 3*time.Second, 5*time.Millisecond, "attack-move resumes after the fight")
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Repair(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("quick-vulture", strings.NewReader(`{"Vulture": {"buildTime": 0.5}}`)))
+
+	var wg sync.WaitGroup
+	scv := NewUnit("scv", SCV, Position{}, &wg)
+	vulture := NewUnit("vulture", Vulture, Position{X: 3}, &wg)
+	marine := NewUnit("marine", Marine, Position{X: 1}, &wg)
+	vulture.TakeDamage(60)
+
+	result := make(chan error, 1)
+	require.NoError(t, scv.SendCommand(Command{Type: CmdRepair, Target: vulture, Result: result}))
+	require.NoError(t, <-result)
+	require.Eventually(t, func() bool { return scv.GetState() == Repairing }, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return vulture.GetHealth() == vulture.GetMaxHealth() && scv.GetState() == Idle
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Marines aren't mechanical; Marines can't repair
+	require.NoError(t, scv.SendCommand(Command{Type: CmdRepair, Target: marine, Result: result}))
+	require.ErrorIs(t, <-result, ErrCannotRepair)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdRepair, Target: vulture, Result: result}))
+	require.ErrorIs(t, <-result, ErrCannotRepair)
+
+	for _, u := range []*Unit{scv, vulture, marine} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestUnit_Build(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("quick-marine", strings.NewReader(`{"Marine": {"buildTime": 0.2}}`)))
+
+	var wg sync.WaitGroup
+	scv := NewUnit("scv", SCV, Position{}, &wg)
+	events := scv.Subscribe(EventTypes(EventBuilt))
+
+	site := Position{X: 1}
+	require.NoError(t, scv.SendCommand(Command{Type: CmdBuild, Build: Marine, Dest: site}))
+
+	ev := waitForEvent(t, events, EventBuilt)
+	built, ok := ev.Data.(*Unit)
+	require.True(t, ok)
+	require.Equal(t, built, ev.Target)
+	require.Equal(t, Marine, built.Type)
+	require.Equal(t, site, built.GetPosition())
+	require.Equal(t, "scv/Marine-1", built.ID)
+	require.Eventually(t, func() bool { return scv.GetState() == Idle }, time.Second, 5*time.Millisecond)
+
+	// Only workers build
+	result := make(chan error, 1)
+	require.NoError(t, built.SendCommand(Command{Type: CmdBuild, Build: Marine, Result: result}))
+	require.ErrorIs(t, <-result, ErrCannotBuild)
+
+	built.Shutdown()
+	scv.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Build_JoinsTheBuildersWorld(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("quick-marine", strings.NewReader(`{"Marine": {"buildTime": 0.2}}`)))
+	clock := NewManualClock(clockStart)
+	world := NewWorld(nil, 1)
+
+	// Nobody listens for EventBuilt: the world still learns of the Marine
+	var wg sync.WaitGroup
+	scv := NewUnit("scv", SCV, Position{}, &wg, WithClock(clock))
+	world.Add(scv)
+	require.NoError(t, sendOrder(t, scv, Command{Type: CmdBuild, Build: Marine, Dest: Position{X: 1}}))
+	advanceUntil(t, clock, func() bool { return len(world.Units()) == 2 })
+	built := world.Unit("scv/Marine-1")
+	require.NotNil(t, built)
+	require.Equal(t, world, built.GetWorld())
+
+	// The builder stops on its own; the Marine carries on
+	lifecycle := scv.Subscribe(nil)
+	scv.Shutdown()
+	require.Eventually(t, func() bool {
+		select {
+		case _, open := <-lifecycle.Events():
+			return !open
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond, "the SCV's goroutine exits")
+	require.NoError(t, sendOrder(t, built, Command{Type: CmdStop}))
+
+	// Shutting down what's in the world releases the shared WaitGroup
+	for _, u := range world.Units() {
+		u.Shutdown()
+	}
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("a built unit was left running")
+	}
+}
@@ -0,0 +1,541 @@
+package types
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// SNAPSHOTS - Save Game, Load Game
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Snapshot copies everything a unit needs to carry on—vitals, position,
+// stance, what it's shooting at, its orders and its owner's upgrades—into
+// a plain value that encodes to JSON. RestoreUnit turns one back into a
+// live unit with its own goroutine.
+//
+// Other units are referred to by ID (TargetID, order targets, the
+// transport a passenger rides in). RestoreUnit looks them up through a
+// UnitResolver; World.Restore brings back a whole battlefield at once, so
+// two units attacking each other can both be restored.
+//
+// Durations (weapon and spell cooldowns, the rest of a morph) are saved as
+// time remaining, so a game saved at minute 12 resumes cleanly on a clock
+// that starts at zero.
+//
+// What doesn't survive: spell effects on the unit, reload progress on a
+// magazine, and construction progress (a restored Build order starts the
+// building over).
+//
+// 🎴 MTG ANALOGY: Writing down the board state to finish a game on
+// Tuesday: every permanent, its counters, whether it's tapped, what it's
+// enchanting—by name, because the cards go back in the box.
+//
+// ⚔️ SC:BW ANALOGY: Save Game in a campaign mission. The SCVs come back
+// mid-mining-trip, the tanks come back sieged, and the Wraith you saved
+// while cloaked is still cloaked.
+//
+// 🎓 LEARNING: A snapshot is taken under one read lock, so it's consistent:
+// it never shows a health from before a hit alongside shields from after
+// it. It holds no pointers into the unit—slices and maps are copies—so
+// the caller can keep it, change it, or send it to another goroutine
+// freely.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Snapshot errors. Returned wrapped in *SnapshotError.
+var (
+	ErrBadSnapshot    = errors.New("bad snapshot")
+	ErrUnresolvedUnit = errors.New("unit in snapshot not found")
+	ErrSnapshotOwner  = errors.New("snapshot owner mismatch")
+)
+
+// SnapshotError explains why a snapshot couldn't be restored. A saved
+// order the unit refuses now wraps that order's error.
+type SnapshotError struct {
+	UnitID string
+	Reason string
+	Err    error
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("%s: %v: %s", e.UnitID, e.Err, e.Reason)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// UnitSnapshot is a unit's state at one moment, ready for encoding/json
+type UnitSnapshot struct {
+	ID        string         `json:"id"`
+	Type      UnitType       `json:"type"`
+	Owner     string         `json:"owner,omitempty"` // Faction ID; empty for neutral units
+	TakenAt   time.Time      `json:"takenAt"`         // On the unit's clock
+	Health    float64        `json:"health"`
+	Shields   float64        `json:"shields,omitempty"`
+	Energy    float64        `json:"energy,omitempty"`
+	Position  Position       `json:"position"`
+	State     UnitState      `json:"state"`
+	Elevation ElevationLayer `json:"elevation"`
+	Sieged    bool           `json:"sieged,omitempty"`
+	Cloaked   bool           `json:"cloaked,omitempty"`
+	TargetID  string         `json:"target,omitempty"`  // What it's attacking, repairing, loading...
+	CarrierID string         `json:"carrier,omitempty"` // The transport it rides in
+
+	Order  *OrderSnapshot  `json:"order,omitempty"`  // The order being carried out; nil when idle
+	Orders []OrderSnapshot `json:"orders,omitempty"` // Shift-queued behind it
+	Patrol *PatrolLeg      `json:"patrol,omitempty"` // The leg being walked, while Patrolling
+
+	Transition     *Transition                   `json:"transition,omitempty"` // Morph, siege or burrow in progress
+	TransitionLeft time.Duration                 `json:"transitionLeft,omitempty"`
+	Cooldown       time.Duration                 `json:"cooldown,omitempty"` // Until the weapons can fire again
+	CastCooldowns  map[AbilityType]time.Duration `json:"castCooldowns,omitempty"`
+	Ammo           *int                          `json:"ammo,omitempty"`     // Rounds loaded; nil = unlimited
+	Rolls          uint64                        `json:"rolls,omitempty"`    // Random rolls made so far; a restored unit picks up where it left off
+	Upgrades       map[UpgradeType]int           `json:"upgrades,omitempty"` // Owner's levels that apply to this unit
+}
+
+// OrderSnapshot is a Command with its target saved by ID
+type OrderSnapshot struct {
+	Type     CommandType `json:"type"`
+	TargetID string      `json:"target,omitempty"`
+	Dest     Position    `json:"dest"`
+	Build    UnitType    `json:"build,omitempty"`
+	Ability  AbilityType `json:"ability,omitempty"`
+}
+
+// PatrolLeg is the stretch a patrolling unit is walking, From → To
+type PatrolLeg struct {
+	From Position `json:"from"`
+	To   Position `json:"to"`
+}
+
+// UnitResolver finds a live unit by ID while restoring; nil if there's
+// none
+type UnitResolver func(id string) *Unit
+
+func snapshotOrder(cmd Command) OrderSnapshot {
+	o := OrderSnapshot{Type: cmd.Type, Dest: cmd.Dest, Build: cmd.Build, Ability: cmd.Ability}
+	if cmd.Target != nil {
+		o.TargetID = cmd.Target.ID
+	}
+	return o
+}
+
+// hasOrder reports whether a unit in state us is carrying out an order
+func (us UnitState) hasOrder() bool {
+	return us != Idle && us != Loaded && us != Dead
+}
+
+// Snapshot returns a copy of the unit's state, consistent as of one
+// instant, that RestoreUnit can bring back to life
+func (u *Unit) Snapshot() UnitSnapshot {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	now := u.clock.Now()
+
+	s := UnitSnapshot{
+		ID:        u.ID,
+		Type:      u.Type,
+		TakenAt:   now,
+		Health:    u.health,
+		Shields:   u.shields,
+		Energy:    u.energy,
+		Position:  u.position,
+		State:     u.state,
+		Elevation: u.elevationLayer,
+		Sieged:    u.sieged,
+		Cloaked:   u.cloaked,
+	}
+	if u.owner != nil {
+		s.Owner = u.owner.ID
+	}
+	if u.target != nil {
+		s.TargetID = u.target.ID
+	}
+	if u.carrier != nil {
+		s.CarrierID = u.carrier.ID
+	}
+
+	if u.state.hasOrder() {
+		o := snapshotOrder(u.order)
+		s.Order = &o
+	}
+	for _, cmd := range u.orders {
+		s.Orders = append(s.Orders, snapshotOrder(cmd))
+	}
+	if u.state == Patrolling {
+		s.Patrol = &PatrolLeg{From: u.patrolFrom, To: u.patrolTo}
+	}
+	if u.state == Morphing || u.state == Transforming {
+		t := u.transition
+		s.Transition = &t
+		s.TransitionLeft = max(u.transitionEnd.Sub(now), 0)
+	}
+
+	if u.cooldownUntil.After(now) {
+		s.Cooldown = u.cooldownUntil.Sub(now)
+	}
+	s.Rolls = u.rolls
+	for at, ready := range u.castReady {
+		if ready.After(now) {
+			if s.CastCooldowns == nil {
+				s.CastCooldowns = make(map[AbilityType]time.Duration)
+			}
+			s.CastCooldowns[at] = ready.Sub(now)
+		}
+	}
+	if u.ammo != nil {
+		rounds := u.ammo.rounds
+		s.Ammo = &rounds
+	}
+	for stat := UpgradeWeapons; stat < upgradeStatCount; stat++ {
+		t, ok := UpgradeFor(u.Type, stat)
+		if !ok {
+			continue
+		}
+		if level := u.owner.UpgradeLevel(t); level > 0 {
+			if s.Upgrades == nil {
+				s.Upgrades = make(map[UpgradeType]int)
+			}
+			s.Upgrades[t] = level
+		}
+	}
+	return s
+}
+
+// validate catches snapshots that can't describe a living unit
+func (s UnitSnapshot) validate() error {
+	bad := func(format string, args ...any) error {
+		return &SnapshotError{UnitID: s.ID, Reason: fmt.Sprintf(format, args...), Err: ErrBadSnapshot}
+	}
+	switch {
+	case s.ID == "":
+		return bad("no ID")
+	case !s.Type.IsValid():
+		return bad("unknown unit type %d", s.Type)
+	case !s.State.IsValid():
+		return bad("unknown state %d", s.State)
+	case s.State == Dead || s.Health <= 0:
+		return bad("dead units aren't restored")
+	case !s.Elevation.IsValid():
+		return bad("unknown elevation %d", s.Elevation)
+	case (s.Transition != nil) != (s.State == Morphing || s.State == Transforming):
+		return bad("state %s with transition %v", s.State, s.Transition)
+	case s.State.hasOrder() && s.Order == nil:
+		return bad("state %s without an order", s.State)
+	case (s.CarrierID != "") != (s.State == Loaded):
+		return bad("state %s with carrier %q", s.State, s.CarrierID)
+	}
+	return nil
+}
+
+// RestoreUnit brings a snapshot back as a live unit and starts its
+// goroutine. opts work as for NewUnit; a snapshot with an Owner must be
+// given that faction with WithOwner. resolve finds the units the snapshot
+// refers to and may be nil if it refers to none.
+//
+// The owner's upgrades are raised to the snapshot's levels if they're
+// behind. The current order is re-issued as though the player clicked it
+// again, so it's checked like any other order; the shift-queue goes back
+// behind it untouched.
+//
+// Errors are *SnapshotError wrapping ErrBadSnapshot, ErrSnapshotOwner,
+// ErrUnresolvedUnit, or the error the re-issued order was refused with.
+// Nothing is left running on error.
+func RestoreUnit(s UnitSnapshot, wg *sync.WaitGroup, resolve UnitResolver, opts ...UnitOption) (*Unit, error) {
+	u, err := restoreBody(s, wg, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.restoreOrders(s, resolve); err != nil {
+		u.Shutdown()
+		return nil, err
+	}
+	return u, nil
+}
+
+// restoreBody creates the unit and puts back everything that doesn't refer
+// to another unit
+func restoreBody(s UnitSnapshot, wg *sync.WaitGroup, opts []UnitOption) (*Unit, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	u := NewUnit(s.ID, s.Type, s.Position, wg, opts...)
+	if err := u.restoreOwner(s); err != nil {
+		u.Shutdown()
+		return nil, err
+	}
+
+	now := u.clock.Now()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.health = math.Min(s.Health, float64(u.maxHealth))
+	u.shields = math.Min(s.Shields, float64(u.maxShields))
+	u.energy = math.Min(s.Energy, float64(u.maxEnergy))
+	u.elevationLayer = s.Elevation
+	if s.Sieged && u.siegeWeapon != nil {
+		u.tankWeapon, u.groundWeapon = u.groundWeapon, u.siegeWeapon
+		u.sieged = true
+	}
+	u.cloaked = s.Cloaked
+	u.cooldownUntil = now.Add(s.Cooldown)
+	u.rolls = s.Rolls
+	if len(s.CastCooldowns) > 0 && u.castReady == nil {
+		u.castReady = make(map[AbilityType]time.Time) // applyAbilityStats starts it nil
+	}
+	for at, left := range s.CastCooldowns {
+		u.castReady[at] = now.Add(left)
+	}
+	if s.Ammo != nil && u.ammo != nil {
+		u.ammo.rounds = min(max(*s.Ammo, 0), u.ammo.capacity)
+	}
+	if s.Transition != nil {
+		u.state = s.State
+		u.transition = *s.Transition
+		u.transitionEnd = now.Add(s.TransitionLeft)
+	}
+	return u, nil
+}
+
+// restoreOwner checks the unit went to the faction its snapshot names and
+// brings that faction's upgrades up to the snapshot's levels
+func (u *Unit) restoreOwner(s UnitSnapshot) error {
+	owner := ""
+	if u.owner != nil {
+		owner = u.owner.ID
+	}
+	if owner != s.Owner {
+		return &SnapshotError{UnitID: s.ID, Reason: fmt.Sprintf("saved under %q, restored under %q", s.Owner, owner), Err: ErrSnapshotOwner}
+	}
+	for t, level := range s.Upgrades {
+		if u.owner.UpgradeLevel(t) >= level {
+			continue
+		}
+		if u.owner == nil {
+			return &SnapshotError{UnitID: s.ID, Reason: "neutral units have no upgrades", Err: ErrBadSnapshot}
+		}
+		if err := u.owner.SetUpgradeLevel(t, level, u.clock.Now()); err != nil {
+			return &SnapshotError{UnitID: s.ID, Reason: fmt.Sprintf("upgrade %s %d", t, level), Err: err}
+		}
+	}
+	return nil
+}
+
+// restoreOrders puts back everything that refers to other units: the
+// transport, the orders and the target
+func (u *Unit) restoreOrders(s UnitSnapshot, resolve UnitResolver) error {
+	lookup := func(id string) (*Unit, error) {
+		if id == "" {
+			return nil, nil
+		}
+		if resolve != nil {
+			if found := resolve(id); found != nil {
+				return found, nil
+			}
+		}
+		return nil, &SnapshotError{UnitID: s.ID, Reason: fmt.Sprintf("no unit %q", id), Err: ErrUnresolvedUnit}
+	}
+	command := func(o OrderSnapshot) (Command, error) {
+		target, err := lookup(o.TargetID)
+		return Command{Type: o.Type, Target: target, Dest: o.Dest, Build: o.Build, Ability: o.Ability}, err
+	}
+
+	if s.CarrierID != "" {
+		t, err := lookup(s.CarrierID)
+		if err != nil {
+			return err
+		}
+		if err := t.addCargo(u); err != nil {
+			return &SnapshotError{UnitID: s.ID, Reason: "boarding " + t.ID, Err: err}
+		}
+		u.mu.Lock()
+		u.carrier = t
+		u.state = Loaded
+		u.mu.Unlock()
+		return nil
+	}
+
+	queued := make([]Command, 0, len(s.Orders))
+	for _, o := range s.Orders {
+		cmd, err := command(o)
+		if err != nil {
+			return err
+		}
+		cmd.Queue = true
+		queued = append(queued, cmd)
+	}
+	target, err := lookup(s.TargetID)
+	if err != nil {
+		return err
+	}
+	if s.Order == nil {
+		return nil
+	}
+	current, err := command(*s.Order)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	u.orders = queued
+	if s.Transition != nil {
+		u.order = current
+		u.mu.Unlock()
+		return nil
+	}
+	u.mu.Unlock()
+
+	// Queue is set so the idle unit starts it without dropping the
+	// shift-queue restored above
+	reason := "restoring " + current.String()
+	result := make(chan error, 1)
+	current.Result, current.Queue = result, true
+	if err := u.SendCommand(current); err != nil {
+		return &SnapshotError{UnitID: s.ID, Reason: reason, Err: err}
+	}
+	select {
+	case err = <-result:
+	case <-u.ctx.Done():
+		err = ErrShuttingDown
+	}
+	if err != nil {
+		return &SnapshotError{UnitID: s.ID, Reason: reason, Err: err}
+	}
+
+	// Pick up where the order had got to: the patrol leg, or the enemy an
+	// attack-move or patrol had stopped to fight
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if s.Patrol != nil && u.state == Patrolling {
+		u.patrolFrom, u.patrolTo = s.Patrol.From, s.Patrol.To
+		u.destination = s.Patrol.To
+	}
+	if s.State == Attacking && current.Type != CmdAttack && target != nil {
+		u.state = Attacking
+		u.target = target
+	}
+	return nil
+}
+
+// Restore brings a saved battlefield back into w: every snapshot becomes a
+// live unit owned by the faction it names (looked up in factions) and is
+// added to w, then orders and targets are restored, so units may refer to
+// any other unit in snaps or already in w. On error every unit restored so
+// far is removed and shut down.
+func (w *World) Restore(snaps []UnitSnapshot, factions []*Faction, wg *sync.WaitGroup, opts ...UnitOption) ([]*Unit, error) {
+	byID := make(map[string]*Faction, len(factions))
+	for _, f := range factions {
+		byID[f.ID] = f
+	}
+	var units []*Unit
+	abandon := func(err error) ([]*Unit, error) {
+		for _, u := range units {
+			w.Remove(u)
+			u.Shutdown()
+		}
+		return nil, err
+	}
+
+	for _, s := range snaps {
+		unitOpts := opts
+		if s.Owner != "" {
+			f, ok := byID[s.Owner]
+			if !ok {
+				return abandon(&SnapshotError{UnitID: s.ID, Reason: fmt.Sprintf("no faction %q", s.Owner), Err: ErrSnapshotOwner})
+			}
+			unitOpts = append(append([]UnitOption(nil), opts...), WithOwner(f))
+		}
+		u, err := restoreBody(s, wg, unitOpts)
+		if err != nil {
+			return abandon(err)
+		}
+		units = append(units, u)
+		w.Add(u)
+	}
+	for i, u := range units {
+		if err := u.restoreOrders(snaps[i], w.Unit); err != nil {
+			return abandon(err)
+		}
+	}
+	return units, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// JSON: enums travel by name, so saves survive reordered constants
+// ─────────────────────────────────────────────────────────────────────────────
+
+var (
+	_ encoding.TextMarshaler   = UnitType(0)
+	_ encoding.TextUnmarshaler = (*UnitType)(nil)
+)
+
+func enumText[E comparable](e E, names map[E]string) ([]byte, error) {
+	if name, ok := names[e]; ok {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("%T(%v) has no name", e, e)
+}
+
+func parseEnumText[E comparable](text []byte, names map[E]string, e *E) error {
+	for v, name := range names {
+		if name == string(text) {
+			*e = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %T %q", *e, text)
+}
+
+func (ut UnitType) MarshalText() ([]byte, error) {
+	return enumText(ut, unitTypeNames)
+}
+
+func (ut *UnitType) UnmarshalText(text []byte) error {
+	return parseEnumText(text, unitTypeNames, ut)
+}
+
+func (us UnitState) MarshalText() ([]byte, error) {
+	return enumText(us, unitStateNames)
+}
+
+func (us *UnitState) UnmarshalText(text []byte) error {
+	return parseEnumText(text, unitStateNames, us)
+}
+
+func (el ElevationLayer) MarshalText() ([]byte, error) {
+	return enumText(el, elevationLayerNames)
+}
+
+func (el *ElevationLayer) UnmarshalText(text []byte) error {
+	return parseEnumText(text, elevationLayerNames, el)
+}
+
+func (ct CommandType) MarshalText() ([]byte, error) {
+	return enumText(ct, commandTypeNames)
+}
+
+func (ct *CommandType) UnmarshalText(text []byte) error {
+	return parseEnumText(text, commandTypeNames, ct)
+}
+
+func (at AbilityType) MarshalText() ([]byte, error) {
+	return enumText(at, abilityTypeNames)
+}
+
+func (at *AbilityType) UnmarshalText(text []byte) error {
+	return parseEnumText(text, abilityTypeNames, at)
+}
+
+func (t UpgradeType) MarshalText() ([]byte, error) {
+	return enumText(t, upgradeTypeNames)
+}
+
+func (t *UpgradeType) UnmarshalText(text []byte) error {
+	return parseEnumText(text, upgradeTypeNames, t)
+}
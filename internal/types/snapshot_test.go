@@ -0,0 +1,197 @@
+package types
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_SaveAndResume(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+	require.NoError(t, red.SetUpgradeLevel(InfantryWeapons, 1, clock.Now()))
+
+	var wg sync.WaitGroup
+	terran := []UnitOption{WithClock(clock), WithOwner(red)}
+	zerg := []UnitOption{WithClock(clock), WithOwner(teal)}
+	marine := NewUnit("marine", Marine, Position{}, &wg, terran...)
+	tank := NewUnit("tank", SiegeTank, Position{Y: 10}, &wg, terran...)
+	drop := NewUnit("drop", DropShip, Position{X: -5}, &wg, terran...)
+	medic := NewUnit("medic", Medic, Position{X: -5}, &wg, terran...)
+	ling := NewUnit("ling", Zergling, Position{X: 6}, &wg, zerg...)
+	hydra := NewUnit("hydra", Hydralisk, Position{X: 20}, &wg, zerg...)
+	before := []*Unit{drop, hydra, ling, marine, medic, tank} // World.Units order
+	for _, u := range before {
+		world.Add(u)
+	}
+
+	require.NoError(t, sendOrder(t, tank, Command{Type: CmdSiege}))
+	require.NoError(t, sendOrder(t, drop, Command{Type: CmdLoad, Target: medic}))
+	advanceUntil(t, clock, func() bool { return tank.IsSieged() && medic.IsLoaded() && drop.GetState() == Idle })
+	require.NoError(t, sendOrder(t, hydra, Command{Type: CmdMorph, Build: Lurker}))
+	clock.Advance(10 * time.Second)
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdAttack, Target: ling}))
+	require.NoError(t, marine.SendCommand(Command{Type: CmdMove, Dest: Position{X: 5, Y: 5}, Queue: true}))
+	require.Eventually(t, func() bool { return len(marine.PendingOrders()) == 1 }, time.Second, time.Millisecond)
+	marine.TakeDamage(15)
+
+	var saved []UnitSnapshot
+	for _, u := range world.Units() {
+		saved = append(saved, u.Snapshot())
+	}
+	s := saved[3]
+	require.Equal(t, "marine", s.ID)
+	require.Equal(t, Attacking, s.State)
+	require.Equal(t, "ling", s.TargetID)
+	require.Equal(t, &OrderSnapshot{Type: CmdAttack, TargetID: "ling"}, s.Order)
+	require.Equal(t, []OrderSnapshot{{Type: CmdMove, Dest: Position{X: 5, Y: 5}}}, s.Orders)
+	require.Equal(t, map[UpgradeType]int{InfantryWeapons: 1}, s.Upgrades)
+	require.Equal(t, "drop", saved[4].CarrierID)
+	require.InDelta(t, 15*time.Second, saved[1].TransitionLeft, float64(TickRate))
+
+	// Snapshots are copies: the unit carrying on doesn't change them
+	clock.Advance(TickRate)
+	require.Equal(t, Attacking, s.State)
+
+	data, err := json.Marshal(saved)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"type":"Marine","owner":"p1"`)
+	require.Contains(t, string(data), `"state":"Attacking"`)
+	require.Contains(t, string(data), `"upgrades":{"InfantryWeapons":1}`)
+	var loaded []UnitSnapshot
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	require.Equal(t, saved, loaded)
+
+	for _, u := range before {
+		u.Shutdown()
+	}
+	wg.Wait()
+
+	// Resume on a fresh clock, with factions that have researched nothing
+	world = NewWorld(nil, 1)
+	clock = NewManualClock(clockStart.Add(time.Hour))
+	red = NewFaction("p1", "Flash", Terran, Red)
+	teal = NewFaction("p3", "Jaedong", Zerg, Teal)
+	after, err := world.Restore(loaded, []*Faction{red, teal}, &wg, WithClock(clock))
+	require.NoError(t, err)
+	require.Len(t, after, 6)
+	drop, hydra, ling, marine, medic, tank = after[0], after[1], after[2], after[3], after[4], after[5]
+
+	require.Equal(t, 1, red.UpgradeLevel(InfantryWeapons))
+	require.Equal(t, 7, marine.GetDamage())
+	require.Equal(t, 25, marine.GetHealth())
+	require.Equal(t, Attacking, marine.GetState())
+	order, ok := marine.CurrentOrder()
+	require.True(t, ok)
+	require.Same(t, ling, order.Target)
+	require.Len(t, marine.PendingOrders(), 1)
+	require.True(t, tank.IsSieged())
+	require.Equal(t, 70, tank.GetDamage())
+	require.Same(t, drop, medic.GetTransport())
+	require.Equal(t, []*Unit{medic}, drop.GetCargo())
+	require.Equal(t, Morphing, hydra.GetState())
+
+	clock.Advance(15*time.Second - TickRate)
+	require.Equal(t, Hydralisk, hydra.GetType())
+	advanceUntil(t, clock, func() bool { return hydra.GetType() == Lurker })
+
+	for _, u := range after {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSnapshot_CastCooldownsRoundTrip(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	vessel := NewUnit("vessel", ScienceVessel, Position{}, &wg, WithClock(clock), WithOwner(red))
+	ling := NewUnit("ling", Zergling, Position{X: 3}, &wg, WithClock(clock), WithOwner(teal))
+	world.Add(vessel)
+	world.Add(ling)
+	setEnergy(vessel, 200)
+	require.NoError(t, sendOrder(t, vessel, Command{Type: CmdCast, Ability: Irradiate, Target: ling}))
+	advanceUntil(t, clock, func() bool { return vessel.GetState() == Idle && vessel.CooldownRemaining(Irradiate) > 0 })
+
+	s := vessel.Snapshot()
+	left := s.CastCooldowns[Irradiate]
+	require.Positive(t, left)
+	saved := []UnitSnapshot{ling.Snapshot(), s}
+	vessel.Shutdown()
+	ling.Shutdown()
+	wg.Wait()
+
+	// On its own...
+	clock = NewManualClock(clockStart.Add(time.Hour))
+	red = NewFaction("p1", "Flash", Terran, Red)
+	teal = NewFaction("p3", "Jaedong", Zerg, Teal)
+	restored, err := RestoreUnit(s, &wg, nil, WithClock(clock), WithOwner(red))
+	require.NoError(t, err)
+	require.Equal(t, left, restored.CooldownRemaining(Irradiate))
+	require.Zero(t, restored.CooldownRemaining(EMPShockwave))
+	restored.Shutdown()
+
+	// ...and with the rest of the battle, through JSON
+	data, err := json.Marshal(saved)
+	require.NoError(t, err)
+	var loaded []UnitSnapshot
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	after, err := NewWorld(nil, 1).Restore(loaded, []*Faction{red, teal}, &wg, WithClock(clock))
+	require.NoError(t, err)
+	require.Equal(t, left, after[1].CooldownRemaining(Irradiate))
+	err = sendOrder(t, after[1], Command{Type: CmdCast, Ability: Irradiate, Target: after[0]})
+	require.ErrorIs(t, err, ErrOnCooldown)
+
+	for _, u := range after {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestRestoreUnit_Refusals(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	var wg sync.WaitGroup
+
+	alive := UnitSnapshot{ID: "marine", Type: Marine, Owner: "p1", Health: 40, State: Idle, Elevation: Ground}
+	dead := alive
+	dead.State, dead.Health = Dead, 0
+	_, err := RestoreUnit(dead, &wg, nil, WithClock(clock), WithOwner(red))
+	require.ErrorIs(t, err, ErrBadSnapshot)
+	require.ErrorContains(t, err, "dead units aren't restored")
+
+	_, err = RestoreUnit(alive, &wg, nil, WithClock(clock))
+	require.ErrorIs(t, err, ErrSnapshotOwner)
+	require.ErrorContains(t, err, `saved under "p1", restored under ""`)
+
+	attacking := alive
+	attacking.State, attacking.TargetID = Attacking, "ling"
+	attacking.Order = &OrderSnapshot{Type: CmdAttack, TargetID: "ling"}
+	_, err = RestoreUnit(attacking, &wg, nil, WithClock(clock), WithOwner(red))
+	require.ErrorIs(t, err, ErrUnresolvedUnit)
+
+	// The target is there but already dead: the re-issued order is refused
+	ling := NewUnit("ling", Zergling, Position{X: 3}, &wg, WithClock(clock))
+	ling.TakeDamage(100)
+	_, err = RestoreUnit(attacking, &wg, func(string) *Unit { return ling }, WithClock(clock), WithOwner(red))
+	require.ErrorIs(t, err, ErrUnitDead)
+	require.ErrorContains(t, err, "restoring Attack ling")
+
+	var s UnitSnapshot
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"type":"Overmind"}`), &s), `unknown types.UnitType "Overmind"`)
+
+	u, err := RestoreUnit(alive, &wg, nil, WithClock(clock), WithOwner(red))
+	require.NoError(t, err)
+	require.Equal(t, Idle, u.GetState())
+	u.Shutdown()
+	ling.Shutdown()
+	wg.Wait()
+}
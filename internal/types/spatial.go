@@ -0,0 +1,447 @@
+package types
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// SPATIAL INDEX - "Who's Near Here?" Without Asking Everyone
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Finding the units around a point by checking every unit is fine for a
+// skirmish and hopeless for 10,000 Zerglings. The SpatialIndex drops units
+// into a uniform grid of square cells, so a query only looks at the cells
+// its area touches:
+//
+//   - Within / InRadius / InRect: every unit inside an Area
+//   - Nearest: the k closest units, searching outward ring by ring
+//
+// Each query can be narrowed to some elevation layers and some factions
+// (see SpatialFilter). Dead units and passengers are never returned.
+//
+// The index keeps itself current by subscribing to each tracked unit:
+// EventMoved moves it to its new cell, EventDied drops it, and a
+// transport's EventUnloaded puts its passengers back where they landed.
+//
+// 🎴 MTG ANALOGY: Sorting the battlefield into piles. "Each creature
+// blocking or blocked by this" doesn't need you to read every permanent—
+// just the ones in the combat pile.
+//
+// ⚔️ SC:BW ANALOGY: The engine never asks all 1,600 units whether a Siege
+// Tank shell hits them. Units live in map regions, and a splash check only
+// looks at the regions under the blast.
+//
+// 🎓 LEARNING: EventMoved comes once per MoveEventInterval, not every tick,
+// so the grid is always slightly behind. The index knows how far behind it
+// can be—the fastest tracked unit's speed times the interval—and widens
+// every search by that much, then checks each candidate's live position.
+// Stale cells cost a few extra candidates, not wrong answers—so long as
+// the index keeps up with the events.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// DefaultSpatialCellSize is the grid cell size NewSpatialIndex uses when
+// given zero: 8 tiles, about a Siege Tank's sieged range across.
+const DefaultSpatialCellSize = 8.0
+
+// spatialBuffer is how many events a tracked unit can get ahead of the
+// index. Only the latest position matters, so older moves can be dropped.
+const spatialBuffer = 8
+
+// SpatialFilter narrows a spatial query. The zero value matches everyone.
+type SpatialFilter struct {
+	Layers   []ElevationLayer // Empty = every layer
+	Factions []*Faction       // Empty = every owner, neutral included
+}
+
+// wantsOwner reports whether f accepts units owned by owner
+func (f SpatialFilter) wantsOwner(owner *Faction) bool {
+	if len(f.Factions) == 0 {
+		return true
+	}
+	for _, faction := range f.Factions {
+		if faction == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsLayer reports whether f accepts units on layer
+func (f SpatialFilter) wantsLayer(layer ElevationLayer) bool {
+	if len(f.Layers) == 0 {
+		return true
+	}
+	for _, l := range f.Layers {
+		if l == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// locate returns u's live position if u is on the map and passes f. The
+// layer is read live: burrowing changes it without moving the unit.
+func (f SpatialFilter) locate(u *Unit) (Position, bool) {
+	if !f.wantsOwner(u.GetOwner()) {
+		return Position{}, false
+	}
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.state == Dead || u.carrier != nil || !f.wantsLayer(u.elevationLayer) {
+		return Position{}, false
+	}
+	return u.position, true
+}
+
+// cellKey is a grid cell's column and row
+type cellKey struct {
+	X, Y int
+}
+
+// spatialEntry is one tracked unit: where the index last saw it
+type spatialEntry struct {
+	unit *Unit
+	pos  Position
+	cell cellKey
+	sub  *Subscription
+}
+
+// SpatialIndex is a uniform grid of tracked units. Safe for concurrent use:
+// queries share a read lock, and each unit's updates arrive on its own
+// goroutine.
+type SpatialIndex struct {
+	cellSize float64
+
+	mu      sync.RWMutex
+	cells   map[cellKey]map[*Unit]*spatialEntry // Empty cells are deleted
+	entries map[*Unit]*spatialEntry
+	drift   float64 // Furthest a tracked unit can move between EventMoved reports
+	closed  bool
+
+	wg sync.WaitGroup // One per follow goroutine
+}
+
+// NewSpatialIndex creates an empty index with cells cellSize tiles across
+// (DefaultSpatialCellSize if cellSize <= 0). Pick roughly the radius of a
+// typical query: much smaller and queries visit many cells, much larger and
+// each cell holds many units.
+func NewSpatialIndex(cellSize float64) *SpatialIndex {
+	if cellSize <= 0 {
+		cellSize = DefaultSpatialCellSize
+	}
+	return &SpatialIndex{
+		cellSize: cellSize,
+		cells:    make(map[cellKey]map[*Unit]*spatialEntry),
+		entries:  make(map[*Unit]*spatialEntry),
+	}
+}
+
+// Track adds u at its current position and follows its events until it
+// dies, stops, or is untracked. Tracking a unit twice, or after Close,
+// does nothing.
+func (ix *SpatialIndex) Track(u *Unit) {
+	// Subscribe before reading the position, so no move falls in between
+	sub := u.Subscribe(EventTypes(EventMoved, EventUnloaded, EventDied, EventTransformed), WithBuffer(spatialBuffer))
+	pos := u.GetPosition()
+	reach := u.GetSpeed() * MoveEventInterval.Seconds()
+
+	ix.mu.Lock()
+	if ix.closed || ix.entries[u] != nil {
+		ix.mu.Unlock()
+		sub.Unsubscribe()
+		return
+	}
+	e := &spatialEntry{unit: u, sub: sub}
+	ix.entries[u] = e
+	ix.place(e, pos)
+	ix.drift = max(ix.drift, reach)
+	ix.wg.Add(1)
+	ix.mu.Unlock()
+
+	go ix.follow(e)
+}
+
+// Untrack removes u from the index. Untracking a unit that isn't tracked
+// does nothing.
+func (ix *SpatialIndex) Untrack(u *Unit) {
+	ix.mu.Lock()
+	e := ix.entries[u]
+	if e != nil {
+		ix.remove(e)
+	}
+	ix.mu.Unlock()
+
+	if e != nil {
+		e.sub.Unsubscribe()
+	}
+}
+
+// Refresh re-reads u's live position. Only needed after moving a unit
+// without an event, such as with SetPosition.
+func (ix *SpatialIndex) Refresh(u *Unit) {
+	pos := u.GetPosition()
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if e := ix.entries[u]; e != nil {
+		ix.place(e, pos)
+	}
+}
+
+// Len returns how many units are tracked
+func (ix *SpatialIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries)
+}
+
+// Close untracks every unit and waits for the index's goroutines to exit.
+// Queries on a closed index find nothing.
+func (ix *SpatialIndex) Close() {
+	ix.mu.Lock()
+	ix.closed = true
+	entries := ix.entries
+	ix.entries = make(map[*Unit]*spatialEntry)
+	ix.cells = make(map[cellKey]map[*Unit]*spatialEntry)
+	ix.mu.Unlock()
+
+	for _, e := range entries {
+		e.sub.Unsubscribe()
+	}
+	ix.wg.Wait()
+}
+
+// follow applies one unit's events until its subscription closes
+func (ix *SpatialIndex) follow(e *spatialEntry) {
+	defer ix.wg.Done()
+	for ev := range e.sub.Events() {
+		switch ev.Type {
+		case EventMoved:
+			if progress, ok := ev.Data.(MoveProgress); ok {
+				ix.move(e, progress.Position)
+			}
+		case EventUnloaded:
+			ix.Refresh(ev.Target)
+		case EventTransformed:
+			// A morph can change the unit's speed (Hydralisk → Lurker)
+			reach := e.unit.GetSpeed() * MoveEventInterval.Seconds()
+			ix.mu.Lock()
+			ix.drift = max(ix.drift, reach)
+			ix.mu.Unlock()
+		case EventDied:
+			e.sub.Unsubscribe()
+		}
+	}
+
+	ix.mu.Lock()
+	if ix.entries[e.unit] == e {
+		ix.remove(e)
+	}
+	ix.mu.Unlock()
+}
+
+// move puts e at pos, unless e has been untracked meanwhile
+func (ix *SpatialIndex) move(e *spatialEntry, pos Position) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.entries[e.unit] == e {
+		ix.place(e, pos)
+	}
+}
+
+// cellOf returns the cell holding p
+func (ix *SpatialIndex) cellOf(p Position) cellKey {
+	return cellKey{X: int(math.Floor(p.X / ix.cellSize)), Y: int(math.Floor(p.Y / ix.cellSize))}
+}
+
+// place records e at pos, moving it between cells if need be. Caller holds
+// ix.mu for writing.
+func (ix *SpatialIndex) place(e *spatialEntry, pos Position) {
+	key := ix.cellOf(pos)
+	cell := ix.cells[key]
+	if cell[e.unit] == e {
+		e.pos = pos
+		return
+	}
+	if cell == nil {
+		cell = make(map[*Unit]*spatialEntry)
+		ix.cells[key] = cell
+	}
+	ix.unlink(e)
+	cell[e.unit] = e
+	e.pos, e.cell = pos, key
+}
+
+// remove forgets e. Caller holds ix.mu for writing.
+func (ix *SpatialIndex) remove(e *spatialEntry) {
+	ix.unlink(e)
+	delete(ix.entries, e.unit)
+}
+
+// unlink takes e out of its cell. Caller holds ix.mu for writing.
+func (ix *SpatialIndex) unlink(e *spatialEntry) {
+	cell := ix.cells[e.cell]
+	if cell[e.unit] != e {
+		return
+	}
+	delete(cell, e.unit)
+	if len(cell) == 0 {
+		delete(ix.cells, e.cell)
+	}
+}
+
+// candidates lists the units the index last saw inside r widened by the
+// drift. Whichever is cheaper: visit the cells r covers, or every
+// occupied cell.
+func (ix *SpatialIndex) candidates(r Rectangle) []*Unit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	r = r.Expand(ix.drift)
+	lo, hi := ix.cellOf(r.TopLeft), ix.cellOf(r.BottomRight)
+
+	var found []*Unit
+	collect := func(cell map[*Unit]*spatialEntry) {
+		for u, e := range cell {
+			if r.Contains(e.pos) {
+				found = append(found, u)
+			}
+		}
+	}
+	span := float64(hi.X-lo.X+1) * float64(hi.Y-lo.Y+1)
+	if span > float64(len(ix.cells)) {
+		for key, cell := range ix.cells {
+			if key.X >= lo.X && key.X <= hi.X && key.Y >= lo.Y && key.Y <= hi.Y {
+				collect(cell)
+			}
+		}
+		return found
+	}
+	for x := lo.X; x <= hi.X; x++ {
+		for y := lo.Y; y <= hi.Y; y++ {
+			collect(ix.cells[cellKey{X: x, Y: y}])
+		}
+	}
+	return found
+}
+
+// Within returns the tracked units inside a that pass f, ordered by ID
+func (ix *SpatialIndex) Within(a Area, f SpatialFilter) []*Unit {
+	var in []*Unit
+	for _, u := range ix.candidates(a.Bounds()) {
+		if pos, ok := f.locate(u); ok && a.Contains(pos) {
+			in = append(in, u)
+		}
+	}
+	sort.Slice(in, func(i, j int) bool { return in[i].ID < in[j].ID })
+	return in
+}
+
+// InRadius returns the tracked units whose center is within radius of
+// center and that pass f, ordered by ID
+func (ix *SpatialIndex) InRadius(center Position, radius float64, f SpatialFilter) []*Unit {
+	return ix.Within(Circle{Center: center, Radius: radius}, f)
+}
+
+// InRect returns the tracked units inside r that pass f, ordered by ID
+func (ix *SpatialIndex) InRect(r Rectangle, f SpatialFilter) []*Unit {
+	return ix.Within(r, f)
+}
+
+// Nearest returns up to k tracked units that pass f, closest to center
+// first (ties by ID)
+//
+// 💡 It searches the cell under center, then the ring of cells around it,
+// and so on. After ring n every unit closer than n cells (less the drift)
+// has been seen, so once k of those are in hand nothing further out can
+// beat them. If the rings grow larger than the occupied part of the grid,
+// it checks every occupied cell instead.
+func (ix *SpatialIndex) Nearest(center Position, k int, f SpatialFilter) []*Unit {
+	if k <= 0 {
+		return nil
+	}
+	type hit struct {
+		unit *Unit
+		dist float64
+	}
+	var hits []hit
+	seen := make(map[*Unit]bool)
+	origin := ix.cellOf(center)
+
+	for ring := 0; ; ring++ {
+		ix.mu.RLock()
+		side := float64(2*ring + 1)
+		everything := side*side > float64(len(ix.cells))
+		var batch []*Unit
+		for _, cell := range ix.ringCells(origin, ring, everything) {
+			for u := range cell {
+				batch = append(batch, u)
+			}
+		}
+		reach := float64(ring)*ix.cellSize - ix.drift
+		ix.mu.RUnlock()
+
+		for _, u := range batch {
+			if seen[u] {
+				continue
+			}
+			seen[u] = true
+			if pos, ok := f.locate(u); ok {
+				hits = append(hits, hit{unit: u, dist: pos.Distance(center)})
+			}
+		}
+		if everything {
+			break
+		}
+		settled := 0
+		for _, h := range hits {
+			if h.dist < reach {
+				settled++
+			}
+		}
+		if settled >= k {
+			break
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].dist != hits[j].dist {
+			return hits[i].dist < hits[j].dist
+		}
+		return hits[i].unit.ID < hits[j].unit.ID
+	})
+	nearest := make([]*Unit, 0, min(k, len(hits)))
+	for _, h := range hits[:min(k, len(hits))] {
+		nearest = append(nearest, h.unit)
+	}
+	return nearest
+}
+
+// ringCells returns the occupied cells exactly ring steps from origin, or
+// every occupied cell. Caller holds ix.mu.
+func (ix *SpatialIndex) ringCells(origin cellKey, ring int, everything bool) map[cellKey]map[*Unit]*spatialEntry {
+	if everything {
+		return ix.cells
+	}
+	out := make(map[cellKey]map[*Unit]*spatialEntry)
+	add := func(x, y int) {
+		key := cellKey{X: x, Y: y}
+		if cell, ok := ix.cells[key]; ok {
+			out[key] = cell
+		}
+	}
+	if ring == 0 {
+		add(origin.X, origin.Y)
+		return out
+	}
+	for d := -ring; d <= ring; d++ {
+		add(origin.X+d, origin.Y-ring)
+		add(origin.X+d, origin.Y+ring)
+	}
+	for d := -ring + 1; d < ring; d++ {
+		add(origin.X-ring, origin.Y+d)
+		add(origin.X+ring, origin.Y+d)
+	}
+	return out
+}
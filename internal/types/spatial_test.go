@@ -0,0 +1,211 @@
+package types
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unitIDs lists the units' IDs in order, for readable comparisons
+func unitIDs(units []*Unit) []string {
+	ids := make([]string, 0, len(units))
+	for _, u := range units {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func TestSpatialIndex_Queries(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	terran := []UnitOption{WithClock(clock), WithOwner(red)}
+	zerg := []UnitOption{WithClock(clock), WithOwner(teal)}
+	units := []*Unit{
+		NewUnit("marine", Marine, Position{}, &wg, terran...),
+		NewUnit("medic", Medic, Position{X: 1}, &wg, terran...),
+		NewUnit("wraith", Wraith, Position{X: 2, Y: 2}, &wg, terran...),
+		NewUnit("ling", Zergling, Position{X: 5}, &wg, zerg...),
+		NewUnit("muta", Mutalisk, Position{X: 20, Y: 20}, &wg, zerg...),
+		NewUnit("straggler", Zergling, Position{X: -30}, &wg, zerg...),
+	}
+	ix := NewSpatialIndex(4)
+	for _, u := range units {
+		ix.Track(u)
+		ix.Track(u)
+	}
+	require.Equal(t, 6, ix.Len(), "tracking twice is a no-op")
+
+	require.Equal(t, []string{"marine", "medic", "wraith"}, unitIDs(ix.InRadius(Position{}, 3, SpatialFilter{})))
+	require.Equal(t, []string{"wraith"}, unitIDs(ix.InRadius(Position{}, 3, SpatialFilter{Layers: []ElevationLayer{Air}})))
+	require.Equal(t, []string{"ling"}, unitIDs(ix.InRadius(Position{}, 6, SpatialFilter{Factions: []*Faction{teal}})))
+	box := NewRectangle(Position{X: 21, Y: 21}, Position{X: -1, Y: -1})
+	require.Equal(t, []string{"ling", "muta"}, unitIDs(ix.InRect(box, SpatialFilter{Factions: []*Faction{teal}})))
+	require.Equal(t, []string{"muta", "wraith"}, unitIDs(ix.InRect(box, SpatialFilter{Layers: []ElevationLayer{Air}})))
+
+	require.Equal(t, []string{"marine", "medic"}, unitIDs(ix.Nearest(Position{}, 2, SpatialFilter{})))
+	require.Equal(t, []string{"ling", "muta"}, unitIDs(ix.Nearest(Position{}, 2, SpatialFilter{Factions: []*Faction{teal}})),
+		"the Mutalisk (28 away) beats the straggler (30 away) across several rings")
+	require.Equal(t, []string{"marine", "medic", "wraith", "ling", "muta", "straggler"}, unitIDs(ix.Nearest(Position{}, 10, SpatialFilter{})))
+	require.Empty(t, ix.Nearest(Position{}, 0, SpatialFilter{}))
+
+	// The dead drop out of the index, not just out of the answers
+	units[3].TakeDamage(1000)
+	require.Equal(t, []string{"muta", "straggler"}, unitIDs(ix.Nearest(Position{}, 2, SpatialFilter{Factions: []*Faction{teal}})))
+	require.Eventually(t, func() bool { return ix.Len() == 5 }, time.Second, time.Millisecond)
+
+	ix.Untrack(units[4])
+	ix.Untrack(units[4])
+	require.Equal(t, 4, ix.Len())
+	require.Equal(t, []string{"straggler"}, unitIDs(ix.Nearest(Position{}, 2, SpatialFilter{Factions: []*Faction{teal}})))
+
+	ix.Close()
+	require.Zero(t, ix.Len())
+	ix.Track(units[0])
+	require.Zero(t, ix.Len(), "a closed index tracks nothing")
+	require.Empty(t, ix.InRadius(Position{}, 100, SpatialFilter{}))
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSpatialIndex_FollowsMovesAndTransports(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{X: 0.49}, &wg, WithClock(clock), WithOwner(red))
+	drop := NewUnit("drop", DropShip, Position{Y: 3}, &wg, WithClock(clock), WithOwner(red))
+	medic := NewUnit("medic", Medic, Position{X: 1, Y: 3}, &wg, WithClock(clock), WithOwner(red))
+
+	ix := NewSpatialIndex(0.5)
+	defer ix.Close()
+	for _, u := range []*Unit{marine, drop, medic} {
+		ix.Track(u)
+	}
+
+	// Between EventMoved reports the index is behind, but never wrong:
+	// the marine crosses into the next cell before it reports again
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 30}}))
+	advanceUntil(t, clock, func() bool { return marine.GetPosition().X > 0.5 })
+	require.Equal(t, []*Unit{marine}, ix.InRadius(marine.GetPosition(), 0.001, SpatialFilter{}))
+
+	advanceUntil(t, clock, func() bool { return marine.GetState() == Idle })
+	require.Eventually(t, func() bool {
+		return len(ix.InRect(NewRectangle(Position{X: 29}, Position{X: 31}), SpatialFilter{})) == 1
+	}, time.Second, time.Millisecond, "arrival is reported")
+	require.Empty(t, ix.InRadius(Position{}, 1, SpatialFilter{}))
+
+	// Passengers are off the map, and land where the transport drops them
+	require.NoError(t, sendOrder(t, drop, Command{Type: CmdLoad, Target: medic}))
+	advanceUntil(t, clock, func() bool { return medic.IsLoaded() && drop.GetState() == Idle })
+	require.Equal(t, []*Unit{drop}, ix.InRadius(Position{Y: 3}, 2, SpatialFilter{}))
+	require.NoError(t, sendOrder(t, drop, Command{Type: CmdMove, Dest: Position{X: 10, Y: 10}}))
+	require.NoError(t, drop.SendCommand(Command{Type: CmdUnload, Queue: true}))
+	advanceUntil(t, clock, func() bool { return !medic.IsLoaded() })
+	require.Eventually(t, func() bool {
+		return len(ix.InRadius(Position{X: 10, Y: 10}, 1, SpatialFilter{})) == 2
+	}, time.Second, time.Millisecond, "the unload is reported")
+	require.Equal(t, []*Unit{medic}, ix.Nearest(Position{X: 10, Y: 10}, 1, SpatialFilter{Layers: []ElevationLayer{Ground}}))
+
+	for _, u := range []*Unit{marine, drop, medic} {
+		u.Shutdown()
+	}
+	wg.Wait()
+	require.Eventually(t, func() bool { return ix.Len() == 0 }, time.Second, time.Millisecond, "stopped units drop out")
+}
+
+func TestSpatialIndex_ConcurrentReadersAndWriters(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	rng := rand.New(rand.NewSource(1998))
+	spot := func() Position { return Position{X: rng.Float64() * 20, Y: rng.Float64() * 20} }
+
+	var wg sync.WaitGroup
+	kinds := []UnitType{Marine, Zergling, Wraith, Mutalisk}
+	units := make([]*Unit, 150)
+	ix := NewSpatialIndex(0)
+	defer ix.Close()
+	for i := range units {
+		units[i] = NewUnit(fmt.Sprintf("u%03d", i), kinds[i%len(kinds)], spot(), &wg, WithClock(clock))
+		ix.Track(units[i])
+	}
+	for _, u := range units {
+		require.NoError(t, u.SendCommand(Command{Type: CmdMove, Dest: spot()}))
+	}
+
+	// Readers query and a writer churns tracking while everyone walks
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				at := Position{X: float64(i % 20), Y: float64(r * 5)}
+				ix.InRadius(at, 4, SpatialFilter{Layers: []ElevationLayer{Air}})
+				ix.Nearest(at, 5, SpatialFilter{})
+			}
+		}(r)
+	}
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			u := units[i%len(units)]
+			ix.Untrack(u)
+			ix.Track(u)
+		}
+	}()
+	advanceUntil(t, clock, func() bool {
+		for _, u := range units {
+			if u.GetState() != Idle {
+				return false
+			}
+		}
+		return true
+	})
+	close(stop)
+	readers.Wait()
+	require.Equal(t, len(units), ix.Len())
+
+	// Once everyone has arrived, the index agrees with checking every unit
+	center, radius := Position{X: 10, Y: 10}, 5.0
+	var want []*Unit
+	for _, u := range units {
+		if u.GetPosition().Distance(center) <= radius {
+			want = append(want, u)
+		}
+	}
+	byDistance := append([]*Unit(nil), units...)
+	sort.Slice(byDistance, func(i, j int) bool {
+		return byDistance[i].GetPosition().Distance(center) < byDistance[j].GetPosition().Distance(center)
+	})
+	require.Eventually(t, func() bool {
+		return slices.Equal(unitIDs(want), unitIDs(ix.InRadius(center, radius, SpatialFilter{})))
+	}, time.Second, time.Millisecond)
+	require.Equal(t, unitIDs(byDistance[:10]), unitIDs(ix.Nearest(center, 10, SpatialFilter{})))
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,393 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// SPELLS - The Brood War Set
+// ═══════════════════════════════════════════════════════════════════════════
+//
+//	Heal             Medic: restores Amount health to an allied organic
+//	                 unit (not the Medic itself).
+//	Irradiate        Science Vessel: Amount damage over Duration to the
+//	                 target and every organic unit within Radius of it,
+//	                 straight to health. Mechanical units shrug it off.
+//	EMPShockwave     Science Vessel: drains all shields and energy in the
+//	                 area at once.
+//	DefensiveMatrix  Science Vessel: soaks up to Amount weapon damage on
+//	                 one unit before it reaches shields or health.
+//	PsionicStorm     Templar: Amount damage over Duration to anything
+//	                 standing in the storm, shields first. Two storms on
+//	                 the same spot don't stack.
+//	DarkSwarm        Defiler: ground units under the swarm can't be hurt
+//	                 by ranged attacks. Melee, splash and spells still work.
+//	Plague           Defiler: Amount damage over Duration to every unit in
+//	                 the area, straight to health. It never kills—a
+//	                 plagued unit stops at 1 HP.
+//	StasisField      Arbiter: everything in the area is frozen for
+//	                 Duration—it can't act, take orders, or be hurt or
+//	                 targeted by anything.
+//
+// Area spells skip the caster and anything in a transport, and need a
+// World to find the units in the area; without one they land on nobody.
+// Spell damage ignores armor and the damage matrix, and Defensive Matrix
+// only stops weapons.
+//
+// 🎴 MTG ANALOGY: Auras and global enchantments with a fading counter.
+// Irradiate is an aura on one creature that hurts its neighbours, Storm
+// and Swarm are enchantments on a patch of the battlefield, and Stasis is
+// phasing: the creature is still there, it just isn't in the game.
+//
+// ⚔️ SC:BW ANALOGY: The late game. Storms over a Hydralisk ball, Irradiate
+// on the Defiler, Dark Swarm over the Lurkers so the Marines can't touch
+// them, and an Arbiter freezing half the Terran army to split the fight.
+//
+// 🎓 LEARNING: Lasting effects are ticked by the unit they're on, in its
+// own goroutine—no timers, no extra goroutines. Storms and swarms belong
+// to the World, and each unit checks whether it's standing in one.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// ErrInStasis is returned (wrapped in an *OrderError) when ordering a unit
+// frozen in a Stasis Field
+var ErrInStasis = errors.New("unit is in stasis")
+
+// effect is a spell still working on a unit
+type effect struct {
+	source *Unit     // The caster, credited with kills
+	until  time.Time // When it wears off
+	last   time.Time // Damage over time has been dealt up to here
+	left   float64   // Defensive Matrix: damage it can still absorb
+}
+
+// zone is a spell left on the ground: a Psionic Storm or a Dark Swarm
+type zone struct {
+	ability AbilityType
+	source  *Unit
+	at      Position
+	radius  float64
+	start   time.Time
+	until   time.Time
+}
+
+// covers reports whether c stands inside the zone's area
+func (z zone) covers(c *Unit) bool {
+	reach := z.radius + c.GetSize().Radius()
+	return c.GetPosition().DistanceSq(z.at) <= reach*reach
+}
+
+// activeAt reports whether the zone is on the ground at t
+func (z zone) activeAt(t time.Time) bool {
+	return !t.Before(z.start) && t.Before(z.until)
+}
+
+// HasEffect reports whether the spell is working on the unit right now
+func (u *Unit) HasEffect(at AbilityType) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.effects[at] != nil
+}
+
+// Effects returns the spells working on the unit right now
+func (u *Unit) Effects() []AbilityType {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	var set []AbilityType
+	for at := range u.effects {
+		set = append(set, at)
+	}
+	return sortedAbilities(set)
+}
+
+// InStasis reports whether the unit is frozen in a Stasis Field
+func (u *Unit) InStasis() bool {
+	return u.HasEffect(StasisField)
+}
+
+// stasisError refuses every order for a unit in stasis, or returns nil
+func (u *Unit) stasisError(cmd Command) error {
+	if !u.InStasis() {
+		return nil
+	}
+	return &OrderError{UnitID: u.ID, Order: cmd.Type, Reason: "frozen", Err: ErrInStasis}
+}
+
+// resolveSpell applies the spell cast by u
+func (u *Unit) resolveSpell(world *World, spell Ability, target *Unit, at Position, now time.Time) {
+	switch spell.Type {
+	case Heal:
+		target.repair(spell.Amount)
+		target.emit(UnitEvent{Type: EventEffectBegan, Source: target, Target: u, Timestamp: now, Data: Heal})
+	case Irradiate, DefensiveMatrix:
+		target.addEffect(u, spell, now)
+	case EMPShockwave, Plague, StasisField:
+		if world == nil {
+			return
+		}
+		for _, c := range world.unitsInArea(at, spell.Radius, u) {
+			if spell.Type == EMPShockwave {
+				c.drainShieldsAndEnergy(u, now)
+			} else {
+				c.addEffect(u, spell, now)
+			}
+		}
+	case PsionicStorm, DarkSwarm:
+		if world == nil {
+			return
+		}
+		world.addZone(zone{ability: spell.Type, source: u, at: at, radius: spell.Radius, start: now, until: now.Add(spell.Duration)})
+	}
+}
+
+// addEffect puts a lasting spell on the unit. Casting it again starts it
+// over. Units in stasis are out of reach.
+func (u *Unit) addEffect(source *Unit, spell Ability, now time.Time) {
+	u.mu.Lock()
+	if u.state == Dead || u.effects[StasisField] != nil {
+		u.mu.Unlock()
+		return
+	}
+	if u.effects == nil {
+		u.effects = make(map[AbilityType]*effect)
+	}
+	u.effects[spell.Type] = &effect{source: source, until: now.Add(spell.Duration), last: now, left: spell.Amount}
+	if spell.Type == StasisField {
+		u.velocity = 0
+	}
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventEffectBegan, Source: u, Target: source, Timestamp: now, Data: spell.Type})
+}
+
+// drainShieldsAndEnergy is the EMP Shockwave landing on the unit
+func (u *Unit) drainShieldsAndEnergy(source *Unit, now time.Time) {
+	u.mu.Lock()
+	if u.state == Dead || u.effects[StasisField] != nil {
+		u.mu.Unlock()
+		return
+	}
+	u.shields = 0
+	u.energy = 0
+	u.lastDamaged = now
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventEffectBegan, Source: u, Target: source, Timestamp: now, Data: EMPShockwave})
+}
+
+// dot is one tick's worth of damage over time
+type dot struct {
+	ability AbilityType
+	source  *Unit
+	amount  float64
+}
+
+// tickEffects deals the damage over time due since the last tick (the
+// unit's own spells, and any storm it stands in) and drops the effects that
+// wore off, one EventEffectEnded each. Returns whether the unit is frozen
+// in stasis—in which case nothing else about it moves this tick.
+func (u *Unit) tickEffects(now time.Time) bool {
+	u.mu.Lock()
+	since := u.lastEffects
+	u.lastEffects = now
+	var dots []dot
+	var ended []AbilityType
+	for at, e := range u.effects {
+		spell := abilities[at]
+		end := now
+		if e.until.Before(end) {
+			end = e.until
+		}
+		if (at == Irradiate || at == Plague) && end.After(e.last) {
+			amount := spell.Amount * end.Sub(e.last).Seconds() / spell.Duration.Seconds()
+			dots = append(dots, dot{ability: at, source: e.source, amount: amount})
+			e.last = end
+		}
+		if !now.Before(e.until) || (at == DefensiveMatrix && e.left <= 0) {
+			delete(u.effects, at)
+			ended = append(ended, at)
+		}
+	}
+	frozen := u.effects[StasisField] != nil
+	if frozen {
+		// Time stands still: nothing regenerates and no move picks up
+		// where it left off with a burst of speed
+		u.lastRegen, u.lastStep, u.velocity = now, now, 0
+	}
+	world, loaded := u.world, u.carrier != nil
+	u.mu.Unlock()
+
+	sort.Slice(dots, func(i, j int) bool { return dots[i].ability < dots[j].ability })
+	for _, d := range dots {
+		if d.ability == Irradiate {
+			u.irradiate(world, loaded, d, now)
+			continue
+		}
+		u.spellDamage(d.source, d.amount, true, 1, now)
+	}
+	if world != nil && !loaded {
+		if source, amount := world.stormDamage(u, since, now); amount > 0 {
+			u.spellDamage(source, amount, false, 0, now)
+		}
+	}
+	for _, at := range sortedAbilities(ended) {
+		u.emit(UnitEvent{Type: EventEffectEnded, Source: u, Timestamp: now, Data: at})
+	}
+	return frozen
+}
+
+// irradiate deals one tick of Irradiate to the unit and the organic units
+// around it. A unit in a transport only poisons itself.
+func (u *Unit) irradiate(world *World, loaded bool, d dot, now time.Time) {
+	victims := []*Unit{u}
+	if world != nil && !loaded {
+		reach := abilities[Irradiate].Radius + u.GetSize().Radius()
+		victims = append(victims, world.unitsInArea(u.GetPosition(), reach, u)...)
+	}
+	for _, c := range victims {
+		if !c.IsMechanical() {
+			c.spellDamage(d.source, d.amount, true, 0, now)
+		}
+	}
+}
+
+// spellDamage deals amount of spell damage—no armor, no damage matrix, no
+// minimum—and kills the unit if it runs out of health, crediting source.
+// throughShields goes straight to health (Irradiate, Plague); floor is the
+// health the spell can't take the unit below. Units in stasis take nothing.
+func (u *Unit) spellDamage(source *Unit, amount float64, throughShields bool, floor float64, now time.Time) {
+	u.mu.Lock()
+	if u.state == Dead || amount <= 0 || u.effects[StasisField] != nil {
+		u.mu.Unlock()
+		return
+	}
+	if !throughShields {
+		toShields := math.Min(u.shields, amount)
+		u.shields -= toShields
+		amount -= toShields
+	}
+	u.health -= math.Min(amount, math.Max(0, u.health-floor))
+	u.lastDamaged = now
+	killed := u.health <= 0
+	if killed {
+		u.health = 0
+		u.state = Dead
+		u.target = nil
+		u.velocity = 0
+	}
+	u.mu.Unlock()
+
+	if killed {
+		u.die(source, now)
+	}
+}
+
+// soakLocked lets a Defensive Matrix absorb what it can of one hit and
+// returns the rest. Caller must hold u.mu.
+func (u *Unit) soakLocked(damage float64) float64 {
+	m := u.effects[DefensiveMatrix]
+	if m == nil || m.left <= 0 {
+		return damage
+	}
+	soaked := math.Min(m.left, damage)
+	m.left -= soaked
+	return damage - soaked
+}
+
+// addZone leaves a storm or swarm on the ground, clearing out the ones
+// that ran their course before the last tick—a unit still owes damage for
+// a storm that ended since it last ticked
+func (w *World) addZone(z zone) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	live := w.zones[:0]
+	for _, old := range w.zones {
+		if old.until.After(z.start.Add(-TickRate)) {
+			live = append(live, old)
+		}
+	}
+	w.zones = append(live, z)
+}
+
+// zonesCovering lists the zones of one kind that c stands in, whether or
+// not they're still active
+func (w *World) zonesCovering(ability AbilityType, c *Unit) []zone {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var in []zone
+	for _, z := range w.zones {
+		if z.ability == ability && z.covers(c) {
+			in = append(in, z)
+		}
+	}
+	return in
+}
+
+// stormDamage is the Psionic Storm damage c takes for standing where it is
+// over (since, now], and who cast the storm. Overlapping storms don't
+// stack: c takes the one it has stood in longest.
+func (w *World) stormDamage(c *Unit, since, now time.Time) (*Unit, float64) {
+	spell := abilities[PsionicStorm]
+	var source *Unit
+	var longest time.Duration
+	for _, z := range w.zonesCovering(PsionicStorm, c) {
+		if d := overlap(since, now, z.start, z.until); d > longest {
+			source, longest = z.source, d
+		}
+	}
+	return source, spell.Amount * longest.Seconds() / spell.Duration.Seconds()
+}
+
+// shelters reports whether a Dark Swarm keeps weapon from hurting target:
+// a ranged, single-target weapon shooting at a ground unit under a swarm
+func (w *World) shelters(target *Unit, weapon Weapon, now time.Time) bool {
+	if weapon.IsMelee() || weapon.Splash.Shape != NoSplash || target.GetElevationLayer() == Air {
+		return false
+	}
+	for _, z := range w.zonesCovering(DarkSwarm, target) {
+		if z.activeAt(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// unitsInArea lists the units whose edge is within radius of at, ordered
+// by ID. Dead units, passengers and except are left out.
+func (w *World) unitsInArea(at Position, radius float64, except *Unit) []*Unit {
+	var in []*Unit
+	for _, c := range w.Units() {
+		if c == except || c.IsDead() || c.IsLoaded() {
+			continue
+		}
+		reach := radius + c.GetSize().Radius()
+		if c.GetPosition().DistanceSq(at) <= reach*reach {
+			in = append(in, c)
+		}
+	}
+	return in
+}
+
+// sortedAbilities orders a set of spells so events come out the same way
+// every run
+func sortedAbilities(set []AbilityType) []AbilityType {
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+	return set
+}
+
+// overlap is how much of (from, to] falls inside [start, end]
+func overlap(from, to, start, end time.Time) time.Duration {
+	if start.After(from) {
+		from = start
+	}
+	if end.Before(to) {
+		to = end
+	}
+	if to.Before(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
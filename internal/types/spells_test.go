@@ -0,0 +1,286 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// settle waits for u's goroutine to finish the tick it's on: the reply to
+// an order only comes once that tick is done
+func settle(t *testing.T, u *Unit) {
+	t.Helper()
+	require.NoError(t, sendOrder(t, u, Command{Type: CmdStop}))
+}
+
+func TestSpell_Heal(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	medic := NewUnit("medic", Medic, Position{}, &wg, WithClock(clock))
+	marine := NewUnit("marine", Marine, Position{X: 1}, &wg, WithClock(clock))
+	healed := marine.Subscribe(EventTypes(EventEffectBegan), WithBuffer(4))
+
+	marine.TakeDamage(25)
+	require.NoError(t, sendOrder(t, medic, Command{Type: CmdCast, Ability: Heal, Target: marine}))
+	clock.Advance(TickRate)
+	ev := waitForEvent(t, healed, EventEffectBegan)
+	require.Equal(t, Heal, ev.Data)
+	require.Same(t, medic, ev.Target)
+	require.Equal(t, 25, marine.GetHealth())
+	require.Eventually(t, func() bool { return medic.GetState() == Idle }, time.Second, time.Millisecond)
+	require.InDelta(t, 45, medic.GetEnergyExact(), 0.1)
+
+	medic.Shutdown()
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestSpell_Irradiate(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Boxer", Terran, Blue)
+
+	var wg sync.WaitGroup
+	vessel := NewUnit("vessel", ScienceVessel, Position{}, &wg, WithClock(clock), WithOwner(red))
+	target := NewUnit("target", Firebat, Position{X: 5}, &wg, WithClock(clock), WithOwner(blue))
+	near := NewUnit("near", Marine, Position{X: 6}, &wg, WithClock(clock), WithOwner(blue))
+	far := NewUnit("far", Marine, Position{X: 8}, &wg, WithClock(clock), WithOwner(blue))
+	vulture := NewUnit("vulture", Vulture, Position{X: 5, Y: 1}, &wg, WithClock(clock), WithOwner(blue))
+	units := []*Unit{vessel, target, near, far, vulture}
+	for _, u := range units {
+		world.Add(u)
+	}
+	setEnergy(vessel, 200)
+	kills := vessel.Subscribe(EventTypes(EventKilled), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, vessel, Command{Type: CmdCast, Ability: Irradiate, Target: target}))
+	advanceUntil(t, clock, func() bool { return target.HasEffect(Irradiate) })
+
+	// 250 over 30 seconds: about 25 in the first three, to health only
+	clock.Advance(3 * time.Second)
+	settle(t, target)
+	lost := 50 - target.GetHealthExact()
+	require.InDelta(t, 25, lost, 0.6)
+	require.InDelta(t, 40-lost, near.GetHealthExact(), 1e-9, "organic and within a tile")
+	require.Equal(t, 40, far.GetHealth())
+	require.Equal(t, 80, vulture.GetHealth(), "mechanical")
+
+	clock.Advance(4 * time.Second)
+	require.Eventually(t, func() bool { return target.IsDead() && near.IsDead() }, time.Second, time.Millisecond)
+	got := map[*Unit]bool{}
+	for i := 0; i < 2; i++ {
+		got[waitForEvent(t, kills, EventKilled).Target] = true
+	}
+	require.Equal(t, map[*Unit]bool{target: true, near: true}, got)
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSpell_DefensiveMatrix(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	vessel := NewUnit("vessel", ScienceVessel, Position{}, &wg, WithClock(clock))
+	marine := NewUnit("marine", Marine, Position{X: 1}, &wg, WithClock(clock))
+	setEnergy(vessel, 200)
+	sub := marine.Subscribe(EventTypes(EventEffectBegan, EventEffectEnded), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, vessel, Command{Type: CmdCast, Ability: DefensiveMatrix, Target: marine}))
+	clock.Advance(TickRate)
+	waitForEvent(t, sub, EventEffectBegan)
+
+	require.Equal(t, 40.0, marine.TakeDamage(200))
+	require.Equal(t, 30.0, marine.TakeDamage(60), "50 left in the matrix")
+	clock.Advance(TickRate)
+	ev := waitForEvent(t, sub, EventEffectEnded)
+	require.Equal(t, DefensiveMatrix, ev.Data)
+	require.False(t, marine.HasEffect(DefensiveMatrix))
+	require.Equal(t, 20.0, marine.TakeDamage(10))
+
+	vessel.Shutdown()
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestSpell_PsionicStormDoesNotStack(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+	red := NewFaction("p1", "Flash", Terran, Red)
+
+	var wg sync.WaitGroup
+	ht1 := NewUnit("ht1", Templar, Position{X: 2}, &wg, WithClock(clock), WithOwner(blue))
+	ht2 := NewUnit("ht2", Templar, Position{X: 2, Y: 1}, &wg, WithClock(clock), WithOwner(blue))
+	zealot := NewUnit("zealot", Zealot, Position{X: 10}, &wg, WithClock(clock), WithOwner(blue))
+	marine := NewUnit("marine", Marine, Position{X: 10.5}, &wg, WithClock(clock), WithOwner(red))
+	outside := NewUnit("outside", Marine, Position{X: 13}, &wg, WithClock(clock), WithOwner(red))
+	units := []*Unit{ht1, ht2, zealot, marine, outside}
+	for _, u := range units {
+		world.Add(u)
+	}
+	setEnergy(ht1, 200)
+	setEnergy(ht2, 200)
+	died := marine.Subscribe(EventTypes(EventDied), WithBuffer(4))
+
+	storm := Command{Type: CmdCast, Ability: PsionicStorm, Dest: Position{X: 10}}
+	require.NoError(t, sendOrder(t, ht1, storm))
+	require.NoError(t, sendOrder(t, ht2, storm))
+	clock.Advance(4 * time.Second)
+
+	ev := waitForEvent(t, died, EventDied)
+	require.Contains(t, []*Unit{ht1, ht2}, ev.Target, "the storm's caster gets the kill")
+	settle(t, zealot)
+	require.InDelta(t, 160-112, zealot.GetHealthExact()+zealot.GetShieldsExact(), 1e-6, "shields first, one storm's worth")
+	require.Equal(t, 40, outside.GetHealth())
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSpell_DarkSwarm(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+	red := NewFaction("p1", "Flash", Terran, Red)
+
+	var wg sync.WaitGroup
+	defiler := NewUnit("defiler", Defiler, Position{}, &wg, WithClock(clock), WithOwner(teal))
+	ling := NewUnit("ling", Zergling, Position{X: 5}, &wg, WithClock(clock), WithOwner(teal))
+	muta := NewUnit("muta", Mutalisk, Position{X: 5, Y: 1}, &wg, WithClock(clock), WithOwner(teal))
+	marine := NewUnit("marine", Marine, Position{X: 8}, &wg, WithClock(clock), WithOwner(red))
+	firebat := NewUnit("firebat", Firebat, Position{X: 5.5}, &wg, WithClock(clock), WithOwner(red))
+	units := []*Unit{defiler, ling, muta, marine, firebat}
+	for _, u := range units {
+		world.Add(u)
+	}
+	setEnergy(defiler, 200)
+	missed := marine.Subscribe(EventTypes(EventMissed), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, defiler, Command{Type: CmdCast, Ability: DarkSwarm, Dest: Position{X: 5}}))
+	advanceUntil(t, clock, func() bool { return defiler.GetState() == Idle })
+
+	now := clock.Now()
+	rifle, _ := marine.GetGroundWeapon()
+	dmg, fired := marine.tryFire(rifle, ling, now)
+	require.True(t, fired)
+	require.Zero(t, dmg)
+	waitForEvent(t, missed, EventMissed)
+	require.Equal(t, 35, ling.GetHealth())
+
+	antiAir, _ := marine.GetAirWeapon()
+	dmg, _ = marine.tryFire(antiAir, muta, now.Add(time.Second))
+	require.Positive(t, dmg, "the swarm only covers the ground")
+
+	flamer, _ := firebat.GetGroundWeapon()
+	dmg, _ = firebat.tryFire(flamer, ling, now)
+	require.Positive(t, dmg, "melee and splash still hurt")
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSpell_PlagueNeverKills(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	defiler := NewUnit("defiler", Defiler, Position{}, &wg, WithClock(clock))
+	zealot := NewUnit("zealot", Zealot, Position{X: 5}, &wg, WithClock(clock))
+	world.Add(defiler)
+	world.Add(zealot)
+	setEnergy(defiler, 200)
+	sub := zealot.Subscribe(EventTypes(EventEffectEnded), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, defiler, Command{Type: CmdCast, Ability: Plague, Dest: Position{X: 5}}))
+	advanceUntil(t, clock, func() bool { return zealot.HasEffect(Plague) })
+	clock.Advance(26 * time.Second)
+	ev := waitForEvent(t, sub, EventEffectEnded)
+	require.Equal(t, Plague, ev.Data)
+	require.Equal(t, 1.0, zealot.GetHealthExact())
+	require.False(t, zealot.IsDead())
+	require.Equal(t, 60, zealot.GetShields(), "straight to health")
+
+	defiler.Shutdown()
+	zealot.Shutdown()
+	wg.Wait()
+}
+
+func TestSpell_EMPShockwave(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	vessel := NewUnit("vessel", ScienceVessel, Position{}, &wg, WithClock(clock))
+	zealot := NewUnit("zealot", Zealot, Position{X: 6}, &wg, WithClock(clock))
+	templar := NewUnit("templar", Templar, Position{X: 7}, &wg, WithClock(clock))
+	units := []*Unit{vessel, zealot, templar}
+	for _, u := range units {
+		world.Add(u)
+	}
+	setEnergy(vessel, 200)
+
+	require.NoError(t, sendOrder(t, vessel, Command{Type: CmdCast, Ability: EMPShockwave, Dest: Position{X: 6.5}}))
+	advanceUntil(t, clock, func() bool { return vessel.GetState() == Idle })
+	require.Zero(t, zealot.GetShields())
+	require.Zero(t, templar.GetShields())
+	require.Equal(t, 100, zealot.GetHealth(), "shields and energy only")
+	require.Less(t, templar.GetEnergy(), 1)
+	require.InDelta(t, 100, vessel.GetEnergyExact(), 0.1, "never the caster")
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSpell_StasisField(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+	red := NewFaction("p1", "Flash", Terran, Red)
+
+	var wg sync.WaitGroup
+	arbiter := NewUnit("arbiter", Arbiter, Position{}, &wg, WithClock(clock), WithOwner(blue))
+	dragoon := NewUnit("dragoon", Dragoon, Position{X: 3}, &wg, WithClock(clock), WithOwner(blue))
+	marine := NewUnit("marine", Marine, Position{X: 6}, &wg, WithClock(clock), WithOwner(red))
+	units := []*Unit{arbiter, dragoon, marine}
+	for _, u := range units {
+		world.Add(u)
+	}
+	setEnergy(arbiter, 200)
+	sub := marine.Subscribe(EventTypes(EventEffectEnded), WithBuffer(4))
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 100}}))
+
+	require.NoError(t, sendOrder(t, arbiter, Command{Type: CmdCast, Ability: StasisField, Dest: Position{X: 6}}))
+	advanceUntil(t, clock, func() bool { return marine.InStasis() })
+	frozenAt := marine.GetPosition()
+
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdStop}), ErrInStasis)
+	err := dragoon.CanTarget(marine)
+	require.ErrorIs(t, err, ErrCannotTarget)
+	require.ErrorContains(t, err, "target is in stasis")
+	require.False(t, world.IsTargetable(blue, marine))
+	require.Equal(t, 40.0, marine.TakeDamage(100), "can't be hurt either")
+
+	clock.Advance(10 * time.Second)
+	require.Equal(t, frozenAt, marine.GetPosition())
+
+	// Thawed out, it carries on with the move
+	clock.Advance(21 * time.Second)
+	ev := waitForEvent(t, sub, EventEffectEnded)
+	require.Equal(t, StasisField, ev.Data)
+	advanceUntil(t, clock, func() bool { return marine.GetPosition().X > frozenAt.X })
+	require.NoError(t, dragoon.CanTarget(marine))
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
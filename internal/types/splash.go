@@ -0,0 +1,244 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// SPLASH DAMAGE - Don't Clump Up
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Some weapons hit everything around the impact, not just the target:
+//
+//	Radial  A circle around the target: Siege Tank (sieged), Reaver,
+//	        Firebat, Valkyrie, Corsair.
+//	Line    A line from the attacker through the target out to the
+//	        weapon's full range: the Lurker's spines.
+//
+// Each splash weapon has three radii, measured from the impact (or the
+// line) to the edge of the unit hit. Inside Inner a unit takes full
+// damage, inside Medium half, inside Outer a quarter. Armor still applies
+// to every hit, and the target itself always takes full damage.
+//
+// Splash lands on the layer the weapon shoots at: a ground weapon hits
+// ground units, burrowed ones included, and an air weapon hits air units.
+// Detection doesn't matter—the blast finds a cloaked unit all the same.
+// Units in a transport are off the map and safe, and the attacker never
+// hits itself. Whether the shooter's own side and allies get hit is up to
+// the World (SetFriendlyFire); enemies and neutral units always do.
+//
+// 🎴 MTG ANALOGY: Pyroclasm with a targeting clause. You aim at one
+// creature and everything next to it takes some of it too.
+//
+// ⚔️ SC:BW ANALOGY: A line of sieged tanks against a ball of Hydralisks.
+// Ten Hydras clumped together is ten times the damage from every shell—
+// and the Marines who wandered into the blast take it as well.
+//
+// 🎓 LEARNING: Finding the units in the blast needs the rest of the
+// battlefield, so splash only happens in a World. The attacker's goroutine
+// resolves every hit itself; each hit takes the victim's lock in turn, so
+// the blast never needs more than one unit lock at a time.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// SplashShape is the area a splash weapon hits
+type SplashShape int
+
+const (
+	NoSplash SplashShape = iota
+	RadialSplash
+	LineSplash
+
+	splashShapeCount
+)
+
+var _ fmt.Stringer = SplashShape(0)
+
+var splashShapeNames = map[SplashShape]string{
+	NoSplash:     "None",
+	RadialSplash: "Radial",
+	LineSplash:   "Line",
+}
+
+func (s SplashShape) String() string {
+	if name, ok := splashShapeNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("SplashShape(%d)", s)
+}
+
+func (s SplashShape) IsValid() bool {
+	return s >= NoSplash && s < splashShapeCount
+}
+
+// parseSplashShape maps the stat sheet spelling to the enum
+func parseSplashShape(name string) (SplashShape, bool) {
+	for s, n := range splashShapeNames {
+		if n == name {
+			return s, true
+		}
+	}
+	return NoSplash, false
+}
+
+// Splash falloff: the share of the weapon's damage dealt in each band
+const (
+	InnerSplashFactor  = 1.0
+	MediumSplashFactor = 0.5
+	OuterSplashFactor  = 0.25
+)
+
+// SplashStatsData describes a weapon's splash in the stat sheet. Radii are
+// in tiles.
+type SplashStatsData struct {
+	Shape  string  `json:"shape"` // "Radial" or "Line"
+	Inner  float64 `json:"inner"`
+	Medium float64 `json:"medium"`
+	Outer  float64 `json:"outer"`
+}
+
+// Validate checks a splash entry; slot prefixes field names like
+// WeaponStatsData.Validate does
+func (s SplashStatsData) Validate(slot string) error {
+	var errs []error
+	if shape, ok := parseSplashShape(s.Shape); !ok || shape == NoSplash {
+		errs = append(errs, fmt.Errorf("%s.splash: unknown shape %q", slot, s.Shape))
+	}
+	if s.Inner < 0 || s.Inner > s.Medium || s.Medium > s.Outer || s.Outer <= 0 {
+		errs = append(errs, fmt.Errorf("%s.splash radii must satisfy 0 <= inner <= medium <= outer, outer > 0, got %g/%g/%g",
+			slot, s.Inner, s.Medium, s.Outer))
+	}
+	return errors.Join(errs...)
+}
+
+// Splash is the runtime form of a weapon's splash. The zero value is no
+// splash.
+type Splash struct {
+	Shape  SplashShape
+	Inner  float64
+	Medium float64
+	Outer  float64
+}
+
+// newSplash converts a stat sheet entry; nil in, no splash out
+func newSplash(data *SplashStatsData) Splash {
+	if data == nil {
+		return Splash{}
+	}
+	shape, _ := parseSplashShape(data.Shape)
+	return Splash{Shape: shape, Inner: data.Inner, Medium: data.Medium, Outer: data.Outer}
+}
+
+// Factor is the share of damage dealt to a unit whose edge is dist tiles
+// from the impact, or 0 outside the outer radius
+func (s Splash) Factor(dist float64) float64 {
+	switch {
+	case s.Shape == NoSplash || dist > s.Outer:
+		return 0
+	case dist <= s.Inner:
+		return InnerSplashFactor
+	case dist <= s.Medium:
+		return MediumSplashFactor
+	default:
+		return OuterSplashFactor
+	}
+}
+
+// SetFriendlyFire decides whether splash hits the shooter's own units and
+// its allies (BattleRules.FriendlyFire). Off by default.
+func (w *World) SetFriendlyFire(on bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.friendlyFire = on
+}
+
+// FriendlyFire reports whether splash hits the shooter's own side
+func (w *World) FriendlyFire() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.friendlyFire
+}
+
+// sameLayerGroup reports whether a weapon aimed at layer reaches a unit on
+// other: air weapons hit the air, ground weapons everything below it
+func sameLayerGroup(layer, other ElevationLayer) bool {
+	return (layer == Air) == (other == Air)
+}
+
+// splashDistance is how far c's edge is from the blast: from the impact
+// point for radial splash, from the line out of the attacker for line
+// splash
+func splashDistance(weapon Weapon, from, impact Position, c *Unit) float64 {
+	pos := c.GetPosition()
+	var d float64
+	if weapon.Splash.Shape == LineSplash {
+		d = math.Sqrt(distanceToSegmentSq(pos, from, lineEnd(from, impact, float64(weapon.Range))))
+	} else {
+		d = math.Sqrt(pos.DistanceSq(impact))
+	}
+	return math.Max(0, d-c.GetSize().Radius())
+}
+
+// lineEnd is the point length tiles from from in the direction of to, or to
+// itself if the two coincide
+func lineEnd(from, to Position, length float64) Position {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	dist := math.Hypot(dx, dy)
+	if dist == 0 {
+		return to
+	}
+	return Position{X: from.X + dx/dist*length, Y: from.Y + dy/dist*length}
+}
+
+// splashHit is one unit caught in a blast and the share of damage it takes
+type splashHit struct {
+	unit   *Unit
+	factor float64
+}
+
+// splashVictims lists the units besides target that a splash weapon fired
+// by attacker catches, ordered by ID
+func (w *World) splashVictims(attacker, target *Unit, weapon Weapon) []splashHit {
+	from, impact := attacker.GetPosition(), target.GetPosition()
+	layer := target.GetElevationLayer()
+	friendlyFire := w.FriendlyFire()
+
+	var hits []splashHit
+	for _, c := range w.Units() {
+		if c == attacker || c == target || c.IsDead() || c.IsLoaded() || c.InStasis() {
+			continue
+		}
+		if !sameLayerGroup(layer, c.GetElevationLayer()) {
+			continue
+		}
+		if !friendlyFire && IsAlly(attacker, c) {
+			continue
+		}
+		if f := weapon.Splash.Factor(splashDistance(weapon, from, impact, c)); f > 0 {
+			hits = append(hits, splashHit{unit: c, factor: f})
+		}
+	}
+	return hits
+}
+
+// splash deals the weapon's splash damage around target, one EventDamaged
+// per unit hit. Returns the total damage dealt.
+func (u *Unit) splash(world *World, weapon Weapon, target *Unit, now time.Time) float64 {
+	if weapon.Splash.Shape == NoSplash || world == nil {
+		return 0
+	}
+	perHit := u.perHitDamage(weapon)
+	total := 0.0
+	for _, hit := range world.splashVictims(u, target, weapon) {
+		dmg, _, killed := hit.unit.takeHits(perHit*hit.factor, weapon.DamageType, weapon.Hits, now)
+		total += dmg
+		u.emit(UnitEvent{Type: EventDamaged, Source: u, Target: hit.unit, Timestamp: now, Data: dmg})
+		if killed {
+			hit.unit.die(u, now)
+		}
+	}
+	return total
+}
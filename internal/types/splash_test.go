@@ -0,0 +1,129 @@
+package types
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplash_Factor(t *testing.T) {
+	s := Splash{Shape: RadialSplash, Inner: 0.5, Medium: 1, Outer: 2}
+	require.Equal(t, 1.0, s.Factor(0))
+	require.Equal(t, 1.0, s.Factor(0.5))
+	require.Equal(t, 0.5, s.Factor(0.75))
+	require.Equal(t, 0.25, s.Factor(2))
+	require.Zero(t, s.Factor(2.1))
+	require.Zero(t, Splash{}.Factor(0), "no splash, no share")
+	require.Equal(t, "Line", LineSplash.String())
+}
+
+func TestSplashStatsData_Validate(t *testing.T) {
+	require.NoError(t, SplashStatsData{Shape: "Line", Inner: 0.6, Medium: 0.6, Outer: 0.6}.Validate("groundWeapon"))
+
+	err := SplashStatsData{Shape: "Cone", Inner: 1, Medium: 0.5, Outer: 2}.Validate("airWeapon")
+	require.ErrorContains(t, err, `airWeapon.splash: unknown shape "Cone"`)
+	require.ErrorContains(t, err, "airWeapon.splash radii must satisfy 0 <= inner <= medium <= outer, outer > 0, got 1/0.5/2")
+	require.ErrorContains(t, SplashStatsData{Shape: "None", Outer: 1}.Validate("groundWeapon"), "unknown shape")
+
+	stats, ok := LookupUnitStats(SiegeTank)
+	require.True(t, ok)
+	siege := *stats.Siege // Shared with the embedded sheet; don't write through it
+	siege.GroundWeapon.Splash = &SplashStatsData{Shape: "Radial"}
+	stats.Siege = &siege
+	require.ErrorContains(t, stats.Validate(), "siege.groundWeapon.splash radii")
+}
+
+func TestSplash_SiegeShellOnClump(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	red1 := []UnitOption{WithClock(clock), WithOwner(red)}
+	zerg := []UnitOption{WithClock(clock), WithOwner(teal)}
+	tank := NewUnit("tank", SiegeTank, Position{}, &wg, red1...)
+	marine := NewUnit("marine", Marine, Position{X: 10, Y: 1}, &wg, red1...)
+	target := NewUnit("target", Hydralisk, Position{X: 10}, &wg, zerg...)
+	inner := NewUnit("inner", Hydralisk, Position{X: 10.5}, &wg, zerg...)
+	medium := NewUnit("medium", Hydralisk, Position{X: 11.2}, &wg, zerg...)
+	outer := NewUnit("outer", Hydralisk, Position{X: 11.6}, &wg, zerg...)
+	clear := NewUnit("clear", Hydralisk, Position{X: 12}, &wg, zerg...)
+	muta := NewUnit("muta", Mutalisk, Position{X: 10}, &wg, zerg...)
+	units := []*Unit{tank, marine, target, inner, medium, outer, clear, muta}
+	for _, u := range units {
+		world.Add(u)
+	}
+	damaged := tank.Subscribe(EventTypes(EventDamaged), WithBuffer(16))
+
+	stats, _ := LookupUnitStats(SiegeTank)
+	shell := *newWeapon(&stats.Siege.GroundWeapon)
+	require.Equal(t, RadialSplash, shell.Splash.Shape)
+
+	// 70 Explosive against Medium is 52.5, then halves per band
+	dmg, fired := tank.tryFire(shell, target, clock.Now())
+	require.True(t, fired)
+	require.InDelta(t, 52.5*2+26.25+13.125, dmg, 1e-9)
+	require.InDelta(t, 27.5, target.GetHealthExact(), 1e-9)
+	require.InDelta(t, 27.5, inner.GetHealthExact(), 1e-9)
+	require.InDelta(t, 53.75, medium.GetHealthExact(), 1e-9)
+	require.InDelta(t, 66.875, outer.GetHealthExact(), 1e-9)
+	require.Equal(t, 80, clear.GetHealth())
+	require.Equal(t, 120, muta.GetHealth(), "a ground shell doesn't reach the air")
+	require.Equal(t, 40, marine.GetHealth(), "no friendly fire")
+	hit := map[*Unit]bool{}
+	for i := 0; i < 4; i++ {
+		hit[waitForEvent(t, damaged, EventDamaged).Target] = true
+	}
+	require.Equal(t, map[*Unit]bool{target: true, inner: true, medium: true, outer: true}, hit)
+
+	// With friendly fire on, the Marine next to the impact takes a share
+	world.SetFriendlyFire(true)
+	require.True(t, world.FriendlyFire())
+	_, fired = tank.tryFire(shell, target, clock.Now().Add(shell.Cooldown))
+	require.True(t, fired)
+	require.InDelta(t, 40-35*0.5, marine.GetHealthExact(), 1e-9, "Small takes half of the Medium-band 35")
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestSplash_LurkerLine(t *testing.T) {
+	world := NewWorld(nil, 1)
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	lurker := NewUnit("lurker", Lurker, Position{}, &wg, WithClock(clock), WithOwner(teal))
+	target := NewUnit("target", Marine, Position{X: 2}, &wg, WithClock(clock), WithOwner(red))
+	behind := NewUnit("behind", Marine, Position{X: 5.5, Y: 0.5}, &wg, WithClock(clock), WithOwner(red))
+	beyond := NewUnit("beyond", Marine, Position{X: 7}, &wg, WithClock(clock), WithOwner(red))
+	aside := NewUnit("aside", Marine, Position{X: 3, Y: 2}, &wg, WithClock(clock), WithOwner(red))
+	ling := NewUnit("ling", Zergling, Position{X: 4}, &wg, WithClock(clock), WithOwner(red))
+	units := []*Unit{lurker, target, behind, beyond, aside, ling}
+	for _, u := range units {
+		world.Add(u)
+	}
+	ling.mu.Lock()
+	ling.elevationLayer = Burrowed
+	ling.mu.Unlock()
+
+	stats, _ := LookupUnitStats(Lurker)
+	spines := *newWeapon(stats.GroundWeapon)
+	_, fired := lurker.tryFire(spines, target, clock.Now())
+	require.True(t, fired)
+	require.Equal(t, 20, target.GetHealth())
+	require.Equal(t, 20, behind.GetHealth(), "the spines run on to full range")
+	require.Equal(t, 15, ling.GetHealth(), "burrowed units are on the ground too")
+	require.Equal(t, 40, beyond.GetHealth())
+	require.Equal(t, 40, aside.GetHealth())
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
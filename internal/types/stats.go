@@ -0,0 +1,611 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// UNIT STATS REGISTRY - Runtime-Loadable Balance Sheets
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// The embedded unit_stats.json is only the starting point. The registry lets
+// the balance team swap the base sheet at runtime and stack named override
+// layers ("patch 1.16.1") on top of it without rebuilding the binary.
+//
+// 🎴 MTG ANALOGY: The embedded sheet is the printed card. An override layer
+// is the Oracle errata—same card, updated text, and the newest errata wins.
+//
+// ⚔️ SC:BW ANALOGY: Blizzard patches. Patch 1.08 didn't ship a new game, it
+// shipped a delta on top of the existing unit data.
+//
+// 🎓 LEARNING: Every successful change bumps the registry version. Units
+// remember the version they were built from, so you can tell a pre-patch
+// Marine from a post-patch Marine in the same battle.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// StatsError describes a problem found while loading a stat sheet or an
+// override layer. Multiple problems are joined with errors.Join, so callers
+// can walk them with errors.As.
+type StatsError struct {
+	Source string // File path, override name, or "<reader>"
+	Unit   string // Unit type name ("" for sheet-level problems)
+	Err    error
+}
+
+func (e *StatsError) Error() string {
+	if e.Unit == "" {
+		return fmt.Sprintf("unit stats %s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("unit stats %s: %s: %v", e.Source, e.Unit, e.Err)
+}
+
+func (e *StatsError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks a single unit's stats for values the simulation can't use.
+// All problems are reported at once instead of stopping at the first one.
+func (s UnitStatsData) Validate() error {
+	var errs []error
+	if s.MaxHealth <= 0 {
+		errs = append(errs, fmt.Errorf("maxHealth must be positive, got %d", s.MaxHealth))
+	}
+	nonNegative := []struct {
+		field string
+		value int
+	}{
+		{"baseArmor", s.BaseArmor},
+		{"armorModifier", s.ArmorModifier},
+		{"attackModifier", s.AttackModifier},
+		{"cargoCapacity", s.CargoCapacity},
+	}
+	for _, f := range nonNegative {
+		if f.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", f.field, f.value))
+		}
+	}
+	if s.VisionRange <= 0 {
+		errs = append(errs, fmt.Errorf("visionRange must be positive, got %d", s.VisionRange))
+	}
+	if s.Speed <= 0 {
+		errs = append(errs, fmt.Errorf("speed must be positive, got %g", s.Speed))
+	}
+	if s.BuildTime <= 0 {
+		errs = append(errs, fmt.Errorf("buildTime must be positive, got %g", s.BuildTime))
+	}
+	if s.Acceleration < 0 {
+		errs = append(errs, fmt.Errorf("acceleration must not be negative, got %g", s.Acceleration))
+	}
+	if _, ok := parseElevationLayer(s.ElevationLayer); !ok {
+		errs = append(errs, fmt.Errorf("unknown elevationLayer %q", s.ElevationLayer))
+	}
+	if _, ok := parseUnitSize(s.UnitSize); !ok {
+		errs = append(errs, fmt.Errorf("unknown unitSize %q", s.UnitSize))
+	}
+	if s.GroundWeapon != nil {
+		errs = append(errs, s.GroundWeapon.Validate("groundWeapon"))
+	}
+	if s.AirWeapon != nil {
+		errs = append(errs, s.AirWeapon.Validate("airWeapon"))
+	}
+	if s.Ammo != nil {
+		errs = append(errs, s.Ammo.Validate())
+	}
+	if s.Shields != nil {
+		errs = append(errs, s.Shields.Validate())
+	}
+	if s.Energy != nil {
+		errs = append(errs, s.Energy.Validate())
+	}
+	if s.Siege != nil {
+		errs = append(errs, s.Siege.Validate())
+	}
+	errs = append(errs, validateMorphs(s.MorphsInto))
+	errs = append(errs, validateAbilities(s.Abilities, s.Energy))
+	if s.HealthRegen < 0 {
+		errs = append(errs, fmt.Errorf("healthRegen must not be negative, got %g", s.HealthRegen))
+	}
+	return errors.Join(errs...)
+}
+
+// statsFormat selects the decoder for a stat sheet
+type statsFormat int
+
+const (
+	formatAuto statsFormat = iota // Sniff the content
+	formatJSON
+	formatYAML
+)
+
+// rawSheet is a decoded-but-untyped sheet: unit name → field name → value.
+// Overrides are merged at this level so a delta only needs the fields it
+// changes.
+type rawSheet map[string]map[string]interface{}
+
+// statsLayer is one named override applied on top of the base sheet
+type statsLayer struct {
+	name  string
+	sheet rawSheet
+}
+
+// statsRegistry holds the base sheet, the override stack, and the resolved
+// result that NewUnit reads from.
+type statsRegistry struct {
+	mu        sync.RWMutex
+	base      rawSheet
+	overrides []statsLayer
+	resolved  map[string]UnitStatsData
+	version   uint64
+}
+
+// unitStats is the process-wide registry, seeded from the embedded JSON in init()
+var unitStats = &statsRegistry{}
+
+// LoadUnitStats replaces the base stat sheet with one read from r. JSON and
+// YAML are both accepted. Active overrides stay in place and are re-applied on
+// top of the new base. On error the registry is left unchanged.
+func LoadUnitStats(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &StatsError{Source: "<reader>", Err: err}
+	}
+	return unitStats.loadBase("<reader>", data, formatAuto)
+}
+
+// LoadUnitStatsFile is LoadUnitStats for a file on disk. The format is chosen
+// from the extension (.json, .yaml, .yml) and sniffed otherwise.
+func LoadUnitStatsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &StatsError{Source: path, Err: err}
+	}
+	return unitStats.loadBase(path, data, formatForPath(path))
+}
+
+// ApplyStatsOverride layers a partial sheet named name on top of the current
+// stats. Only the fields present in the override change. Re-applying an
+// existing name replaces that layer in place, keeping its position in the stack.
+func ApplyStatsOverride(name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &StatsError{Source: name, Err: err}
+	}
+	return unitStats.applyOverride(name, data, formatAuto)
+}
+
+// ApplyStatsOverrideFile is ApplyStatsOverride for a file on disk
+func ApplyStatsOverrideFile(name, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &StatsError{Source: name, Err: err}
+	}
+	return unitStats.applyOverride(name, data, formatForPath(path))
+}
+
+// RemoveStatsOverride drops a named override layer
+func RemoveStatsOverride(name string) error {
+	return unitStats.removeOverride(name)
+}
+
+// ActiveStatsOverrides returns the override names in the order they apply
+func ActiveStatsOverrides() []string {
+	unitStats.mu.RLock()
+	defer unitStats.mu.RUnlock()
+	names := make([]string, len(unitStats.overrides))
+	for i, layer := range unitStats.overrides {
+		names[i] = layer.name
+	}
+	return names
+}
+
+// ResetUnitStats restores the embedded sheet and drops every override. It is
+// one change: readers see the old stats or the reset ones, never a mix, and
+// on error the overrides stay in place.
+func ResetUnitStats() error {
+	return unitStats.reset("unit_stats.json (embedded)", unitStatsJSON, formatJSON)
+}
+
+// UnitStatsVersion returns the current registry version. It increases by one
+// on every successful load, override, or reset.
+func UnitStatsVersion() uint64 {
+	unitStats.mu.RLock()
+	defer unitStats.mu.RUnlock()
+	return unitStats.version
+}
+
+// LookupUnitStats returns the resolved stats for a unit type
+func LookupUnitStats(unitType UnitType) (UnitStatsData, bool) {
+	stats, _, ok := unitStats.lookup(unitType)
+	return stats, ok
+}
+
+func (sr *statsRegistry) lookup(unitType UnitType) (UnitStatsData, uint64, bool) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	stats, ok := sr.resolved[unitType.String()]
+	return stats, sr.version, ok
+}
+
+func (sr *statsRegistry) loadBase(source string, data []byte, format statsFormat) error {
+	sheet, err := decodeBaseSheet(source, data, format)
+	if err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	resolved, err := resolveSheet(source, sheet, sr.overrides)
+	if err != nil {
+		return err
+	}
+	sr.base, sr.resolved = sheet, resolved
+	sr.version++
+	return nil
+}
+
+// reset is loadBase with the override stack dropped in the same step
+func (sr *statsRegistry) reset(source string, data []byte, format statsFormat) error {
+	sheet, err := decodeBaseSheet(source, data, format)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveSheet(source, sheet, nil)
+	if err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.base, sr.overrides, sr.resolved = sheet, nil, resolved
+	sr.version++
+	return nil
+}
+
+func (sr *statsRegistry) applyOverride(name string, data []byte, format statsFormat) error {
+	if name == "" {
+		return &StatsError{Source: "<override>", Err: errors.New("override name must not be empty")}
+	}
+	sheet, err := decodeRawSheet(name, data, format)
+	if err != nil {
+		return err
+	}
+	if err := checkSheetLayer(name, sheet); err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	layers := make([]statsLayer, 0, len(sr.overrides)+1)
+	replaced := false
+	for _, layer := range sr.overrides {
+		if layer.name == name {
+			layer.sheet, replaced = sheet, true
+		}
+		layers = append(layers, layer)
+	}
+	if !replaced {
+		layers = append(layers, statsLayer{name: name, sheet: sheet})
+	}
+	resolved, err := resolveSheet(name, sr.base, layers)
+	if err != nil {
+		return err
+	}
+	sr.overrides, sr.resolved = layers, resolved
+	sr.version++
+	return nil
+}
+
+func (sr *statsRegistry) removeOverride(name string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	layers := make([]statsLayer, 0, len(sr.overrides))
+	for _, layer := range sr.overrides {
+		if layer.name != name {
+			layers = append(layers, layer)
+		}
+	}
+	if len(layers) == len(sr.overrides) {
+		return &StatsError{Source: name, Err: errors.New("no such override")}
+	}
+	resolved, err := resolveSheet(name, sr.base, layers)
+	if err != nil {
+		return err
+	}
+	sr.overrides, sr.resolved = layers, resolved
+	sr.version++
+	return nil
+}
+
+// formatForPath picks a decoder from the file extension
+func formatForPath(path string) statsFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	default:
+		return formatAuto
+	}
+}
+
+// decodeRawSheet parses JSON or YAML into an untyped sheet. Auto-detection
+// treats anything starting with '{' as JSON so JSON syntax errors keep their
+// line numbers instead of turning into confusing YAML errors.
+func decodeRawSheet(source string, data []byte, format statsFormat) (rawSheet, error) {
+	if format == formatAuto {
+		format = formatYAML
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = formatJSON
+		}
+	}
+
+	var sheet rawSheet
+	switch format {
+	case formatJSON:
+		if err := json.Unmarshal(data, &sheet); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line, col := lineAndColumn(data, syntaxErr.Offset)
+				err = fmt.Errorf("line %d, column %d: %w", line, col, err)
+			}
+			return nil, &StatsError{Source: source, Err: err}
+		}
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &sheet); err != nil {
+			return nil, &StatsError{Source: source, Err: err}
+		}
+	}
+	if len(sheet) == 0 {
+		return nil, &StatsError{Source: source, Err: errors.New("sheet is empty")}
+	}
+
+	var errs []error
+	for _, name := range sortedUnitNames(sheet) {
+		fields, err := canonicalFields(sheet[name], unitStatsType)
+		if err != nil {
+			errs = append(errs, &StatsError{Source: source, Unit: name, Err: err})
+			continue
+		}
+		sheet[name] = fields
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return sheet, nil
+}
+
+// unitStatsType is the struct a unit's fields decode into
+var unitStatsType = reflect.TypeOf(UnitStatsData{})
+
+// canonicalFields renames fields' keys to the json names of t's fields,
+// nested blocks included. encoding/json matches names without regard to
+// case, so "MaxHealth" is maxHealth to it; merging layers on the raw keys
+// would keep both and leave the winner to map order. Two spellings of one
+// field in the same block are an error. Unknown keys are left for
+// decodeStatsFields to reject.
+func canonicalFields(fields map[string]interface{}, t reflect.Type) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(fields))
+	spelled := make(map[string]string, len(fields))
+	var errs []error
+	for _, key := range sortedFieldKeys(fields) {
+		value := fields[key]
+		field, ok := jsonField(t, key)
+		if !ok {
+			out[key] = value
+			continue
+		}
+		if prev, dup := spelled[field.name]; dup {
+			errs = append(errs, fmt.Errorf("%q and %q are both %s", prev, key, field.name))
+			continue
+		}
+		spelled[field.name] = key
+		if nested, isMap := value.(map[string]interface{}); isMap && field.block != nil {
+			canon, err := canonicalFields(nested, field.block)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", field.name, err))
+				continue
+			}
+			value = canon
+		}
+		out[field.name] = value
+	}
+	return out, errors.Join(errs...)
+}
+
+// statsField is a struct field as a stat sheet names it
+type statsField struct {
+	name  string       // The json name
+	block reflect.Type // The nested struct, for blocks like groundWeapon; nil otherwise
+}
+
+// jsonField finds the field of struct t that encoding/json would decode key
+// into
+func jsonField(t reflect.Type, key string) (statsField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case !f.IsExported() || name == "-":
+			continue
+		case name == "":
+			name = f.Name
+		}
+		if !strings.EqualFold(name, key) {
+			continue
+		}
+		block := f.Type
+		if block.Kind() == reflect.Pointer {
+			block = block.Elem()
+		}
+		if block.Kind() != reflect.Struct {
+			block = nil
+		}
+		return statsField{name: name, block: block}, true
+	}
+	return statsField{}, false
+}
+
+func sortedFieldKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeBaseSheet decodes a full sheet and checks it can stand alone as the base
+func decodeBaseSheet(source string, data []byte, format statsFormat) (rawSheet, error) {
+	sheet, err := decodeRawSheet(source, data, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSheetLayer(source, sheet); err != nil {
+		return nil, err
+	}
+	if err := checkSheetComplete(source, sheet); err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// lineAndColumn converts a byte offset into a 1-based line and column
+func lineAndColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// checkSheetLayer rejects unknown unit names, unknown fields and wrong value
+// types. It runs on partial sheets too, so typos in an override are caught
+// even though the override alone isn't a complete stat line.
+func checkSheetLayer(source string, sheet rawSheet) error {
+	var errs []error
+	for _, name := range sortedUnitNames(sheet) {
+		if _, ok := unitTypeByName(name); !ok {
+			errs = append(errs, &StatsError{Source: source, Unit: name, Err: errors.New("unknown unit type")})
+			continue
+		}
+		if _, err := decodeStatsFields(sheet[name]); err != nil {
+			errs = append(errs, &StatsError{Source: source, Unit: name, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkSheetComplete makes sure a base sheet covers every unit type
+func checkSheetComplete(source string, sheet rawSheet) error {
+	var errs []error
+	for ut := SCV; ut < unitTypeCount; ut++ {
+		if _, ok := sheet[ut.String()]; !ok {
+			errs = append(errs, &StatsError{Source: source, Unit: ut.String(), Err: errors.New("missing from base sheet")})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveSheet merges the override layers over the base and validates the result
+func resolveSheet(source string, base rawSheet, layers []statsLayer) (map[string]UnitStatsData, error) {
+	merged := make(rawSheet, len(base))
+	for name, fields := range base {
+		merged[name] = mergeFields(make(map[string]interface{}, len(fields)), fields)
+	}
+	for _, layer := range layers {
+		for name, fields := range layer.sheet {
+			dst, ok := merged[name]
+			if !ok {
+				dst = make(map[string]interface{}, len(fields))
+				merged[name] = dst
+			}
+			mergeFields(dst, fields)
+		}
+	}
+
+	resolved := make(map[string]UnitStatsData, len(merged))
+	var errs []error
+	for _, name := range sortedUnitNames(merged) {
+		stats, err := decodeStatsFields(merged[name])
+		if err == nil {
+			err = stats.Validate()
+		}
+		if err != nil {
+			errs = append(errs, &StatsError{Source: source, Unit: name, Err: err})
+			continue
+		}
+		resolved[name] = stats
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return resolved, nil
+}
+
+// mergeFields copies src into dst. Nested objects merge key by key so an
+// override can change a single field of a nested block.
+func mergeFields(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		switch {
+		case srcIsMap && dstIsMap:
+			mergeFields(dstMap, srcMap)
+		case srcIsMap:
+			dst[key] = mergeFields(make(map[string]interface{}, len(srcMap)), srcMap)
+		default:
+			dst[key] = value
+		}
+	}
+	return dst
+}
+
+// decodeStatsFields turns one unit's untyped fields into UnitStatsData,
+// rejecting fields the struct doesn't know about.
+func decodeStatsFields(fields map[string]interface{}) (UnitStatsData, error) {
+	var stats UnitStatsData
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return stats, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&stats); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+func sortedUnitNames(sheet rawSheet) []string {
+	names := make([]string, 0, len(sheet))
+	for name := range sheet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unitTypeByName is the reverse of UnitType.String
+func unitTypeByName(name string) (UnitType, bool) {
+	for ut, n := range unitTypeNames {
+		if n == name {
+			return ut, true
+		}
+	}
+	return 0, false
+}
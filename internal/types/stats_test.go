@@ -0,0 +1,213 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// resetStatsAfter restores the embedded sheet when the test finishes so the
+// global registry doesn't leak between tests.
+func resetStatsAfter(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		require.NoError(t, ResetUnitStats())
+	})
+}
+
+func TestLookupUnitStats_Embedded(t *testing.T) {
+	stats, ok := LookupUnitStats(Marine)
+	require.True(t, ok)
+	require.Equal(t, 40, stats.MaxHealth)
+	require.Equal(t, "Ground", stats.ElevationLayer)
+
+	_, ok = LookupUnitStats(UnitType(999))
+	require.False(t, ok)
+}
+
+func TestLoadUnitStats_YAMLBaseSheet(t *testing.T) {
+	resetStatsAfter(t)
+
+	// Start from the embedded sheet and re-encode it as YAML so the base is complete
+	var sheet map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(unitStatsJSON, &sheet))
+	sheet["Marine"]["maxHealth"] = 45
+	doc, err := yaml.Marshal(sheet)
+	require.NoError(t, err)
+
+	before := UnitStatsVersion()
+	require.NoError(t, LoadUnitStats(strings.NewReader(string(doc))))
+	require.Equal(t, before+1, UnitStatsVersion())
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	require.Equal(t, 45, marine.GetHealth())
+	require.Equal(t, UnitStatsVersion(), marine.StatsVersion())
+
+	marine.Shutdown()
+	wg.Wait()
+}
+
+func TestLoadUnitStats_IncompleteBaseRejected(t *testing.T) {
+	resetStatsAfter(t)
+
+	before := UnitStatsVersion()
+	err := LoadUnitStats(strings.NewReader(`{"Marine": {"maxHealth": 40, "visionRange": 7, "elevationLayer": "Ground"}}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing from base sheet")
+	require.Equal(t, before, UnitStatsVersion(), "failed load must not bump the version")
+}
+
+func TestLoadUnitStats_ReportsEveryProblem(t *testing.T) {
+	resetStatsAfter(t)
+
+	err := ApplyStatsOverride("bad-patch", strings.NewReader(`
+Marine:
+  maxHealth: -5
+  elevationLayer: Space
+Zergling:
+  maxHelth: 40
+Ultralord:
+  maxHealth: 1
+`))
+	require.Error(t, err)
+
+	// Layer checks run first: the typo and the unknown unit are both reported
+	var statsErr *StatsError
+	require.True(t, errors.As(err, &statsErr))
+	require.Contains(t, err.Error(), `unknown field "maxHelth"`)
+	require.Contains(t, err.Error(), "Ultralord: unknown unit type")
+}
+
+func TestLoadUnitStats_SemanticValidation(t *testing.T) {
+	resetStatsAfter(t)
+
+	err := ApplyStatsOverride("bad-patch", strings.NewReader(`{"Marine": {"maxHealth": -5, "elevationLayer": "Space"}}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "maxHealth must be positive")
+	require.Contains(t, err.Error(), `unknown elevationLayer "Space"`)
+	require.Empty(t, ActiveStatsOverrides())
+}
+
+func TestLoadUnitStats_JSONSyntaxErrorHasLine(t *testing.T) {
+	err := LoadUnitStats(strings.NewReader("{\n  \"Marine\": {\n    \"maxHealth\": 40,,\n  }\n}"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 3")
+}
+
+func TestApplyStatsOverride_Layering(t *testing.T) {
+	resetStatsAfter(t)
+
+	require.NoError(t, ApplyStatsOverride("patch-1.16.1", strings.NewReader(`
+Marine:
+  groundWeapon:
+    damage: 7
+Zergling:
+  maxHealth: 40
+`)))
+	require.NoError(t, ApplyStatsOverride("experiment", strings.NewReader(`{"Marine": {"groundWeapon": {"damage": 8}}}`)))
+	require.Equal(t, []string{"patch-1.16.1", "experiment"}, ActiveStatsOverrides())
+
+	marine, _ := LookupUnitStats(Marine)
+	require.Equal(t, 8, marine.GroundWeapon.Damage, "later layers win")
+	require.Equal(t, 40, marine.MaxHealth, "untouched fields come from the base")
+
+	zergling, _ := LookupUnitStats(Zergling)
+	require.Equal(t, 40, zergling.MaxHealth)
+
+	require.NoError(t, RemoveStatsOverride("experiment"))
+	marine, _ = LookupUnitStats(Marine)
+	require.Equal(t, 7, marine.GroundWeapon.Damage)
+
+	require.Error(t, RemoveStatsOverride("experiment"))
+}
+
+func TestApplyStatsOverride_ReplaceKeepsPosition(t *testing.T) {
+	resetStatsAfter(t)
+
+	require.NoError(t, ApplyStatsOverride("a", strings.NewReader(`{"Marine": {"groundWeapon": {"damage": 7}}}`)))
+	require.NoError(t, ApplyStatsOverride("b", strings.NewReader(`{"Marine": {"groundWeapon": {"damage": 9}}}`)))
+	require.NoError(t, ApplyStatsOverride("a", strings.NewReader(`{"Marine": {"groundWeapon": {"damage": 8}}}`)))
+
+	require.Equal(t, []string{"a", "b"}, ActiveStatsOverrides())
+	marine, _ := LookupUnitStats(Marine)
+	require.Equal(t, 9, marine.GroundWeapon.Damage)
+}
+
+func TestApplyStatsOverride_FieldNamesIgnoreCase(t *testing.T) {
+	resetStatsAfter(t)
+
+	// encoding/json would read either spelling as maxHealth; so does the merge
+	require.NoError(t, ApplyStatsOverride("shouty", strings.NewReader(`{"Marine": {"MaxHealth": 60, "GroundWeapon": {"Damage": 9}}}`)))
+	require.NoError(t, ApplyStatsOverride("quiet", strings.NewReader("Marine:\n  maxhealth: 70\n")))
+	for i := 0; i < 20; i++ { // Map order used to pick the winner
+		marine, _ := LookupUnitStats(Marine)
+		require.Equal(t, 70, marine.MaxHealth, "the later layer wins whatever its spelling")
+		require.Equal(t, 9, marine.GroundWeapon.Damage)
+		require.Equal(t, 4, marine.GroundWeapon.Range, "the rest of the block comes from the base")
+	}
+
+	err := ApplyStatsOverride("twice", strings.NewReader(`{"Marine": {"maxHealth": 50, "MAXHEALTH": 55}}`))
+	var statsErr *StatsError
+	require.ErrorAs(t, err, &statsErr)
+	require.Equal(t, "Marine", statsErr.Unit)
+	require.ErrorContains(t, err, "both maxHealth")
+	require.Equal(t, []string{"shouty", "quiet"}, ActiveStatsOverrides(), "the bad layer isn't applied")
+}
+
+func TestResetUnitStats_OneStep(t *testing.T) {
+	resetStatsAfter(t)
+
+	require.NoError(t, ApplyStatsOverride("a", strings.NewReader(`{"Marine": {"maxHealth": 60}}`)))
+	require.NoError(t, ApplyStatsOverride("b", strings.NewReader(`{"Zergling": {"maxHealth": 50}}`)))
+	before := UnitStatsVersion()
+
+	require.NoError(t, ResetUnitStats())
+	require.Empty(t, ActiveStatsOverrides())
+	require.Equal(t, before+1, UnitStatsVersion(), "one change, one version")
+	marine, _ := LookupUnitStats(Marine)
+	require.Equal(t, 40, marine.MaxHealth)
+
+	// A reset that can't load its sheet changes nothing
+	require.NoError(t, ApplyStatsOverride("a", strings.NewReader(`{"Marine": {"maxHealth": 60}}`)))
+	before = UnitStatsVersion()
+	require.Error(t, unitStats.reset("broken", []byte(`{"Marine": {}}`), formatJSON))
+	require.Equal(t, []string{"a"}, ActiveStatsOverrides())
+	require.Equal(t, before, UnitStatsVersion())
+	marine, _ = LookupUnitStats(Marine)
+	require.Equal(t, 60, marine.MaxHealth)
+}
+
+func TestApplyStatsOverrideFile_UnitsPickUpNewVersion(t *testing.T) {
+	resetStatsAfter(t)
+
+	var wg sync.WaitGroup
+	before := NewUnit("before", Zergling, Position{}, &wg)
+
+	path := filepath.Join(t.TempDir(), "patch.yml")
+	require.NoError(t, os.WriteFile(path, []byte("Zergling:\n  maxHealth: 50\n"), 0o644))
+	require.NoError(t, ApplyStatsOverrideFile("patch", path))
+
+	after := NewUnit("after", Zergling, Position{}, &wg)
+
+	require.Equal(t, 35, before.GetHealth(), "existing units keep their stats")
+	require.Equal(t, 50, after.GetHealth())
+	require.Less(t, before.StatsVersion(), after.StatsVersion())
+
+	before.Shutdown()
+	after.Shutdown()
+	wg.Wait()
+}
+
+func TestLoadUnitStatsFile_MissingFile(t *testing.T) {
+	err := LoadUnitStatsFile(filepath.Join(t.TempDir(), "nope.json"))
+	require.Error(t, err)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,317 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// TRANSFORMATIONS - Morph, Siege and Burrow
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Some units change shape mid-game, and none of them do it instantly:
+//
+//	CmdMorph     Hydralisk → Lurker, Mutalisk → Guardian/Devourer. Takes
+//	             the new type's build time; the unit keeps its ID, owner
+//	             and position and comes out with the new type's stats.
+//	CmdSiege     Siege Tank packs into siege mode: a longer-range, harder
+//	CmdUnsiege   hitting cannon with a minimum range, and no moving.
+//	CmdBurrow    Zerg ground units dig in. A burrowing unit drops to the
+//	CmdUnburrow  Burrowed layer at once—untargetable without detection—and
+//	             stays there until it has fully come back up.
+//
+// While a transition runs the unit is Morphing or Transforming: it won't
+// fight, move, or take a new order. Shift-queued orders wait their turn
+// and start once it's done. Each transition emits EventTransforming when
+// it starts and EventTransformed when it finishes, both carrying a
+// Transition.
+//
+// A sieged or burrowed unit stays put: move-type orders are refused with
+// ErrImmobile until it unsieges or unburrows. The Lurker is the odd one
+// out—it can only attack while burrowed.
+//
+// 🎴 MTG ANALOGY: Transform and flip cards, with summoning sickness on the
+// way through. The creature is the same object afterwards (same counters,
+// same controller), it just has a different face up.
+//
+// ⚔️ SC:BW ANALOGY: Siege up before the push arrives, not when it's
+// already on top of you. Those three seconds of packing are three seconds
+// of a tank that can't shoot back.
+//
+// 🎓 LEARNING: A morph rewrites Type, which used to be immutable. It now
+// changes under u.mu like everything else, and GetType is the safe way to
+// read it from other goroutines.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Burrowing and unburrowing take a fixed time for every unit
+const (
+	BurrowTime   = 1 * time.Second
+	UnburrowTime = 500 * time.Millisecond
+)
+
+// Transformation errors. Wrapped in an *OrderError.
+var (
+	ErrCannotMorph     = errors.New("cannot morph")
+	ErrCannotTransform = errors.New("cannot transform")
+	ErrImmobile        = errors.New("unit is immobile")
+	ErrTransforming    = errors.New("unit is transforming")
+)
+
+// SiegeStatsData describes siege mode in the stat sheet
+type SiegeStatsData struct {
+	GroundWeapon   WeaponStatsData `json:"groundWeapon"`   // Replaces the tank-mode ground weapon
+	TransitionTime float64         `json:"transitionTime"` // Seconds to pack or unpack
+}
+
+// Validate checks a siege entry
+func (s SiegeStatsData) Validate() error {
+	errs := []error{s.GroundWeapon.Validate("siege.groundWeapon")}
+	if s.TransitionTime <= 0 {
+		errs = append(errs, fmt.Errorf("siege.transitionTime must be positive, got %g", s.TransitionTime))
+	}
+	return errors.Join(errs...)
+}
+
+// validateMorphs checks that every morphsInto entry names a unit type
+func validateMorphs(names []string) error {
+	var errs []error
+	for _, name := range names {
+		if _, ok := unitTypeByName(name); !ok {
+			errs = append(errs, fmt.Errorf("morphsInto: unknown unit type %q", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyTransformStats sets up morphing, burrow and siege mode. A morphed
+// unit starts unsieged with whatever its new type can do.
+func applyTransformStats(u *Unit, stats UnitStatsData) {
+	u.morphsInto = nil
+	for _, name := range stats.MorphsInto {
+		if ut, ok := unitTypeByName(name); ok {
+			u.morphsInto = append(u.morphsInto, ut)
+		}
+	}
+	u.canBurrow = stats.CanBurrow
+	u.burrowedOnly = stats.BurrowedOnly
+	u.siegeWeapon, u.siegeTime = nil, 0
+	u.tankWeapon, u.sieged = nil, false
+	if stats.Siege != nil {
+		u.siegeWeapon = newWeapon(&stats.Siege.GroundWeapon)
+		u.siegeTime = seconds(stats.Siege.TransitionTime)
+	}
+}
+
+// Transition describes a morph, siege or burrow (Data for EventTransforming
+// and EventTransformed)
+type Transition struct {
+	Order    CommandType // CmdMorph, CmdSiege, CmdUnsiege, CmdBurrow or CmdUnburrow
+	From     UnitType
+	To       UnitType // Differs from From only for CmdMorph
+	Duration time.Duration
+}
+
+func (t Transition) String() string {
+	if t.Order == CmdMorph {
+		return fmt.Sprintf("Morph %s into %s (%s)", t.From, t.To, t.Duration)
+	}
+	return fmt.Sprintf("%s %s (%s)", t.Order, t.From, t.Duration)
+}
+
+// GetType returns the unit's current type
+func (u *Unit) GetType() UnitType {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.Type
+}
+
+// IsSieged reports whether the unit is in siege mode
+func (u *Unit) IsSieged() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.sieged
+}
+
+// IsBurrowed reports whether the unit is underground (including while it
+// is still burrowing or unburrowing)
+func (u *Unit) IsBurrowed() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.elevationLayer == Burrowed
+}
+
+// CanMorphInto reports whether the unit can morph into ut
+func (u *Unit) CanMorphInto(ut UnitType) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	for _, m := range u.morphsInto {
+		if m == ut {
+			return true
+		}
+	}
+	return false
+}
+
+// busyError rejects an order given mid-transition, or returns nil
+func (u *Unit) busyError(cmd Command) error {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.state != Morphing && u.state != Transforming {
+		return nil
+	}
+	return &OrderError{UnitID: u.ID, Order: cmd.Type, Reason: fmt.Sprintf("busy: %s", u.transition), Err: ErrTransforming}
+}
+
+// immobileError refuses orders that need a sieged or burrowed unit to
+// move, or returns nil
+func (u *Unit) immobileError(order CommandType) error {
+	switch order {
+	case CmdMove, CmdPatrol, CmdAttackMove, CmdRepair, CmdBuild:
+	default:
+		return nil
+	}
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	switch {
+	case u.sieged:
+		return &OrderError{UnitID: u.ID, Order: order, Reason: "sieged", Err: ErrImmobile}
+	case u.elevationLayer == Burrowed:
+		return &OrderError{UnitID: u.ID, Order: order, Reason: "burrowed", Err: ErrImmobile}
+	}
+	return nil
+}
+
+// isImmobile reports whether the unit is rooted to the spot
+func (u *Unit) isImmobile() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.sieged || u.elevationLayer == Burrowed
+}
+
+// handleMorph starts morphing into cmd.Build. The morph takes as long as
+// building the new type from scratch.
+func (u *Unit) handleMorph(cmd Command) error {
+	cannot := func(reason string) error {
+		return &OrderError{UnitID: u.ID, Order: CmdMorph, Reason: reason, Err: ErrCannotMorph}
+	}
+	from := u.GetType()
+	if !u.CanMorphInto(cmd.Build) {
+		return cannot(fmt.Sprintf("%s can't morph into %s", from, cmd.Build))
+	}
+	if u.IsBurrowed() {
+		return cannot("burrowed")
+	}
+	buildTime := 0.0
+	if stats, ok := LookupUnitStats(cmd.Build); ok {
+		buildTime = stats.BuildTime
+	}
+	u.startTransition(Transition{Order: CmdMorph, From: from, To: cmd.Build, Duration: seconds(buildTime)}, Morphing)
+	return nil
+}
+
+// handleSiege packs a Siege Tank into siege mode
+func (u *Unit) handleSiege(cmd Command) error {
+	u.mu.RLock()
+	siegeWeapon, siegeTime, sieged, ut := u.siegeWeapon, u.siegeTime, u.sieged, u.Type
+	u.mu.RUnlock()
+	switch {
+	case siegeWeapon == nil:
+		return &OrderError{UnitID: u.ID, Order: CmdSiege, Reason: fmt.Sprintf("%s has no siege mode", ut), Err: ErrCannotTransform}
+	case sieged:
+		return &OrderError{UnitID: u.ID, Order: CmdSiege, Reason: "already sieged", Err: ErrCannotTransform}
+	}
+	u.startTransition(Transition{Order: CmdSiege, From: ut, To: ut, Duration: siegeTime}, Transforming)
+	return nil
+}
+
+// handleUnsiege switches a sieged tank back to tank mode
+func (u *Unit) handleUnsiege(cmd Command) error {
+	u.mu.RLock()
+	siegeTime, sieged, ut := u.siegeTime, u.sieged, u.Type
+	u.mu.RUnlock()
+	if !sieged {
+		return &OrderError{UnitID: u.ID, Order: CmdUnsiege, Reason: "not sieged", Err: ErrCannotTransform}
+	}
+	u.startTransition(Transition{Order: CmdUnsiege, From: ut, To: ut, Duration: siegeTime}, Transforming)
+	return nil
+}
+
+// handleBurrow digs the unit in
+func (u *Unit) handleBurrow(cmd Command) error {
+	u.mu.RLock()
+	canBurrow, burrowed, ut := u.canBurrow, u.elevationLayer == Burrowed, u.Type
+	u.mu.RUnlock()
+	switch {
+	case !canBurrow:
+		return &OrderError{UnitID: u.ID, Order: CmdBurrow, Reason: fmt.Sprintf("%s can't burrow", ut), Err: ErrCannotTransform}
+	case burrowed:
+		return &OrderError{UnitID: u.ID, Order: CmdBurrow, Reason: "already burrowed", Err: ErrCannotTransform}
+	}
+	u.startTransition(Transition{Order: CmdBurrow, From: ut, To: ut, Duration: BurrowTime}, Transforming)
+	return nil
+}
+
+// handleUnburrow brings a burrowed unit back up
+func (u *Unit) handleUnburrow(cmd Command) error {
+	u.mu.RLock()
+	burrowed, ut := u.elevationLayer == Burrowed, u.Type
+	u.mu.RUnlock()
+	if !burrowed {
+		return &OrderError{UnitID: u.ID, Order: CmdUnburrow, Reason: "not burrowed", Err: ErrCannotTransform}
+	}
+	u.startTransition(Transition{Order: CmdUnburrow, From: ut, To: ut, Duration: UnburrowTime}, Transforming)
+	return nil
+}
+
+// startTransition stops whatever the unit was doing and begins t. A
+// burrowing unit goes underground straight away.
+func (u *Unit) startTransition(t Transition, state UnitState) {
+	now := u.clock.Now()
+	u.mu.Lock()
+	u.state = state
+	u.target = nil
+	u.velocity = 0
+	u.transition = t
+	u.transitionEnd = now.Add(t.Duration)
+	if t.Order == CmdBurrow {
+		u.elevationLayer = Burrowed
+	}
+	u.mu.Unlock()
+
+	u.emit(UnitEvent{Type: EventTransforming, Source: u, Timestamp: now, Data: t})
+}
+
+// continueTransition finishes the transition once its time is up, then
+// moves on to the next queued order
+func (u *Unit) continueTransition(now time.Time) {
+	u.mu.Lock()
+	if now.Before(u.transitionEnd) {
+		u.mu.Unlock()
+		return
+	}
+	t := u.transition
+	switch t.Order {
+	case CmdMorph:
+		u.Type = t.To
+		initializeStats(u, t.To)
+	case CmdSiege:
+		u.tankWeapon, u.groundWeapon = u.groundWeapon, u.siegeWeapon
+		u.sieged = true
+	case CmdUnsiege:
+		u.groundWeapon, u.tankWeapon = u.tankWeapon, nil
+		u.sieged = false
+	case CmdUnburrow:
+		u.elevationLayer = Ground
+	}
+	u.transition = Transition{}
+	vision, world := float64(u.visionRange), u.world
+	u.mu.Unlock()
+
+	if world != nil {
+		world.noteVision(vision) // A morph can see further than its larva
+	}
+	u.emit(UnitEvent{Type: EventTransformed, Source: u, Timestamp: now, Data: t})
+	u.orderDone(now)
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sendOrder sends cmd and returns the unit's answer
+func sendOrder(t *testing.T, u *Unit, cmd Command) error {
+	t.Helper()
+	result := make(chan error, 1)
+	cmd.Result = result
+	require.NoError(t, u.SendCommand(cmd))
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s never answered %s", u.ID, cmd)
+		return nil
+	}
+}
+
+func TestMorph_HydraliskToLurker(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	hydra := NewUnit("hydra", Hydralisk, Position{}, &wg, WithClock(clock))
+	ling := NewUnit("ling", Zergling, Position{X: 3}, &wg, WithClock(clock))
+	sub := hydra.Subscribe(EventTypes(EventTransforming, EventTransformed), WithBuffer(16))
+
+	require.ErrorIs(t, sendOrder(t, hydra, Command{Type: CmdMorph, Build: Guardian}), ErrCannotMorph)
+	require.ErrorIs(t, sendOrder(t, ling, Command{Type: CmdMorph, Build: Lurker}), ErrCannotMorph)
+
+	require.NoError(t, sendOrder(t, hydra, Command{Type: CmdMorph, Build: Lurker}))
+	ev := waitForEvent(t, sub, EventTransforming)
+	require.Equal(t, Transition{Order: CmdMorph, From: Hydralisk, To: Lurker, Duration: 25 * time.Second}, ev.Data)
+	require.Equal(t, Morphing, hydra.GetState())
+
+	// Inside the egg: no new orders, but queued ones wait their turn
+	err := sendOrder(t, hydra, Command{Type: CmdMove, Dest: Position{X: 5}})
+	require.ErrorIs(t, err, ErrTransforming)
+	require.ErrorContains(t, err, "Morph Hydralisk into Lurker")
+	require.NoError(t, hydra.SendCommand(Command{Type: CmdBurrow, Queue: true}))
+	require.Eventually(t, func() bool { return len(hydra.PendingOrders()) == 1 }, time.Second, time.Millisecond)
+
+	clock.Advance(24 * time.Second)
+	require.Equal(t, Hydralisk, hydra.GetType())
+
+	clock.Advance(time.Second + TickRate)
+	ev = waitForEvent(t, sub, EventTransformed)
+	require.Equal(t, Lurker, ev.Data.(Transition).To)
+	require.Equal(t, Lurker, hydra.GetType())
+	require.Equal(t, 125, hydra.GetMaxHealth())
+	require.Equal(t, 125, hydra.GetHealth(), "a fresh Lurker")
+
+	// The queued burrow starts straight away
+	ev = waitForEvent(t, sub, EventTransforming)
+	require.Equal(t, CmdBurrow, ev.Data.(Transition).Order)
+	require.True(t, hydra.IsBurrowed())
+	clock.Advance(BurrowTime + TickRate)
+	waitForEvent(t, sub, EventTransformed)
+	require.Eventually(t, func() bool { return hydra.GetState() == Idle }, time.Second, time.Millisecond)
+
+	_, err = hydra.WeaponAgainst(ling)
+	require.NoError(t, err, "Lurkers attack from underground")
+
+	hydra.Shutdown()
+	ling.Shutdown()
+	wg.Wait()
+}
+
+func TestSiege(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	tank := NewUnit("tank", SiegeTank, Position{}, &wg, WithClock(clock))
+	near := NewUnit("near", Zealot, Position{X: 2}, &wg, WithClock(clock))
+	far := NewUnit("far", Dragoon, Position{X: 10}, &wg, WithClock(clock))
+	marine := NewUnit("marine", Marine, Position{X: 30}, &wg, WithClock(clock))
+	sub := tank.Subscribe(EventTypes(EventTransformed), WithBuffer(16))
+
+	require.ErrorIs(t, sendOrder(t, tank, Command{Type: CmdUnsiege}), ErrCannotTransform)
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdSiege}), ErrCannotTransform)
+
+	require.NoError(t, sendOrder(t, tank, Command{Type: CmdSiege}))
+	require.Equal(t, Transforming, tank.GetState())
+	clock.Advance(2 * time.Second)
+	require.False(t, tank.IsSieged(), "still packing")
+	clock.Advance(time.Second + TickRate)
+	waitForEvent(t, sub, EventTransformed)
+	require.True(t, tank.IsSieged())
+
+	cannon, ok := tank.GetGroundWeapon()
+	require.True(t, ok)
+	require.Equal(t, 70, cannon.Damage)
+	require.Equal(t, 12, cannon.Range)
+	require.Equal(t, 2, cannon.MinRange)
+
+	require.ErrorIs(t, sendOrder(t, tank, Command{Type: CmdMove, Dest: Position{X: 5}}), ErrImmobile)
+	require.ErrorIs(t, sendOrder(t, tank, Command{Type: CmdSiege}), ErrCannotTransform)
+	err := sendOrder(t, tank, Command{Type: CmdAttack, Target: near})
+	require.ErrorIs(t, err, ErrOutOfRange)
+	require.ErrorContains(t, err, "inside minimum range 2")
+	require.ErrorIs(t, sendOrder(t, tank, Command{Type: CmdAttack, Target: marine}), ErrTargetNotVisible)
+	require.NoError(t, sendOrder(t, tank, Command{Type: CmdAttack, Target: far}))
+
+	require.NoError(t, sendOrder(t, tank, Command{Type: CmdUnsiege}))
+	clock.Advance(3*time.Second + TickRate)
+	waitForEvent(t, sub, EventTransformed)
+	require.False(t, tank.IsSieged())
+	gun, _ := tank.GetGroundWeapon()
+	require.Equal(t, 30, gun.Damage)
+	require.Equal(t, 0, gun.MinRange)
+
+	for _, u := range []*Unit{tank, near, far, marine} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestBurrow(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	ling := NewUnit("ling", Zergling, Position{}, &wg, WithClock(clock))
+	marine := NewUnit("marine", Marine, Position{X: 2}, &wg, WithClock(clock))
+	lurker := NewUnit("lurker", Lurker, Position{X: 4}, &wg, WithClock(clock))
+	sub := ling.Subscribe(EventTypes(EventTransformed), WithBuffer(16))
+
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdBurrow}), ErrCannotTransform)
+	require.ErrorIs(t, sendOrder(t, ling, Command{Type: CmdUnburrow}), ErrCannotTransform)
+	_, err := lurker.WeaponAgainst(marine)
+	require.ErrorIs(t, err, ErrCannotTarget)
+	require.ErrorContains(t, err, "must burrow to attack")
+
+	// Untargetable from the moment it starts digging
+	require.NoError(t, sendOrder(t, ling, Command{Type: CmdBurrow}))
+	require.Equal(t, Burrowed, ling.GetElevationLayer())
+	require.ErrorIs(t, marine.CanTarget(ling), ErrCannotTarget)
+	clock.Advance(BurrowTime + TickRate)
+	waitForEvent(t, sub, EventTransformed)
+	require.Eventually(t, func() bool { return ling.GetState() == Idle }, time.Second, time.Millisecond)
+
+	_, err = ling.WeaponAgainst(marine)
+	require.ErrorContains(t, err, "can't attack while burrowed")
+	require.ErrorIs(t, sendOrder(t, ling, Command{Type: CmdAttackMove, Dest: Position{X: 9}}), ErrImmobile)
+	require.NoError(t, sendOrder(t, ling, Command{Type: CmdHold}), "holding still is fine")
+
+	// ...and stays that way until it's all the way up
+	require.NoError(t, sendOrder(t, ling, Command{Type: CmdUnburrow}))
+	clock.Advance(UnburrowTime / 2)
+	require.True(t, ling.IsBurrowed())
+	clock.Advance(UnburrowTime)
+	waitForEvent(t, sub, EventTransformed)
+	require.Equal(t, Ground, ling.GetElevationLayer())
+	require.NoError(t, marine.CanTarget(ling))
+
+	for _, u := range []*Unit{ling, marine, lurker} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestTransformStats_Validate(t *testing.T) {
+	stats, ok := LookupUnitStats(SiegeTank)
+	require.True(t, ok)
+	stats.Siege = &SiegeStatsData{GroundWeapon: WeaponStatsData{Damage: 70, DamageType: "Explosive", Range: 2, MinRange: 2}}
+	stats.MorphsInto = []string{"Ultralisk", "Baneling"}
+
+	err := stats.Validate()
+	require.ErrorContains(t, err, "siege.groundWeapon.minRange must be between 0 and range, got 2")
+	require.ErrorContains(t, err, "siege.transitionTime must be positive")
+	require.ErrorContains(t, err, `morphsInto: unknown unit type "Baneling"`)
+	require.NotContains(t, err.Error(), "Ultralisk")
+
+	require.Equal(t, "Morph Hydralisk into Lurker (25s)", Transition{Order: CmdMorph, From: Hydralisk, To: Lurker, Duration: 25 * time.Second}.String())
+	require.Equal(t, "Siege SiegeTank (3s)", Transition{Order: CmdSiege, From: SiegeTank, To: SiegeTank, Duration: 3 * time.Second}.String())
+	require.Equal(t, "Morph into Lurker", Command{Type: CmdMorph, Build: Lurker}.String())
+}
@@ -0,0 +1,392 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// TRANSPORTS - Drops, Shuttles and Overlords
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// A transport carries its own side's ground units, of its own race, up to
+// its cargo capacity. Passengers take room by size:
+//
+//	Small   1 slot    Marine, Zergling, Zealot
+//	Medium  2 slots   Vulture, Hydralisk, Dragoon
+//	Large   4 slots   Siege Tank, Ultralisk, Reaver
+//
+// Loading is a handshake between two goroutines:
+//
+//  1. The transport gets CmdLoad (Target: the passenger) and flies over
+//     until it's within LoadRange.
+//  2. It sends the passenger CmdLoad (Target: the transport). The
+//     passenger climbs in on its own goroutine, so nothing else it's doing
+//     can race with boarding.
+//  3. The transport emits EventLoaded and moves on to its next order.
+//
+// Aboard, a passenger is Loaded: it refuses every order, can't be targeted,
+// doesn't see, and rides along at the transport's position. CmdUnload
+// drops one passenger (or everyone) where the transport is—over walkable
+// ground only. If the transport dies, everyone aboard dies with it.
+//
+// 🎴 MTG ANALOGY: Exile-until-it-leaves. The cards under the transport
+// aren't on the battlefield—no targeting, no blocking—and if it dies
+// before you get them back, they're gone.
+//
+// ⚔️ SC:BW ANALOGY: Two High Templar in a Shuttle, dropped on top of the
+// Hydralisk ball. Or a full Dropship of Marines meeting a Valkyrie on the
+// way in—eight EventDied at once.
+//
+// 🎓 LEARNING: Each unit's state belongs to its own goroutine. The
+// transport never flips a passenger to Loaded from outside; it asks with
+// a command and waits for the answer on the Result channel. Unloading and
+// dying don't need a handshake: a Loaded passenger's goroutine does
+// nothing, so there's nobody to race with.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// LoadRange is how close (edge to edge) a transport must be to pick up a
+// passenger
+const LoadRange = 1.0
+
+// Transport errors. Wrapped in an *OrderError.
+var (
+	ErrCannotLoad   = errors.New("cannot load")
+	ErrCannotUnload = errors.New("cannot unload")
+	ErrInTransport  = errors.New("unit is in a transport")
+)
+
+// unitSizeSlots is how many cargo slots a passenger of each size takes
+var unitSizeSlots = [unitSizeCount]int{
+	Small:  1,
+	Medium: 2,
+	Large:  4,
+}
+
+// CargoSlots returns how much room a unit of this size takes in a transport
+func (us UnitSize) CargoSlots() int {
+	if !us.IsValid() {
+		return 0
+	}
+	return unitSizeSlots[us]
+}
+
+// IsLoaded reports whether the unit is riding in a transport
+func (u *Unit) IsLoaded() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.carrier != nil
+}
+
+// GetTransport returns the transport the unit is riding in, nil if none
+func (u *Unit) GetTransport() *Unit {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.carrier
+}
+
+// GetCargo returns the passengers aboard, in boarding order
+func (u *Unit) GetCargo() []*Unit {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return append([]*Unit(nil), u.cargo...)
+}
+
+// CargoSpace returns the cargo slots in use and the total; capacity is 0
+// for units that aren't transports
+func (u *Unit) CargoSpace() (used, capacity int) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.cargoUsed, u.cargoCapacity
+}
+
+// loadedError refuses orders for a unit that's aboard a transport, or
+// returns nil
+func (u *Unit) loadedError(cmd Command) error {
+	carrier := u.GetTransport()
+	if carrier == nil {
+		return nil
+	}
+	return &OrderError{UnitID: u.ID, Order: cmd.Type, Reason: fmt.Sprintf("aboard %s", carrier.ID), Err: ErrInTransport}
+}
+
+// loadError explains why this transport can't take p aboard, or returns nil
+func (u *Unit) loadError(p *Unit) error {
+	cannot := func(format string, args ...interface{}) error {
+		return &OrderError{UnitID: u.ID, Order: CmdLoad, Reason: fmt.Sprintf(format, args...), Err: ErrCannotLoad}
+	}
+	u.mu.RLock()
+	used, capacity, ut := u.cargoUsed, u.cargoCapacity, u.Type
+	u.mu.RUnlock()
+
+	switch {
+	case capacity == 0:
+		return cannot("%s is not a transport", ut)
+	case p == nil:
+		return &TargetingError{UnitID: u.ID, Err: ErrNoTarget}
+	case p == u:
+		return cannot("can't load itself")
+	case p.IsDead():
+		return &TargetingError{UnitID: u.ID, TargetID: p.ID, Layer: p.GetElevationLayer(), Reason: "target is dead", Err: ErrUnitDead}
+	case p.owner != u.owner:
+		return cannot("%s belongs to another player", p.ID)
+	case p.GetType().Race() != ut.Race():
+		return cannot("%s only carries %s units", ut, ut.Race())
+	case p.GetElevationLayer() == Air:
+		return cannot("can't load air units")
+	case p.isImmobile():
+		return cannot("%s is sieged or burrowed", p.ID)
+	case p.GetTransport() == u:
+		return cannot("%s is already aboard", p.ID)
+	case p.IsLoaded():
+		return cannot("%s is in another transport", p.ID)
+	}
+	if need := p.GetSize().CargoSlots(); used+need > capacity {
+		return cannot("%s needs %d slots, %d free", p.ID, need, capacity-used)
+	}
+	return nil
+}
+
+// inReach reports whether other is within reach edge to edge
+func (u *Unit) inReach(other *Unit, reach float64) bool {
+	reach += u.GetSize().Radius() + other.GetSize().Radius()
+	return u.GetPosition().DistanceSq(other.GetPosition()) <= reach*reach
+}
+
+// handleLoad is two orders in one. Sent to a transport, it goes to pick up
+// cmd.Target. Sent to anything else with a transport as cmd.Target, the
+// unit climbs aboard—that's the transport's side of the handshake.
+func (u *Unit) handleLoad(cmd Command) error {
+	u.mu.RLock()
+	transport := u.cargoCapacity > 0
+	u.mu.RUnlock()
+	if !transport {
+		return u.board(cmd.Target)
+	}
+	if err := u.loadError(cmd.Target); err != nil {
+		return err
+	}
+
+	now := u.clock.Now()
+	u.mu.Lock()
+	if u.state != Moving {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Loading
+	u.target = cmd.Target
+	u.boarding = nil
+	u.mu.Unlock()
+	return nil
+}
+
+// continueLoad flies to the passenger, asks it aboard and waits for its
+// answer. The order fails with EventOrderFailed if the passenger can no
+// longer be loaded (it died, left, or there's no room any more).
+func (u *Unit) continueLoad(now time.Time) {
+	p := u.GetTarget()
+	u.mu.RLock()
+	boarding := u.boarding
+	u.mu.RUnlock()
+
+	if boarding != nil {
+		select {
+		case err := <-boarding:
+			u.mu.Lock()
+			u.boarding = nil
+			u.mu.Unlock()
+			if err != nil {
+				u.failCargo(p, err, now)
+				return
+			}
+			u.orderDone(now)
+		default:
+			// Still climbing in
+		}
+		return
+	}
+
+	if err := u.loadError(p); err != nil {
+		u.failCargo(p, err, now)
+		return
+	}
+	if !u.inReach(p, LoadRange) {
+		if err := u.approach(p.GetPosition(), now); err != nil {
+			u.failCargo(p, err, now)
+		}
+		return
+	}
+	u.halt(now)
+
+	result := make(chan error, 1)
+	if err := p.SendCommand(Command{Type: CmdLoad, Target: u, Result: result}); err != nil {
+		u.failCargo(p, err, now)
+		return
+	}
+	u.mu.Lock()
+	u.boarding = result
+	u.mu.Unlock()
+}
+
+// failCargo abandons a load or unload, explaining why on the event stream
+func (u *Unit) failCargo(p *Unit, err error, now time.Time) {
+	u.emit(UnitEvent{Type: EventOrderFailed, Source: u, Target: p, Timestamp: now, Data: err})
+	u.orderDone(now)
+}
+
+// board puts u aboard transport t. Runs on u's goroutine.
+func (u *Unit) board(t *Unit) error {
+	switch {
+	case t == nil:
+		return &TargetingError{UnitID: u.ID, Err: ErrNoTarget}
+	case !u.inReach(t, LoadRange):
+		return &TargetingError{UnitID: u.ID, TargetID: t.ID, Layer: t.GetElevationLayer(), Reason: "transport is too far away", Err: ErrOutOfRange}
+	}
+	if err := t.loadError(u); err != nil {
+		return err
+	}
+	if err := t.addCargo(u); err != nil {
+		return err
+	}
+
+	u.cancelOrders(ErrInTransport)
+	now := u.clock.Now()
+	pos := t.GetPosition()
+	u.mu.Lock()
+	u.carrier = t
+	u.state = Loaded
+	u.target = nil
+	u.velocity = 0
+	u.position = pos
+	u.mu.Unlock()
+
+	t.emit(UnitEvent{Type: EventLoaded, Source: t, Target: u, Timestamp: now})
+	return nil
+}
+
+// addCargo makes room for p, unless someone else took it first
+func (u *Unit) addCargo(p *Unit) error {
+	need := p.GetSize().CargoSlots()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.cargoUsed+need > u.cargoCapacity {
+		return &OrderError{UnitID: u.ID, Order: CmdLoad, Reason: fmt.Sprintf("%s needs %d slots, %d free", p.ID, need, u.cargoCapacity-u.cargoUsed), Err: ErrCannotLoad}
+	}
+	u.cargo = append(u.cargo, p)
+	u.cargoUsed += need
+	return nil
+}
+
+// handleUnload drops off cmd.Target, or everyone aboard if it's nil
+func (u *Unit) handleUnload(cmd Command) error {
+	cannot := func(reason string) error {
+		return &OrderError{UnitID: u.ID, Order: CmdUnload, Reason: reason, Err: ErrCannotUnload}
+	}
+	u.mu.RLock()
+	capacity, aboard, ut := u.cargoCapacity, len(u.cargo), u.Type
+	u.mu.RUnlock()
+	switch {
+	case capacity == 0:
+		return cannot(fmt.Sprintf("%s is not a transport", ut))
+	case cmd.Target != nil && cmd.Target.GetTransport() != u:
+		return cannot(fmt.Sprintf("%s is not aboard", cmd.Target.ID))
+	case aboard == 0:
+		return cannot("nothing aboard")
+	}
+
+	now := u.clock.Now()
+	u.mu.Lock()
+	u.state = Unloading
+	u.target = cmd.Target
+	u.velocity = 0
+	u.lastStep = now
+	u.mu.Unlock()
+	return nil
+}
+
+// continueUnload puts the passengers down at the transport's position,
+// one EventUnloaded each. Nobody gets off over ground they can't stand on.
+func (u *Unit) continueUnload(now time.Time) {
+	pos := u.GetPosition()
+	u.mu.RLock()
+	only, world := u.order.Target, u.world
+	u.mu.RUnlock()
+
+	if world != nil && world.terrain != nil && !world.terrain.IsWalkable(pos, Ground) {
+		tt, _ := world.terrain.TerrainAt(pos)
+		err := &OrderError{UnitID: u.ID, Order: CmdUnload, Reason: fmt.Sprintf("can't unload over %s", tt), Err: ErrCannotUnload}
+		u.failCargo(only, err, now)
+		return
+	}
+
+	for _, p := range u.removeCargo(only) {
+		p.mu.Lock()
+		p.carrier = nil
+		p.state = Idle
+		p.order = Command{}
+		p.position = pos
+		p.lastStep = now
+		p.mu.Unlock()
+		u.emit(UnitEvent{Type: EventUnloaded, Source: u, Target: p, Timestamp: now})
+	}
+	u.orderDone(now)
+}
+
+// removeCargo takes only (or everyone, if nil) off the cargo list
+func (u *Unit) removeCargo(only *Unit) []*Unit {
+	u.mu.Lock()
+	var removed, kept []*Unit
+	for _, p := range u.cargo {
+		if only == nil || p == only {
+			removed = append(removed, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	u.cargo = kept
+	u.mu.Unlock()
+
+	freed := 0
+	for _, p := range removed {
+		freed += p.GetSize().CargoSlots()
+	}
+	u.mu.Lock()
+	u.cargoUsed -= freed
+	u.mu.Unlock()
+	return removed
+}
+
+// carryCargo keeps the passengers at the transport's position
+func (u *Unit) carryCargo() {
+	u.mu.RLock()
+	if len(u.cargo) == 0 {
+		u.mu.RUnlock()
+		return
+	}
+	pos := u.position
+	cargo := append([]*Unit(nil), u.cargo...)
+	u.mu.RUnlock()
+
+	for _, p := range cargo {
+		p.SetPosition(pos)
+	}
+}
+
+// sinkCargo kills everyone aboard a transport that just died. The
+// transport's killer is credited with each of them.
+func (u *Unit) sinkCargo(killer *Unit, now time.Time) {
+	for _, p := range u.removeCargo(nil) {
+		p.mu.Lock()
+		if p.state == Dead {
+			p.mu.Unlock()
+			continue
+		}
+		p.health, p.shields = 0, 0
+		p.state = Dead
+		p.carrier = nil
+		p.mu.Unlock()
+		p.die(killer, now)
+	}
+}
@@ -0,0 +1,188 @@
+package types
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// advanceUntil steps clock a tick at a time until cond holds
+func advanceUntil(t *testing.T, clock *ManualClock, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		clock.Advance(TickRate)
+		deadline := time.Now().Add(5 * time.Millisecond)
+		for time.Now().Before(deadline) {
+			if cond() {
+				return
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}
+	t.Fatal("condition never held")
+}
+
+func TestUnitSize_CargoSlots(t *testing.T) {
+	require.Equal(t, 1, Small.CargoSlots())
+	require.Equal(t, 2, Medium.CargoSlots())
+	require.Equal(t, 4, Large.CargoSlots())
+	require.Equal(t, 0, unitSizeCount.CargoSlots())
+}
+
+func TestTransport_LoadCarryUnload(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+
+	var wg sync.WaitGroup
+	drop := NewUnit("drop", DropShip, Position{}, &wg, WithClock(clock), WithOwner(red))
+	marine := NewUnit("marine", Marine, Position{X: 4}, &wg, WithClock(clock), WithOwner(red))
+	hydra := NewUnit("hydra", Hydralisk, Position{X: 6}, &wg, WithClock(clock), WithOwner(blue))
+	events := drop.Subscribe(EventTypes(EventLoaded, EventUnloaded), WithBuffer(16))
+
+	require.NoError(t, sendOrder(t, drop, Command{Type: CmdLoad, Target: marine}))
+	require.Equal(t, Loading, drop.GetState())
+	advanceUntil(t, clock, marine.IsLoaded)
+	ev := waitForEvent(t, events, EventLoaded)
+	require.Equal(t, marine, ev.Target)
+	require.Equal(t, drop, marine.GetTransport())
+	require.Equal(t, Loaded, marine.GetState())
+	require.Equal(t, []*Unit{marine}, drop.GetCargo())
+	used, capacity := drop.CargoSpace()
+	require.Equal(t, 1, used)
+	require.Equal(t, 8, capacity)
+	advanceUntil(t, clock, func() bool { return drop.GetState() == Idle })
+
+	// Off the battlefield: no orders, no targeting
+	err := sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 9}})
+	require.ErrorIs(t, err, ErrInTransport)
+	require.ErrorContains(t, err, "aboard drop")
+	require.ErrorIs(t, sendOrder(t, hydra, Command{Type: CmdAttack, Target: marine}), ErrCannotTarget)
+
+	// Along for the ride, then off at the destination
+	require.NoError(t, sendOrder(t, drop, Command{Type: CmdMove, Dest: Position{X: 10, Y: 10}}))
+	require.NoError(t, drop.SendCommand(Command{Type: CmdUnload, Queue: true}))
+	advanceUntil(t, clock, func() bool { return !marine.IsLoaded() })
+	ev = waitForEvent(t, events, EventUnloaded)
+	require.Equal(t, marine, ev.Target)
+	require.Equal(t, Position{X: 10, Y: 10}, marine.GetPosition())
+	require.Equal(t, Idle, marine.GetState())
+	require.Empty(t, drop.GetCargo())
+	used, _ = drop.CargoSpace()
+	require.Zero(t, used)
+	require.NoError(t, sendOrder(t, marine, Command{Type: CmdMove, Dest: Position{X: 9}}))
+
+	for _, u := range []*Unit{drop, marine, hydra} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestTransport_LoadErrors(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Bisu", Terran, Blue)
+
+	var wg sync.WaitGroup
+	opts := []UnitOption{WithClock(clock), WithOwner(red)}
+	drop := NewUnit("drop", DropShip, Position{}, &wg, opts...)
+	tank1 := NewUnit("tank1", SiegeTank, Position{X: 1}, &wg, opts...)
+	tank2 := NewUnit("tank2", SiegeTank, Position{Y: 1}, &wg, opts...)
+	marine := NewUnit("marine", Marine, Position{X: -1}, &wg, opts...)
+	wraith := NewUnit("wraith", Wraith, Position{X: 1, Y: 1}, &wg, opts...)
+	ling := NewUnit("ling", Zergling, Position{X: -1, Y: -1}, &wg, opts...)
+	enemy := NewUnit("enemy", Marine, Position{Y: -1}, &wg, WithClock(clock), WithOwner(blue))
+	units := []*Unit{drop, tank1, tank2, marine, wraith, ling, enemy}
+
+	load := func(p *Unit) error {
+		return sendOrder(t, drop, Command{Type: CmdLoad, Target: p})
+	}
+	require.ErrorContains(t, load(wraith), "can't load air units")
+	require.ErrorContains(t, load(enemy), "belongs to another player")
+	require.ErrorContains(t, load(ling), "DropShip only carries Terran units")
+	require.ErrorIs(t, load(nil), ErrNoTarget)
+	require.ErrorIs(t, sendOrder(t, marine, Command{Type: CmdLoad, Target: tank1}), ErrCannotLoad, "a tank is no transport")
+	require.ErrorIs(t, sendOrder(t, drop, Command{Type: CmdUnload}), ErrCannotUnload, "nothing aboard")
+
+	// Two tanks fill a Dropship
+	require.NoError(t, load(tank1))
+	require.NoError(t, drop.SendCommand(Command{Type: CmdLoad, Target: tank2, Queue: true}))
+	advanceUntil(t, clock, func() bool { return tank2.IsLoaded() && drop.GetState() == Idle })
+	used, _ := drop.CargoSpace()
+	require.Equal(t, 8, used)
+	err := load(marine)
+	require.ErrorIs(t, err, ErrCannotLoad)
+	require.ErrorContains(t, err, "marine needs 1 slots, 0 free")
+	require.ErrorContains(t, load(tank1), "already aboard")
+	require.ErrorIs(t, sendOrder(t, drop, Command{Type: CmdUnload, Target: marine}), ErrCannotUnload)
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestTransport_CargoDiesWithTransport(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+
+	var wg sync.WaitGroup
+	shuttle := NewUnit("shuttle", Shuttle, Position{}, &wg, WithClock(clock), WithOwner(blue))
+	zealot := NewUnit("zealot", Zealot, Position{X: 1}, &wg, WithClock(clock), WithOwner(blue))
+	reaver := NewUnit("reaver", Reaver, Position{Y: 1}, &wg, WithClock(clock), WithOwner(blue))
+	var deaths []*Subscription
+	for _, p := range []*Unit{zealot, reaver} {
+		deaths = append(deaths, p.Subscribe(EventTypes(EventDied), WithBuffer(4)))
+		require.NoError(t, sendOrder(t, shuttle, Command{Type: CmdLoad, Target: p}))
+		advanceUntil(t, clock, func() bool { return p.IsLoaded() && shuttle.GetState() == Idle })
+	}
+
+	shuttle.TakeDamage(1000)
+	require.True(t, shuttle.IsDead())
+	for i, p := range []*Unit{zealot, reaver} {
+		waitForEvent(t, deaths[i], EventDied)
+		require.True(t, p.IsDead())
+		require.False(t, p.IsLoaded())
+		require.Zero(t, p.GetHealth())
+	}
+	require.Empty(t, shuttle.GetCargo())
+
+	for _, u := range []*Unit{shuttle, zealot, reaver} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestTransport_NoUnloadOverWater(t *testing.T) {
+	m, err := ParseMapASCII("lake", strings.NewReader("..~~\n"))
+	require.NoError(t, err)
+	world := NewWorld(m, 1)
+	clock := NewManualClock(clockStart)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	var wg sync.WaitGroup
+	overlord := NewUnit("overlord", Overlord, Position{X: 2.5, Y: 0.5}, &wg, WithClock(clock), WithOwner(teal))
+	ling := NewUnit("ling", Zergling, Position{X: 1.5, Y: 0.5}, &wg, WithClock(clock), WithOwner(teal))
+	world.Add(overlord)
+	world.Add(ling)
+	failed := overlord.Subscribe(EventTypes(EventOrderFailed), WithBuffer(4))
+
+	require.NoError(t, sendOrder(t, overlord, Command{Type: CmdLoad, Target: ling}))
+	advanceUntil(t, clock, func() bool { return ling.IsLoaded() && overlord.GetState() == Idle })
+	require.False(t, world.sees(ling, ling.GetPosition()), "passengers see nothing")
+
+	require.NoError(t, sendOrder(t, overlord, Command{Type: CmdUnload}))
+	clock.Advance(TickRate)
+	ev := waitForEvent(t, failed, EventOrderFailed)
+	require.ErrorIs(t, ev.Data.(error), ErrCannotUnload)
+	require.ErrorContains(t, ev.Data.(error), "can't unload over Water")
+	require.True(t, ling.IsLoaded())
+
+	for _, u := range []*Unit{overlord, ling} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
@@ -3,7 +3,6 @@ package types
 import (
 	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
 	"log"
 	"math"
@@ -37,23 +36,41 @@ var unitStatsJSON []byte
 
 // UnitStatsData holds the parsed unit stats for fast lookup
 type UnitStatsData struct {
-	MaxHealth      int    `json:"maxHealth"`
-	BaseDamage     int    `json:"baseDamage"`
-	BaseArmor      int    `json:"baseArmor"`
-	ArmorModifier  int    `json:"armorModifier"`
-	AttackModifier int    `json:"attackModifier"`
-	AttackRange    int    `json:"attackRange"`
-	VisionRange    int    `json:"visionRange"`
-	ElevationLayer string `json:"elevationLayer"`
+	MaxHealth      int              `json:"maxHealth"`
+	Shields        *ShieldStatsData `json:"shields,omitempty"`     // nil = no shields (non-Protoss)
+	HealthRegen    float64          `json:"healthRegen,omitempty"` // HP per second (Zerg)
+	Energy         *EnergyStatsData `json:"energy,omitempty"`      // nil = not a caster
+	BaseArmor      int              `json:"baseArmor"`
+	ArmorModifier  int              `json:"armorModifier"`
+	AttackModifier int              `json:"attackModifier"`
+	VisionRange    int              `json:"visionRange"`
+	ElevationLayer string           `json:"elevationLayer"`
+	UnitSize       string           `json:"unitSize"`
+	Speed          float64          `json:"speed"`                   // Top speed, tiles per second
+	Acceleration   float64          `json:"acceleration"`            // Tiles per second², 0 = instant top speed
+	BuildTime      float64          `json:"buildTime"`               // Seconds to build; also sets repair speed
+	Mechanical     bool             `json:"mechanical,omitempty"`    // Can be repaired
+	Worker         bool             `json:"worker,omitempty"`        // Can build
+	CanRepair      bool             `json:"canRepair,omitempty"`     // Can repair mechanical units
+	GroundWeapon   *WeaponStatsData `json:"groundWeapon,omitempty"`  // nil = can't attack ground
+	AirWeapon      *WeaponStatsData `json:"airWeapon,omitempty"`     // nil = can't attack air
+	Ammo           *AmmoStatsData   `json:"ammo,omitempty"`          // nil = unlimited ammo
+	MorphsInto     []string         `json:"morphsInto,omitempty"`    // Unit types this one can morph into
+	CanBurrow      bool             `json:"canBurrow,omitempty"`     // Can burrow (Zerg ground units)
+	BurrowedOnly   bool             `json:"burrowedOnly,omitempty"`  // Attacks only while burrowed (Lurker)
+	Siege          *SiegeStatsData  `json:"siege,omitempty"`         // nil = no siege mode
+	CargoCapacity  int              `json:"cargoCapacity,omitempty"` // Transport slots (0 = not a transport)
+	Cloaked        bool             `json:"cloaked,omitempty"`       // Permanently cloaked
+	CanCloak       bool             `json:"canCloak,omitempty"`      // Can cloak on demand, paying energy
+	Detector       bool             `json:"detector,omitempty"`      // Detects cloaked and burrowed units
+	Abilities      []string         `json:"abilities,omitempty"`     // Spells this unit can cast (see abilities.go)
 }
 
-// unitStatsCache is populated once at startup from the embedded JSON
-var unitStatsCache map[string]UnitStatsData
-
 // init runs once when the package is loaded
-// Parses the embedded JSON into our cache for fast runtime lookups
+// Seeds the stats registry (see stats.go) with the embedded sheet. The
+// embedded file ships with the binary, so a failure here is a build bug.
 func init() {
-	if err := json.Unmarshal(unitStatsJSON, &unitStatsCache); err != nil {
+	if err := unitStats.loadBase("unit_stats.json (embedded)", unitStatsJSON, formatJSON); err != nil {
 		log.Fatalf("Failed to load unit stats: %v", err)
 	}
 }
@@ -243,6 +260,12 @@ const (
 	Patrolling
 	Repairing
 	Building
+	Morphing     // Zerg egg/cocoon: no orders until the morph finishes (see transform.go)
+	Transforming // Sieging, unsieging, burrowing or unburrowing
+	Loading      // Transport: flying over to pick up a passenger (see transport.go)
+	Unloading    // Transport: dropping passengers off
+	Loaded       // Passenger: riding in a transport
+	Casting      // Closing in to cast a spell (see abilities.go)
 	Dead
 
 	unitStateCount
@@ -259,6 +282,12 @@ var unitStateNames = map[UnitState]string{
 	Patrolling:      "Patrolling",
 	Repairing:       "Repairing",
 	Building:        "Building",
+	Morphing:        "Morphing",
+	Transforming:    "Transforming",
+	Loading:         "Loading",
+	Unloading:       "Unloading",
+	Loaded:          "Loaded",
+	Casting:         "Casting",
 	Dead:            "Dead",
 }
 
@@ -438,37 +467,103 @@ type Unit struct {
 	// ═══════════════════════════════════════════════════════════════════════
 	// IMMUTABLE FIELDS (Never change after creation → No mutex needed!)
 	// ═══════════════════════════════════════════════════════════════════════
-	ID   string   // Unique identifier, never changes
-	Type UnitType // Marine stays Marine, can't morph (unless you're Zerg!)
+	ID    string   // Unique identifier, never changes
+	Type  UnitType // Marine stays Marine; a Zerg morph changes it under mu, so read it with GetType
+	owner *Faction // Who controls this unit (nil = neutral); see faction.go
 
 	// ═══════════════════════════════════════════════════════════════════════
 	// MUTABLE STATE (Protected by mutex—multiple goroutines access this!)
 	// ═══════════════════════════════════════════════════════════════════════
-	mu             sync.RWMutex // Protects ALL fields below
-	health         int          // Current health (0 = dead)
-	maxHealth      int          // Maximum health
-	baseDamage     int          // Attack damage
+	mu             sync.RWMutex  // Protects ALL fields below
+	statsVersion   uint64        // Stats registry version this unit was built (or morphed) from
+	health         float64       // Current health (0 = dead), fractional thanks to MinimumDamage
+	maxHealth      int           // Maximum health
+	healthRegen    float64       // HP per second (Zerg)
+	shields        float64       // Current shields; damage hits these first
+	maxShields     int           // 0 = no shields
+	shieldArmor    int           // Armor against hits on shields
+	shieldRegen    float64       // Shield points per second
+	shieldDelay    time.Duration // Shields wait this long after a hit before regenerating
+	energy         float64       // Current energy for spells
+	maxEnergy      int           // 0 = not a caster
+	energyRegen    float64       // Energy per second
+	lastDamaged    time.Time     // When the unit last took damage
+	lastRegen      time.Time     // When regeneration last ran
+	groundWeapon   *Weapon       // Anti-ground weapon (nil if none)
+	airWeapon      *Weapon       // Anti-air weapon (nil if none)
+	size           UnitSize      // Small, Medium, Large
 	baseArmor      int
 	attackModifier int
 	armorModifier  int
-	attackUpgrades int
-	armorUpgrades  int
-	attackRange    int
 	visionRange    int
 	state          UnitState      // Current state (Idle, Moving, etc.)
 	elevationLayer ElevationLayer // Current Elevation (Burrowed, Flying, Ground)
 	position       Position       // Current position
 	target         *Unit          // Currently attacking this unit (nil if none)
+	cooldownUntil  time.Time      // Weapons can't fire again before this
+	rolls          uint64         // Random rolls made so far (see World.rollMiss)
+	ammo           *magazine      // Limited ammo (Reaver, Carrier); nil = unlimited
+	speed          float64        // Top speed, tiles per second
+	acceleration   float64        // Tiles per second², 0 = instant
+	velocity       float64        // Current speed along the path
+	destination    Position       // Where the current move order is headed
+	lastStep       time.Time      // When the move last advanced
+	lastMoveEvent  time.Time      // When the last EventMoved progress event went out
+	buildTime      float64        // Seconds to build this unit type
+	mechanical     bool           // Can be repaired
+	worker         bool           // Can build
+	canRepair      bool           // Can repair mechanical units
+	order          Command        // The order being carried out (zero when Idle)
+	orders         []Command      // Shift-queued orders waiting their turn
+	scanner        TargetScanner  // Finds targets for attack-move and patrol (nil = none)
+	world          *World         // Terrain, vision and miss rolls (nil = on its own); see world.go
+	patrolFrom     Position       // Patrol leg start
+	patrolTo       Position       // Patrol leg end (where the unit is headed)
+	workStarted    time.Time      // When construction began (zero while walking to the site)
+	workDuration   time.Duration  // How long the current construction takes
+	builtCount     int            // Units this worker has built (for their IDs)
+	lastWork       time.Time      // When repair/build progress was last applied
+	morphsInto     []UnitType     // What this unit can morph into (Zerg)
+	canBurrow      bool           // Can dig in (Zerg ground units)
+	burrowedOnly   bool           // Attacks only while burrowed (Lurker)
+	siegeWeapon    *Weapon        // Ground weapon in siege mode (nil = no siege mode)
+	siegeTime      time.Duration  // How long packing or unpacking siege mode takes
+	tankWeapon     *Weapon        // The tank-mode ground weapon, stashed while sieged
+	sieged         bool           // In siege mode
+	transition     Transition     // The morph/siege/burrow in progress (Morphing/Transforming)
+	transitionEnd  time.Time      // When the transition completes
+	cargoCapacity  int            // Cargo slots (0 = not a transport)
+	cargo          []*Unit        // Passengers aboard, in boarding order
+	cargoUsed      int            // Cargo slots taken
+	boarding       chan error     // The passenger's answer while it climbs aboard (nil = not asked yet)
+	carrier        *Unit          // The transport this unit rides in (nil = not loaded)
+	cloaked        bool           // Hidden until detected (see cloak.go)
+	permanentCloak bool           // Always cloaked (Dark Templar, Observer)
+	canCloak       bool           // Can cloak on demand (Wraith)
+	detector       bool           // Detects hidden units within vision range
+
+	// Spells (see abilities.go and spells.go)
+	abilities   []AbilityType             // Spells this unit knows
+	castReady   map[AbilityType]time.Time // When each spell comes off cooldown
+	effects     map[AbilityType]*effect   // Spells working on this unit
+	lastEffects time.Time                 // When damage over time was last dealt
 
 	// ═══════════════════════════════════════════════════════════════════════
 	// CONCURRENCY PRIMITIVES (Channels, Context, Coordination)
 	// ═══════════════════════════════════════════════════════════════════════
 	commands     chan Command       // Receives commands (attack, move, etc.)
-	events       chan UnitEvent     // Sends events (took damage, killed unit, etc.)
+	sendMu       sync.RWMutex       // Read-held by senders; held by whoever closes or drains commands
 	ctx          context.Context    // For cancellation/shutdown
 	cancel       context.CancelFunc // Call this to stop the unit's goroutine
 	wg           *sync.WaitGroup    // For coordinated shutdown
 	shutdownOnce sync.Once          // Ensures Shutdown only executes once
+	clock        Clock              // Source of time (see clock.go); set once in NewUnit
+
+	// Event fan-out (see events.go)
+	pubMu      sync.Mutex      // Protects the fields below; held while publishing
+	subs       []*Subscription // Current subscribers
+	eventSeq   uint64          // Seq of the last published event
+	subsClosed bool            // The unit stopped; no new subscribers
 }
 
 type Tile struct {
@@ -515,7 +610,7 @@ type Tile struct {
 // "In Zerg, I queue up commands for my units (attack, move, attack). They
 //
 //	execute them in order. Buffered channel = command queue. Makes sense?"
-func NewUnit(id string, unitType UnitType, pos Position, wg *sync.WaitGroup) *Unit {
+func NewUnit(id string, unitType UnitType, pos Position, wg *sync.WaitGroup, opts ...UnitOption) *Unit {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	u := &Unit{
@@ -525,17 +620,25 @@ func NewUnit(id string, unitType UnitType, pos Position, wg *sync.WaitGroup) *Un
 		state:    Idle,
 
 		commands: make(chan Command, 10),
-		events:   make(chan UnitEvent, 10),
 
 		ctx:    ctx,
 		cancel: cancel,
 		wg:     wg,
+		clock:  SystemClock{},
 	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	u.lastRegen = u.clock.Now()
+	u.lastEffects = u.lastRegen
 
 	initializeStats(u, unitType)
 
+	// The ticker exists before NewUnit returns, so a ManualClock advanced
+	// right afterwards already drives this unit
+	ticker := u.clock.NewTicker(TickRate)
 	wg.Add(1)
-	go u.run()
+	go u.run(ticker)
 
 	return u
 }
@@ -570,45 +673,66 @@ func NewUnit(id string, unitType UnitType, pos Position, wg *sync.WaitGroup) *Un
 // - Memory: ~5KB for all 34 unit types
 // - I/O: ZERO (file embedded at compile time)
 func initializeStats(u *Unit, unitType UnitType) {
-	// Lookup stats from our cache (populated by init() at startup)
-	stats, ok := unitStatsCache[unitType.String()]
+	// Lookup stats from the registry (seeded by init(), reloadable at runtime)
+	stats, version, ok := unitStats.lookup(unitType)
+	u.statsVersion = version
 	if !ok {
 		// Fallback for unknown unit types (defensive programming)
 		log.Printf("Warning: Unknown unit type %s, using default stats", unitType)
 		stats = UnitStatsData{
 			MaxHealth:      50,
-			BaseDamage:     5,
 			BaseArmor:      0,
 			ArmorModifier:  1,
 			AttackModifier: 0,
-			AttackRange:    4,
 			VisionRange:    7,
 			ElevationLayer: "Ground",
+			UnitSize:       "Small",
+			Speed:          3,
+			BuildTime:      20,
+			GroundWeapon:   &WeaponStatsData{Damage: 5, DamageType: "Normal", Range: 4},
 		}
 	}
 
 	// Apply stats from cache
-	u.maxHealth, u.health = stats.MaxHealth, stats.MaxHealth
-	u.baseDamage = stats.BaseDamage
+	u.maxHealth, u.health = stats.MaxHealth, float64(stats.MaxHealth)
+	u.healthRegen = stats.HealthRegen
+	applyShieldStats(u, stats.Shields)
+	applyEnergyStats(u, stats.Energy)
+	u.groundWeapon = newWeapon(stats.GroundWeapon)
+	u.airWeapon = newWeapon(stats.AirWeapon)
+	u.ammo = newMagazine(stats.Ammo)
 	u.baseArmor = stats.BaseArmor
 	u.armorModifier = stats.ArmorModifier
 	u.attackModifier = stats.AttackModifier
-	u.armorUpgrades = 0
-	u.attackUpgrades = 0
-	u.attackRange = stats.AttackRange
 	u.visionRange = stats.VisionRange
-
-	// Parse elevation layer string to enum
-	switch stats.ElevationLayer {
-	case "Ground":
-		u.elevationLayer = Ground
-	case "Air":
-		u.elevationLayer = Air
-	case "Burrowed":
-		u.elevationLayer = Burrowed
-	default:
+	u.speed = stats.Speed
+	u.acceleration = stats.Acceleration
+	u.buildTime = stats.BuildTime
+	u.mechanical = stats.Mechanical
+	u.worker = stats.Worker
+	u.canRepair = stats.CanRepair
+	applyTransformStats(u, stats)
+	u.cargoCapacity = stats.CargoCapacity
+	applyCloakStats(u, stats)
+	applyAbilityStats(u, stats)
+
+	// Parse enum strings (Validate already rejected unknown spellings)
+	if layer, ok := parseElevationLayer(stats.ElevationLayer); ok {
+		u.elevationLayer = layer
+	} else {
 		u.elevationLayer = Ground
 	}
+	u.size, _ = parseUnitSize(stats.UnitSize)
+}
+
+// parseElevationLayer maps the stat sheet spelling to the enum
+func parseElevationLayer(name string) (ElevationLayer, bool) {
+	for layer, n := range elevationLayerNames {
+		if n == name {
+			return layer, true
+		}
+	}
+	return Ground, false
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -635,8 +759,58 @@ const (
 	CmdAttack
 	CmdStop
 	CmdHold
+	CmdPatrol     // Walk back and forth between here and Dest, engaging on the way
+	CmdRepair     // Repair Target (mechanical) back to full health
+	CmdBuild      // Walk to Dest and build a unit of type Build there
+	CmdAttackMove // Walk to Dest, fighting anything found on the way
+	CmdMorph      // Zerg: morph into a unit of type Build (see transform.go)
+	CmdSiege      // Siege Tank: pack into siege mode
+	CmdUnsiege    // Siege Tank: back to tank mode
+	CmdBurrow     // Zerg ground units: dig in
+	CmdUnburrow   // Come back up
+	CmdLoad       // Transport: pick up Target (see transport.go)
+	CmdUnload     // Transport: drop off Target, or everyone if Target is nil
+	CmdCloak      // Wraith: cloak (see cloak.go); doesn't interrupt the current order
+	CmdDecloak    // Wraith: drop the cloak
+	CmdCast       // Cast Ability on Target or at Dest (see abilities.go)
+
+	commandTypeCount
 )
 
+var _ fmt.Stringer = CommandType(0)
+
+var commandTypeNames = map[CommandType]string{
+	CmdMove:       "Move",
+	CmdAttack:     "Attack",
+	CmdStop:       "Stop",
+	CmdHold:       "Hold",
+	CmdPatrol:     "Patrol",
+	CmdRepair:     "Repair",
+	CmdBuild:      "Build",
+	CmdAttackMove: "AttackMove",
+	CmdMorph:      "Morph",
+	CmdSiege:      "Siege",
+	CmdUnsiege:    "Unsiege",
+	CmdBurrow:     "Burrow",
+	CmdUnburrow:   "Unburrow",
+	CmdLoad:       "Load",
+	CmdUnload:     "Unload",
+	CmdCloak:      "Cloak",
+	CmdDecloak:    "Decloak",
+	CmdCast:       "Cast",
+}
+
+func (ct CommandType) String() string {
+	if name, ok := commandTypeNames[ct]; ok {
+		return name
+	}
+	return fmt.Sprintf("CommandType(%d)", ct)
+}
+
+func (ct CommandType) IsValid() bool {
+	return ct >= CmdMove && ct < commandTypeCount
+}
+
 // Command represents an order sent to a unit
 //
 // 🤔 DESIGN QUESTION: Why not just use methods like unit.Attack(target)?
@@ -644,25 +818,72 @@ const (
 //
 //	Methods = synchronous, blocking. Big difference in concurrent systems.
 type Command struct {
-	Type   CommandType
-	Target *Unit    // For attack commands
-	Dest   Position // For move commands
+	Type    CommandType
+	Target  *Unit        // For attack, repair, load and unload commands
+	Dest    Position     // For move, patrol, attack-move and build commands
+	Build   UnitType     // For build and morph commands
+	Ability AbilityType  // For cast commands
+	Queue   bool         // Shift-click: append after the current orders instead of replacing them
+	Result  chan<- error // Optional: receives the outcome once the unit starts the order (nil = success)
+}
+
+// reply reports the handler's outcome on Result. Never blocks the unit—give
+// Result a buffer of 1 if you want to be sure to see the answer.
+func (c Command) reply(err error) {
+	if c.Result == nil {
+		return
+	}
+	select {
+	case c.Result <- err:
+	default:
+	}
 }
 
 func (c Command) String() string {
+	if c.Queue {
+		return "Queue " + Command{Type: c.Type, Target: c.Target, Dest: c.Dest, Build: c.Build, Ability: c.Ability}.String()
+	}
+	targetID := "nil"
+	if c.Target != nil {
+		targetID = c.Target.ID
+	}
 	switch c.Type {
 	case CmdMove:
 		return fmt.Sprintf("Move to (%.1f, %.1f)", c.Dest.X, c.Dest.Y)
 	case CmdAttack:
-		targetID := "nil"
-		if c.Target != nil {
-			targetID = c.Target.ID
-		}
 		return fmt.Sprintf("Attack %s", targetID)
 	case CmdStop:
 		return "Stop"
 	case CmdHold:
 		return "Hold Position"
+	case CmdPatrol:
+		return fmt.Sprintf("Patrol to (%.1f, %.1f)", c.Dest.X, c.Dest.Y)
+	case CmdRepair:
+		return fmt.Sprintf("Repair %s", targetID)
+	case CmdBuild:
+		return fmt.Sprintf("Build %s at (%.1f, %.1f)", c.Build, c.Dest.X, c.Dest.Y)
+	case CmdAttackMove:
+		return fmt.Sprintf("Attack-move to (%.1f, %.1f)", c.Dest.X, c.Dest.Y)
+	case CmdMorph:
+		return fmt.Sprintf("Morph into %s", c.Build)
+	case CmdSiege, CmdUnsiege, CmdBurrow, CmdUnburrow, CmdCloak, CmdDecloak:
+		return c.Type.String()
+	case CmdLoad:
+		return fmt.Sprintf("Load %s", targetID)
+	case CmdUnload:
+		if c.Target == nil {
+			return "Unload all"
+		}
+		return fmt.Sprintf("Unload %s", targetID)
+	case CmdCast:
+		switch spell, _ := LookupAbility(c.Ability); spell.Targeting {
+		case TargetUnit:
+			return fmt.Sprintf("Cast %s on %s", c.Ability, targetID)
+		case TargetPoint:
+			return fmt.Sprintf("Cast %s at (%.1f, %.1f)", c.Ability, c.Dest.X, c.Dest.Y)
+		default:
+			return fmt.Sprintf("Cast %s", c.Ability)
+		}
 	default:
 		return fmt.Sprintf("Command(%d)", c.Type)
 
@@ -676,8 +897,21 @@ const (
 	EventDamaged UnitEventType = iota
 	EventKilled                // This unit killed another unit
 	EventDied                  // This unit died
-	EventMoved
+	EventMoved                 // Move progress, sent periodically and on arrival (Data: MoveProgress)
 	EventIdle
+	EventReloaded     // A magazine round finished rebuilding (Data: rounds now loaded)
+	EventOrderFailed  // The current order was abandoned (Data: error explaining why)
+	EventBuilt        // A build order finished (Target and Data: the new *Unit)
+	EventMissed       // An attack missed (Target: the unit shot at, Data: the Weapon)
+	EventTransforming // A morph, siege or burrow began (Data: Transition)
+	EventTransformed  // ...and finished (Data: Transition)
+	EventLoaded       // A transport picked up a passenger (Target: the passenger)
+	EventUnloaded     // A transport dropped a passenger off (Target: the passenger)
+	EventCloaked      // The unit cloaked
+	EventDecloaked    // The cloak dropped (Data: ErrNotEnoughEnergy if it ran out, else nil)
+	EventCast         // A spell went off (Target: the unit aimed at, if any; Data: Cast)
+	EventEffectBegan  // A spell landed on this unit (Target: the caster; Data: AbilityType)
+	EventEffectEnded  // A lasting spell wore off this unit (Data: AbilityType)
 )
 
 // UnitEvent represents something that happened to/by a unit
@@ -685,6 +919,7 @@ const (
 // 🎴 MTG: Like triggered abilities ("When this creature deals damage...")
 // ⚔️ SC:BW: Like game events (unit completed, unit died, etc.)
 type UnitEvent struct {
+	Seq       uint64 // Per-unit, starts at 1, +1 per published event (gaps = missed events)
 	Type      UnitEventType
 	Source    *Unit // Unit that generated this event
 	Target    *Unit // For events involving another unit
@@ -696,7 +931,16 @@ type UnitEvent struct {
 // UNIT METHODS
 // ═══════════════════════════════════════════════════════════════════════════
 
+// GetHealth returns health as the HUD shows it: rounded up, so a unit
+// hanging on at 0.5 HP still reads 1.
 func (u *Unit) GetHealth() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return int(math.Ceil(u.health))
+}
+
+// GetHealthExact returns health including the fractional part
+func (u *Unit) GetHealthExact() float64 {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
 	return u.health
@@ -705,16 +949,38 @@ func (u *Unit) GetHealth() int {
 func (u *Unit) GetDamage() int {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.baseDamage + u.attackModifier*u.attackUpgrades
+	weapon := u.primaryWeapon()
+	if weapon == nil {
+		return 0
+	}
+	return (weapon.Damage + u.attackModifier*u.attackUpgradesLocked()) * weapon.Hits
 }
 
 func (u *Unit) GetArmor() int {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	totalArmor := u.baseArmor + u.armorModifier*u.armorUpgrades
+	totalArmor := u.baseArmor + u.armorModifier*u.armorUpgradesLocked()
 	return totalArmor
 }
 
+// GetDamageType returns the damage type of the unit's primary weapon
+// (ground first, then air). Normal for units without a weapon.
+func (u *Unit) GetDamageType() DamageType {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if weapon := u.primaryWeapon(); weapon != nil {
+		return weapon.DamageType
+	}
+	return NormalDamage
+}
+
+// GetSize returns the unit's size class for the damage matrix
+func (u *Unit) GetSize() UnitSize {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.size
+}
+
 func (u *Unit) GetState() UnitState {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
@@ -727,22 +993,42 @@ func (u *Unit) GetPosition() Position {
 	return u.position
 }
 
+// StatsVersion returns the stats registry version this unit was built from.
+// Compare against UnitStatsVersion() to spot units created before a reload.
+func (u *Unit) StatsVersion() uint64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.statsVersion
+}
+
 func (u *Unit) GetTarget() *Unit {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
 	return u.target
 }
 
+// SetState changes the unit's state. Dead is final: once a unit has died,
+// nothing brings it back.
 func (u *Unit) SetState(state UnitState) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
+	if u.state == Dead {
+		return
+	}
 	u.state = state
 }
 
+// SetPosition puts the unit at position straight away. No EventMoved is
+// sent, so the world's spatial index is told directly.
 func (u *Unit) SetPosition(position Position) {
 	u.mu.Lock()
-	defer u.mu.Unlock()
 	u.position = position
+	world := u.world
+	u.mu.Unlock()
+
+	if world != nil {
+		world.spatial.Refresh(u)
+	}
 }
 
 func (u *Unit) SetTarget(target *Unit) {
@@ -751,19 +1037,29 @@ func (u *Unit) SetTarget(target *Unit) {
 	u.target = target
 }
 
-func (u *Unit) TakeDamage(amount int) int {
-	u.mu.Lock()
-	defer u.mu.Unlock()
-	u.health -= amount
-	if u.health < 0 {
-		u.health = 0
+// TakeDamage deals amount straight through—no armor, no damage type—with no
+// attacker to credit. Shields soak it first. Returns the health left;
+// reaching zero kills the unit (see death.go).
+func (u *Unit) TakeDamage(amount float64) float64 {
+	now := u.clock.Now()
+	_, remaining, killed := u.takeHits(amount, SpellDamage, 1, now)
+	if killed {
+		u.die(nil, now)
 	}
-
-	return u.health
+	return remaining
 }
 
-func (u *Unit) run() {
+// TickRate is how often a unit advances its current order (moving, firing,
+// reloading). Roughly one Brood War frame on Fastest speed.
+const TickRate = 42 * time.Millisecond
+
+func (u *Unit) run(ticker Ticker) {
 	defer u.wg.Done()
+	defer u.closeSubscriptions()
+	defer u.stopOrders()
+	defer u.rejectPending() // Whatever got buffered before the loop stopped
+
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -771,28 +1067,79 @@ func (u *Unit) run() {
 			if !ok {
 				return
 			}
-			switch cmd.Type {
-			case CmdMove:
-				u.handleMove(cmd)
-			case CmdAttack:
-				u.handleAttack(cmd)
-			case CmdStop:
-				u.handleStop(cmd)
-			case CmdHold:
-				u.handleHold(cmd)
-			}
+			u.receive(cmd)
 			fmt.Printf("[%s] %s\n", u.ID, cmd)
+		case now := <-ticker.C():
+			u.tick(now)
 		case <-u.ctx.Done():
 			return
 		}
 	}
 }
 
+// tick advances whatever the unit is doing by one simulation step
+func (u *Unit) tick(now time.Time) {
+	if u.GetState() == Dead {
+		return
+	}
+	if u.tickEffects(now) || u.IsDead() {
+		return
+	}
+	u.drainCloak(now)
+	u.regenerate(now)
+	u.reload(now)
+	defer u.carryCargo()
+	switch u.GetState() {
+	case Moving:
+		if u.currentOrder() == CmdAttackMove && u.scanForTarget(now) {
+			return
+		}
+		u.advanceMove(now)
+	case Patrolling:
+		if u.scanForTarget(now) {
+			return
+		}
+		u.advancePatrol(now)
+	case Attacking:
+		u.continueAttack(now)
+	case Repairing:
+		u.continueRepair(now)
+	case Building:
+		u.continueBuild(now)
+	case Morphing, Transforming:
+		u.continueTransition(now)
+	case Loading:
+		u.continueLoad(now)
+	case Unloading:
+		u.continueUnload(now)
+	case Casting:
+		u.continueCast(now)
+	}
+}
+
+// SendCommand queues an order for the unit. Refusals are a *CommandError:
+// dead units wrap ErrUnitDead, shut-down ones ErrShuttingDown. A full queue
+// gets 100ms (on the unit's clock) to make room before the command is
+// refused with ErrBackpressure.
 func (u *Unit) SendCommand(cmd Command) error {
+	// The check and the send happen under sendMu, so Shutdown can't close
+	// the channel and the loop can't drain it for the last time in between
+	u.sendMu.RLock()
+	defer u.sendMu.RUnlock()
+
 	// Check context first to avoid sending on closed channel
 	select {
 	case <-u.ctx.Done():
-		return fmt.Errorf("unit is shutting down")
+		return u.stoppedError(cmd)
+	default:
+	}
+
+	// Room in the queue? Then no timer is needed at all
+	select {
+	case u.commands <- cmd:
+		return nil
+	case <-u.ctx.Done():
+		return u.stoppedError(cmd)
 	default:
 	}
 
@@ -801,9 +1148,9 @@ func (u *Unit) SendCommand(cmd Command) error {
 	case u.commands <- cmd:
 		return nil
 	case <-u.ctx.Done():
-		return fmt.Errorf("unit is shutting down")
-	case <-time.After(100 * time.Millisecond):
-		return fmt.Errorf("command queue full - backpressure")
+		return u.stoppedError(cmd)
+	case <-u.clock.After(100 * time.Millisecond):
+		return &CommandError{UnitID: u.ID, Command: cmd, Err: ErrBackpressure}
 	}
 }
 
@@ -811,45 +1158,164 @@ func (u *Unit) Shutdown() {
 	// Use sync.Once to ensure this only runs once, even if called multiple times
 	u.shutdownOnce.Do(func() {
 		u.cancel()
+		// Senders hold sendMu while they send; once they've seen the
+		// cancel, none will try again
+		u.sendMu.Lock()
 		close(u.commands)
+		u.sendMu.Unlock()
 	})
 }
 
-func (u *Unit) handleMove(cmd Command) {
-	u.SetState(Moving)
-	u.SetPosition(cmd.Dest)
-	moveEvent := UnitEvent{Type: EventMoved, Source: u, Target: nil, Timestamp: time.Now()}
-	u.events <- moveEvent
+// handleMove starts (or redirects) a move order; tick does the walking.
+// See movement.go.
+func (u *Unit) handleMove(cmd Command) error {
+	if err := u.destinationError(cmd.Type, cmd.Dest); err != nil {
+		return err
+	}
+	u.startMove(cmd.Dest, u.clock.Now())
+	return nil
 }
 
-func (u *Unit) handleAttack(cmd Command) {
+// handleAttack starts an attack order on cmd.Target. A target already in
+// range takes the first volley right away; otherwise tick walks the unit
+// toward it (see continueAttack). Sieged and burrowed units can't walk, so
+// for them a target out of range is rejected. The order is rejected (state untouched)
+// with a *TargetingError if no weapon fits the target's layer or the target
+// can't be seen.
+func (u *Unit) handleAttack(cmd Command) error {
 	if cmd.Target == nil {
 		fmt.Printf("[%s] Attack command has no target!\n", u.ID)
+	}
+	if err := u.attackError(cmd.Target); err != nil {
+		return err
+	}
+	if u.isImmobile() {
+		// A sieged or burrowed unit can't walk into range
+		if err := u.CheckRange(cmd.Target); err != nil {
+			return err
+		}
+	}
+	u.engage(cmd.Target, u.clock.Now())
+	return nil
+}
+
+// attackError explains why target can't be attacked right now, or returns nil
+func (u *Unit) attackError(target *Unit) error {
+	if target == nil {
+		return &TargetingError{UnitID: u.ID, Err: ErrNoTarget}
+	}
+	if target.GetState() == Dead {
+		return &TargetingError{UnitID: u.ID, TargetID: target.ID, Layer: target.GetElevationLayer(), Reason: "target is dead", Err: ErrUnitDead}
+	}
+	if target.IsLoaded() {
+		return &TargetingError{UnitID: u.ID, TargetID: target.ID, Layer: target.GetElevationLayer(), Reason: "target is inside a transport", Err: ErrCannotTarget}
+	}
+	if _, err := u.WeaponAgainst(target); err != nil {
+		return err
+	}
+	return u.checkVision(target)
+}
+
+// engage switches the unit to Attacking target, firing at once if in range.
+// A unit that was already on the move keeps its momentum for the approach.
+func (u *Unit) engage(target *Unit, now time.Time) {
+	u.mu.Lock()
+	if u.state != Moving && u.state != Patrolling {
+		u.velocity = 0
+		u.lastStep = now
+	}
+	u.state = Attacking
+	u.target = target
+	u.mu.Unlock()
+
+	weapon, err := u.WeaponAgainst(target)
+	if err == nil && u.rangeError(weapon, target) == nil {
+		u.halt(now)
+		u.tryFire(weapon, target, now)
+	}
+}
+
+// continueAttack fires again whenever the weapon is ready, closing the
+// distance first if the target is out of range. The fight ends once the
+// target is dead; it fails with EventOrderFailed if the target can no
+// longer be targeted or drops out of sight.
+func (u *Unit) continueAttack(now time.Time) {
+	target := u.GetTarget()
+	if target == nil || target.GetHealthExact() <= 0 {
+		u.endAttack(now)
+		return
+	}
+	weapon, err := u.WeaponAgainst(target)
+	if err != nil {
+		u.failAttack(target, err)
+		return
+	}
+	if err := u.checkVision(target); err != nil {
+		u.failAttack(target, err)
+		return
+	}
+	if err := u.rangeError(weapon, target); err != nil {
+		if u.isImmobile() {
+			u.failAttack(target, err)
+			return
+		}
+		if err := u.approach(target.GetPosition(), now); err != nil {
+			u.failAttack(target, err)
+		}
 		return
 	}
-	u.SetState(Attacking)
-	u.SetTarget(cmd.Target)
-	dmg := u.CalculateDamageAgainst(cmd.Target)
-	cmd.Target.TakeDamage(dmg)
-	attackEvent := UnitEvent{Type: EventDamaged, Source: u, Target: cmd.Target, Timestamp: time.Now()}
-	u.events <- attackEvent
+	u.halt(now)
+	u.tryFire(weapon, target, now)
+}
+
+// failAttack abandons the fight, explaining why on the event stream
+func (u *Unit) failAttack(target *Unit, err error) {
+	now := u.clock.Now()
+	u.emit(UnitEvent{Type: EventOrderFailed, Source: u, Target: target, Timestamp: now, Data: err})
+	u.endAttack(now)
 }
 
-func (u *Unit) handleStop(cmd Command) {
-	u.SetState(Idle)
-	u.SetTarget(nil)
+// endAttack finishes a fight. Attack-move and patrol pick up where they
+// left off; a plain attack order is done (see orderDone).
+func (u *Unit) endAttack(now time.Time) {
+	if u.resumeTravel(now) {
+		return
+	}
+	u.orderDone(now)
 }
 
-func (u *Unit) handleHold(cmd Command) {
-	u.SetState(HoldingPosition)
+func (u *Unit) handleStop(cmd Command) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state == Dead {
+		return nil
+	}
+	u.state = Idle
+	u.target = nil
+	u.velocity = 0
+	return nil
+}
+
+func (u *Unit) handleHold(cmd Command) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state == Dead {
+		return nil
+	}
+	u.state = HoldingPosition
+	u.target = nil
+	u.velocity = 0
+	return nil
 }
 
-func (u *Unit) CalculateDamageAgainst(target *Unit) int {
-	targetArmor := target.GetArmor()
-	dmg := u.GetDamage()
-	result := dmg - targetArmor
-	if result < 0 {
+// CalculateDamageAgainst resolves one volley against target using the
+// weapon that fits the target's layer and the damage matrix (see damage.go):
+// type/size percentage first, then armor, then the 0.5 minimum—per hit.
+// Returns 0 when the unit has no weapon for that layer.
+func (u *Unit) CalculateDamageAgainst(target *Unit) float64 {
+	weapon, err := u.WeaponAgainst(target)
+	if err != nil {
 		return 0
 	}
-	return result
+	return u.volleyDamage(weapon, target)
 }
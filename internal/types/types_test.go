@@ -1,6 +1,7 @@
 package types
 
 import (
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -215,12 +216,8 @@ func TestNewUnit_StartsGoroutine(t *testing.T) {
 	var wg sync.WaitGroup
 	unit := NewUnit("test-unit", Marine, Position{}, &wg)
 
-	// Send a command to verify goroutine is running
-	err := unit.SendCommand(Command{Type: CmdStop})
-	require.NoError(t, err)
-
-	// Give it time to process
-	time.Sleep(50 * time.Millisecond)
+	// A reply proves the goroutine is running
+	require.NoError(t, sendOrder(t, unit, Command{Type: CmdStop}))
 
 	// Shutdown should complete without deadlock
 	unit.Shutdown()
@@ -420,7 +417,7 @@ func TestUnit_TakeDamage(t *testing.T) {
 
 	// Marine has 40 HP
 	remainingHealth := unit.TakeDamage(10)
-	require.Equal(t, 30, remainingHealth)
+	require.Equal(t, 30.0, remainingHealth)
 	require.Equal(t, 30, unit.GetHealth())
 
 	unit.Shutdown()
@@ -433,7 +430,7 @@ func TestUnit_TakeDamage_ClampsToZero(t *testing.T) {
 
 	// Deal more damage than health
 	remainingHealth := unit.TakeDamage(100)
-	require.Equal(t, 0, remainingHealth)
+	require.Equal(t, 0.0, remainingHealth)
 	require.Equal(t, 0, unit.GetHealth())
 
 	unit.Shutdown()
@@ -471,39 +468,35 @@ func TestUnit_Setters_ConcurrentAccess(t *testing.T) {
 // ═══════════════════════════════════════════════════════════════════════════
 
 func TestUnit_HandleMove(t *testing.T) {
+	clock := NewManualClock(clockStart)
 	var wg sync.WaitGroup
-	unit := NewUnit("test", Marine, Position{X: 0, Y: 0}, &wg)
+	unit := NewUnit("test", Marine, Position{X: 0, Y: 0}, &wg, WithClock(clock))
 
 	dest := Position{X: 100, Y: 200}
-	err := unit.SendCommand(Command{Type: CmdMove, Dest: dest})
-	require.NoError(t, err)
-
-	// Give time to process
-	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, sendOrder(t, unit, Command{Type: CmdMove, Dest: dest}))
+	advanceUntil(t, clock, func() bool { return unit.GetPosition() != Position{} })
 
+	// Marines walk: still on the way, nowhere near a 224-tile trip's end
 	require.Equal(t, Moving, unit.GetState())
-	require.Equal(t, dest, unit.GetPosition())
+	require.Equal(t, dest, unit.GetDestination())
+	require.NotEqual(t, dest, unit.GetPosition())
 
 	unit.Shutdown()
 	wg.Wait()
 }
 
 func TestUnit_HandleAttack(t *testing.T) {
+	clock := NewManualClock(clockStart)
 	var wg sync.WaitGroup
-	attacker := NewUnit("marine", Marine, Position{}, &wg)
-	target := NewUnit("zergling", Zergling, Position{}, &wg)
+	attacker := NewUnit("marine", Marine, Position{}, &wg, WithClock(clock))
+	target := NewUnit("zergling", Zergling, Position{}, &wg, WithClock(clock))
 
 	initialHealth := target.GetHealth()
 
-	err := attacker.SendCommand(Command{Type: CmdAttack, Target: target})
-	require.NoError(t, err)
-
-	// Give time to process
-	time.Sleep(50 * time.Millisecond)
-
+	require.NoError(t, sendOrder(t, attacker, Command{Type: CmdAttack, Target: target}))
 	require.Equal(t, Attacking, attacker.GetState())
 	require.Equal(t, target, attacker.GetTarget())
-	require.Less(t, target.GetHealth(), initialHealth, "Target should have taken damage")
+	advanceUntil(t, clock, func() bool { return target.GetHealth() < initialHealth })
 
 	attacker.Shutdown()
 	target.Shutdown()
@@ -514,12 +507,8 @@ func TestUnit_HandleAttack_NilTarget(t *testing.T) {
 	var wg sync.WaitGroup
 	unit := NewUnit("test", Marine, Position{}, &wg)
 
-	// Send attack command with nil target
-	err := unit.SendCommand(Command{Type: CmdAttack, Target: nil})
-	require.NoError(t, err)
-
-	// Give time to process
-	time.Sleep(50 * time.Millisecond)
+	// Send attack command with nil target: refused
+	require.ErrorIs(t, sendOrder(t, unit, Command{Type: CmdAttack, Target: nil}), ErrNoTarget)
 
 	// State should not change to Attacking
 	require.NotEqual(t, Attacking, unit.GetState())
@@ -538,12 +527,7 @@ func TestUnit_HandleStop(t *testing.T) {
 	unit.SetState(Attacking)
 	unit.SetTarget(target)
 
-	err := unit.SendCommand(Command{Type: CmdStop})
-	require.NoError(t, err)
-
-	// Give time to process
-	time.Sleep(50 * time.Millisecond)
-
+	require.NoError(t, sendOrder(t, unit, Command{Type: CmdStop}))
 	require.Equal(t, Idle, unit.GetState())
 	require.Nil(t, unit.GetTarget())
 
@@ -556,12 +540,7 @@ func TestUnit_HandleHold(t *testing.T) {
 	var wg sync.WaitGroup
 	unit := NewUnit("test", Marine, Position{}, &wg)
 
-	err := unit.SendCommand(Command{Type: CmdHold})
-	require.NoError(t, err)
-
-	// Give time to process
-	time.Sleep(50 * time.Millisecond)
-
+	require.NoError(t, sendOrder(t, unit, Command{Type: CmdHold}))
 	require.Equal(t, HoldingPosition, unit.GetState())
 
 	unit.Shutdown()
@@ -579,7 +558,7 @@ func TestUnit_CalculateDamageAgainst(t *testing.T) {
 
 	// Marine: 6 damage, Zergling: 0 armor
 	damage := marine.CalculateDamageAgainst(zergling)
-	require.Equal(t, 6, damage)
+	require.Equal(t, 6.0, damage)
 
 	marine.Shutdown()
 	zergling.Shutdown()
@@ -591,29 +570,48 @@ func TestUnit_CalculateDamageAgainst_WithArmor(t *testing.T) {
 	marine := NewUnit("marine", Marine, Position{}, &wg)
 	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
 
-	// Marine: 6 damage, Zealot: 1 armor
+	// Marine: 6 damage, Zealot: 1 armor (once its 60 shields are gone)
+	zealot.TakeDamage(60)
 	damage := marine.CalculateDamageAgainst(zealot)
-	require.Equal(t, 5, damage)
+	require.Equal(t, 5.0, damage)
 
 	marine.Shutdown()
 	zealot.Shutdown()
 	wg.Wait()
 }
 
-func TestUnit_CalculateDamageAgainst_FloorsAtZero(t *testing.T) {
+func TestUnit_CalculateDamageAgainst_SubtractsArmor(t *testing.T) {
 	var wg sync.WaitGroup
-	probe := NewUnit("probe", Probe, Position{}, &wg)
+	marine := NewUnit("marine", Marine, Position{}, &wg)
 	battlecruiser := NewUnit("bc", Battlecruiser, Position{}, &wg)
 
-	// Probe: 5 damage, Battlecruiser: 3 armor
-	damage := probe.CalculateDamageAgainst(battlecruiser)
-	require.Equal(t, 2, damage)
+	// Marine: 6 damage, Battlecruiser: 3 armor
+	damage := marine.CalculateDamageAgainst(battlecruiser)
+	require.Equal(t, 3.0, damage)
 
-	probe.Shutdown()
+	marine.Shutdown()
 	battlecruiser.Shutdown()
 	wg.Wait()
 }
 
+func TestUnit_CalculateDamageAgainst_FloorsAtMinimum(t *testing.T) {
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+	require.NoError(t, teal.SetUpgradeLevel(Carapace, 3, clockStart))
+
+	var wg sync.WaitGroup
+	firebat := NewUnit("firebat", Firebat, Position{}, &wg)
+	ultra := NewUnit("ultra", Ultralisk, Position{}, &wg, WithOwner(teal))
+
+	// Firebat: 2×8 concussive, 25% against Large = 2 per hit. Ultralisk
+	// with +3 carapace: 4 armor. Each hit still does the 0.5 minimum.
+	require.Equal(t, 4, ultra.GetArmor())
+	require.Equal(t, 1.0, firebat.CalculateDamageAgainst(ultra))
+
+	firebat.Shutdown()
+	ultra.Shutdown()
+	wg.Wait()
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // GOROUTINE LIFECYCLE TESTS
 // ═══════════════════════════════════════════════════════════════════════════
@@ -716,7 +714,7 @@ func TestUnit_ConcurrentCommands(t *testing.T) {
 	}
 
 	testWg.Wait()
-	time.Sleep(100 * time.Millisecond) // Let commands process
+	require.NoError(t, sendOrder(t, unit, Command{Type: CmdStop}), "answered once the others are processed")
 
 	unit.Shutdown()
 	wg.Wait()
@@ -738,6 +736,7 @@ func TestUnit_SendCommand_BlocksWhenBufferFull(t *testing.T) {
 		err := unit.SendCommand(Command{Type: CmdStop})
 		if err != nil {
 			// Expected - hit backpressure
+			require.ErrorIs(t, err, ErrBackpressure)
 			require.Contains(t, err.Error(), "backpressure")
 			unit.Shutdown()
 			wg.Wait()
@@ -765,7 +764,13 @@ func TestUnit_SendCommand_AfterShutdown(t *testing.T) {
 	// Try to send command after shutdown - should return error
 	err := unit.SendCommand(Command{Type: CmdStop})
 	require.Error(t, err, "SendCommand should error when unit is shut down")
+	require.ErrorIs(t, err, ErrShuttingDown)
 	require.Contains(t, err.Error(), "shutting down")
+
+	var cmdErr *CommandError
+	require.ErrorAs(t, err, &cmdErr)
+	require.Equal(t, "test", cmdErr.UnitID)
+	require.Equal(t, CmdStop, cmdErr.Command.Type)
 }
 
 // TestUnit_Shutdown_WithPendingCommands verifies that shutdown
@@ -801,21 +806,60 @@ func TestUnit_Shutdown_WithPendingCommands(t *testing.T) {
 	}
 }
 
+// TestUnit_Shutdown_AnswersEveryAcceptedCommand races senders against
+// Shutdown: a command SendCommand took (returned nil) must still get an
+// answer on Result, even if the loop stopped before running it.
+func TestUnit_Shutdown_AnswersEveryAcceptedCommand(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		var wg sync.WaitGroup
+		unit := NewUnit("test", Marine, Position{}, &wg)
+
+		var senders sync.WaitGroup
+		accepted := make(chan chan error, 400)
+		for i := 0; i < 8; i++ {
+			senders.Add(1)
+			go func() {
+				defer senders.Done()
+				for j := 0; j < 50; j++ {
+					result := make(chan error, 1)
+					if unit.SendCommand(Command{Type: CmdHold, Result: result}) == nil {
+						accepted <- result
+					}
+				}
+			}()
+		}
+		for len(accepted) < 20 { // Shut down mid-stream, not before it starts
+			runtime.Gosched()
+		}
+		unit.Shutdown()
+		senders.Wait()
+		wg.Wait()
+		close(accepted)
+
+		for result := range accepted {
+			select {
+			case err := <-result:
+				if err != nil {
+					require.ErrorIs(t, err, ErrShuttingDown)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("round %d: a command was taken and never answered", round)
+			}
+		}
+	}
+}
+
 // TestUnit_ConcurrentCommands_Stress is a stress test that verifies
 // the system handles many concurrent senders gracefully with backpressure.
 func TestUnit_ConcurrentCommands_Stress(t *testing.T) {
 	var wg sync.WaitGroup
 	unit := NewUnit("test", Marine, Position{}, &wg)
 
-	// Drain events channel in background to prevent blocking
+	// Drain a subscription in background; it closes when the unit stops
+	events := unit.Subscribe(nil)
 	go func() {
-		for {
-			select {
-			case <-unit.events:
-				// Discard events
-			case <-unit.ctx.Done():
-				return
-			}
+		for range events.Events() {
+			// Discard events
 		}
 	}()
 
@@ -0,0 +1,230 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errBroke = errors.New("not enough minerals")
+
+// bank is a Treasury holding a single pool of minerals and gas
+type bank struct {
+	mu       sync.Mutex
+	minerals int
+	gas      int
+}
+
+func (b *bank) Spend(_ string, cost map[string]int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cost[ResourceMinerals] > b.minerals || cost[ResourceGas] > b.gas {
+		return errBroke
+	}
+	b.minerals -= cost[ResourceMinerals]
+	b.gas -= cost[ResourceGas]
+	return nil
+}
+
+// nextUpgradeEvent waits for the faction's next upgrade event
+func nextUpgradeEvent(t *testing.T, events <-chan UpgradeEvent) UpgradeEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no upgrade event")
+		return UpgradeEvent{}
+	}
+}
+
+func TestUpgrades_Sheet(t *testing.T) {
+	weapons, ok := LookupUpgrade(InfantryWeapons)
+	require.True(t, ok)
+	require.Equal(t, map[string]int{ResourceMinerals: 250, ResourceGas: 250}, weapons.Cost(3))
+	require.Equal(t, upgradeTime+2*upgradeTimeStep, weapons.ResearchTime(3))
+	require.Equal(t, 3, weapons.MaxLevel)
+	require.True(t, weapons.Affects(Firebat))
+	require.False(t, weapons.Affects(Medic))
+
+	up, ok := UpgradeFor(Zealot, UpgradeShields)
+	require.True(t, ok)
+	require.Equal(t, PlasmaShields, up)
+	up, _ = UpgradeFor(Lurker, UpgradeWeapons)
+	require.Equal(t, MissileAttacks, up)
+	_, ok = UpgradeFor(Medic, UpgradeWeapons)
+	require.False(t, ok)
+	_, ok = UpgradeFor(Marine, UpgradeShields)
+	require.False(t, ok)
+	require.Equal(t, "FlyerCarapace", FlyerCarapace.String())
+	require.Equal(t, "ResearchCompleted", ResearchCompleted.String())
+}
+
+func TestResearch_ReachesEveryUnitOfTheCategory(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Bisu", Terran, Blue)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := red.WatchUpgrades(ctx, 8)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithClock(clock), WithOwner(red))
+	tank := NewUnit("tank", SiegeTank, Position{}, &wg, WithClock(clock), WithOwner(red))
+	theirs := NewUnit("theirs", Marine, Position{}, &wg, WithClock(clock), WithOwner(blue))
+	require.Equal(t, 6, marine.GetDamage())
+
+	money := &bank{minerals: 500, gas: 500}
+	require.NoError(t, red.Research(ctx, InfantryWeapons, money, clock))
+	ev := nextUpgradeEvent(t, events)
+	require.Equal(t, ResearchStarted, ev.Type)
+	require.Equal(t, 1, ev.Level)
+	require.Equal(t, clockStart.Add(upgradeTime), ev.Done)
+	require.Equal(t, 400, money.minerals)
+	require.True(t, red.Researching(InfantryWeapons))
+
+	err := red.Research(ctx, InfantryWeapons, money, clock)
+	require.ErrorIs(t, err, ErrResearchInProgress)
+	require.ErrorContains(t, err, "p1: InfantryWeapons 1: already researching")
+	require.ErrorIs(t, red.Research(ctx, Carapace, money, clock), ErrCannotResearch)
+	require.Equal(t, 400, money.minerals, "refusals cost nothing")
+
+	clock.Advance(upgradeTime - time.Second)
+	require.Equal(t, 6, marine.GetDamage(), "not done yet")
+	clock.Advance(time.Second)
+	ev = nextUpgradeEvent(t, events)
+	require.Equal(t, ResearchCompleted, ev.Type)
+	require.Equal(t, 1, ev.Level)
+	require.Equal(t, 1, red.UpgradeLevel(InfantryWeapons))
+	require.False(t, red.Researching(InfantryWeapons))
+
+	require.Equal(t, 7, marine.GetDamage())
+	require.Equal(t, 7.0, marine.perHitDamage(*marine.primaryWeapon()))
+	require.Equal(t, 30, tank.GetDamage(), "Vehicle Weapons is a separate upgrade")
+	require.Equal(t, 6, theirs.GetDamage(), "upgrades belong to the faction")
+	fresh := NewUnit("fresh", Marine, Position{}, &wg, WithClock(clock), WithOwner(red))
+	require.Equal(t, 7, fresh.GetDamage(), "units built later get it too")
+
+	require.NoError(t, red.SetUpgradeLevel(InfantryArmor, 2, clock.Now()))
+	require.Equal(t, ResearchCompleted, nextUpgradeEvent(t, events).Type)
+	require.Equal(t, 2, marine.GetArmor())
+	require.Equal(t, 2, marine.defense().armor)
+	require.Equal(t, 1, tank.GetArmor())
+
+	for _, u := range []*Unit{marine, tank, theirs, fresh} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestResearch_PaymentCancelAndMax(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	purple := NewFaction("p4", "Stork", Protoss, Purple)
+	ctx, cancel := context.WithCancel(context.Background())
+	events := purple.WatchUpgrades(ctx, 8)
+
+	err := purple.Research(ctx, PlasmaShields, &bank{minerals: 150, gas: 500}, clock)
+	require.ErrorIs(t, err, errBroke)
+	var ue *UpgradeError
+	require.ErrorAs(t, err, &ue)
+	require.Equal(t, 1, ue.Level)
+	require.False(t, purple.Researching(PlasmaShields))
+
+	// Losing the Forge mid-research loses the money and the progress
+	money := &bank{minerals: 1000, gas: 1000}
+	forge, lost := context.WithCancel(ctx)
+	require.NoError(t, purple.Research(forge, GroundWeapons, money, clock))
+	require.Equal(t, ResearchStarted, nextUpgradeEvent(t, events).Type)
+	lost()
+	ev := nextUpgradeEvent(t, events)
+	require.Equal(t, ResearchCancelled, ev.Type)
+	require.Equal(t, 0, ev.Level)
+	require.Zero(t, purple.UpgradeLevel(GroundWeapons))
+	require.Equal(t, 900, money.minerals)
+	require.NoError(t, purple.Research(ctx, GroundWeapons, money, clock), "can start over")
+	require.Equal(t, ResearchStarted, nextUpgradeEvent(t, events).Type)
+
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg, WithClock(clock), WithOwner(purple))
+	require.NoError(t, purple.SetUpgradeLevel(PlasmaShields, 3, clock.Now()))
+	require.Equal(t, 3, zealot.GetShieldArmor())
+	require.Equal(t, 3, zealot.defense().shieldArmor)
+	require.ErrorIs(t, purple.Research(ctx, PlasmaShields, money, clock), ErrUpgradeMaxed)
+	require.ErrorIs(t, purple.SetUpgradeLevel(PlasmaShields, 4, clock.Now()), ErrUpgradeMaxed)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		for {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return true
+				}
+			default:
+				return false
+			}
+		}
+	}, time.Second, time.Millisecond, "watch channel closes with ctx")
+
+	zealot.Shutdown()
+	wg.Wait()
+}
+
+func TestResearch_CancelKeepsLevelSetMeanwhile(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := red.WatchUpgrades(ctx, 8)
+
+	// A scenario script sets +2 while +1 is still researching
+	bay, destroyed := context.WithCancel(ctx)
+	require.NoError(t, red.Research(bay, InfantryArmor, &bank{minerals: 1000, gas: 1000}, clock))
+	require.Equal(t, ResearchStarted, nextUpgradeEvent(t, events).Type)
+	require.NoError(t, red.SetUpgradeLevel(InfantryArmor, 2, clock.Now()))
+	require.Equal(t, ResearchCompleted, nextUpgradeEvent(t, events).Type)
+
+	destroyed()
+	ev := nextUpgradeEvent(t, events)
+	require.Equal(t, ResearchCancelled, ev.Type)
+	require.Equal(t, 2, ev.Level)
+	require.Equal(t, 2, red.UpgradeLevel(InfantryArmor), "cancelling +1 doesn't undo +2")
+
+	// Nor does +1 finishing late
+	require.NoError(t, red.Research(ctx, InfantryWeapons, &bank{minerals: 1000, gas: 1000}, clock))
+	require.Equal(t, ResearchStarted, nextUpgradeEvent(t, events).Type)
+	require.NoError(t, red.SetUpgradeLevel(InfantryWeapons, 3, clock.Now()))
+	nextUpgradeEvent(t, events)
+	weapons, _ := LookupUpgrade(InfantryWeapons)
+	clock.Advance(weapons.ResearchTime(1))
+	ev = nextUpgradeEvent(t, events)
+	require.Equal(t, ResearchCompleted, ev.Type)
+	require.Equal(t, 3, ev.Level)
+	require.Equal(t, 3, red.UpgradeLevel(InfantryWeapons))
+}
+
+// closedTreasury is a Treasury whose manager has stopped, as
+// resources.ResourceManager.Spend reports after Shutdown (or before Start)
+type closedTreasury struct{}
+
+func (closedTreasury) Spend(string, map[string]int) error { return ErrShuttingDown }
+
+func TestResearch_TreasuryRefusesWithoutBlocking(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+
+	done := make(chan error, 1)
+	go func() { done <- teal.Research(context.Background(), Carapace, closedTreasury{}, clock) }()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrShuttingDown)
+	case <-time.After(time.Second):
+		t.Fatal("Research blocked on a stopped treasury")
+	}
+	require.False(t, teal.Researching(Carapace))
+	require.Zero(t, teal.UpgradeLevel(Carapace))
+}
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// VITALS - Shields, Regeneration and Energy
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Health is only part of the picture:
+//   - Protoss units carry shields on top of health. Every hit lands on the
+//     shields first, at full strength regardless of unit size, reduced only
+//     by shield armor. Shields recharge once the unit has gone a while
+//     without being hit.
+//   - Zerg units slowly regenerate health, all the time.
+//   - Spellcasters (Medic, Science Vessel, High Templar, Defiler, Queen,
+//     Arbiter) have an energy pool that refills over time and pays for spells.
+//
+// 🎴 MTG ANALOGY: Shields are like a prevention shield that refills at your
+// upkeep. Energy is a mana pool that doesn't empty between turns.
+//
+// ⚔️ SC:BW ANALOGY: Pull back a shield-damaged Dragoon and it comes back
+// good as new. Hit a Zergling once and wait—it heals. A Science Vessel has
+// to sit on energy before it can Irradiate.
+//
+// 🎓 LEARNING: All of this runs on the unit's own tick, guarded by the
+// same mutex as health. Getters take the read lock, so any goroutine can
+// watch the numbers tick up safely.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// ShieldStatsData describes a Protoss shield layer in the stat sheet
+type ShieldStatsData struct {
+	Max        int     `json:"max"`
+	Armor      int     `json:"armor"`
+	RegenRate  float64 `json:"regenRate"`  // Shield points per second
+	RegenDelay float64 `json:"regenDelay"` // Seconds without damage before regen starts
+}
+
+// Validate checks a shield entry
+func (s ShieldStatsData) Validate() error {
+	var errs []error
+	if s.Max <= 0 {
+		errs = append(errs, fmt.Errorf("shields.max must be positive, got %d", s.Max))
+	}
+	if s.Armor < 0 {
+		errs = append(errs, fmt.Errorf("shields.armor must not be negative, got %d", s.Armor))
+	}
+	if s.RegenRate < 0 {
+		errs = append(errs, fmt.Errorf("shields.regenRate must not be negative, got %g", s.RegenRate))
+	}
+	if s.RegenDelay < 0 {
+		errs = append(errs, fmt.Errorf("shields.regenDelay must not be negative, got %g", s.RegenDelay))
+	}
+	return errors.Join(errs...)
+}
+
+// EnergyStatsData describes a caster's energy pool in the stat sheet
+type EnergyStatsData struct {
+	Max       int     `json:"max"`
+	Start     int     `json:"start"`     // Energy when the unit spawns
+	RegenRate float64 `json:"regenRate"` // Energy per second
+}
+
+// Validate checks an energy entry
+func (e EnergyStatsData) Validate() error {
+	var errs []error
+	if e.Max <= 0 {
+		errs = append(errs, fmt.Errorf("energy.max must be positive, got %d", e.Max))
+	}
+	if e.Start < 0 || e.Start > e.Max {
+		errs = append(errs, fmt.Errorf("energy.start must be between 0 and max (%d), got %d", e.Max, e.Start))
+	}
+	if e.RegenRate < 0 {
+		errs = append(errs, fmt.Errorf("energy.regenRate must not be negative, got %g", e.RegenRate))
+	}
+	return errors.Join(errs...)
+}
+
+// applyShieldStats sets up the shield layer; nil leaves the unit without one
+func applyShieldStats(u *Unit, data *ShieldStatsData) {
+	if data == nil {
+		return
+	}
+	u.maxShields = data.Max
+	u.shields = float64(data.Max)
+	u.shieldArmor = data.Armor
+	u.shieldRegen = data.RegenRate
+	u.shieldDelay = seconds(data.RegenDelay)
+}
+
+// applyEnergyStats sets up the energy pool; nil leaves the unit without one
+func applyEnergyStats(u *Unit, data *EnergyStatsData) {
+	if data == nil {
+		return
+	}
+	u.maxEnergy = data.Max
+	u.energy = float64(data.Start)
+	u.energyRegen = data.RegenRate
+}
+
+// GetShields returns shields as the HUD shows them (rounded up)
+func (u *Unit) GetShields() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return int(math.Ceil(u.shields))
+}
+
+// GetShieldsExact returns shields including the fractional part
+func (u *Unit) GetShieldsExact() float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.shields
+}
+
+// GetMaxShields returns the shield capacity; 0 for units without shields
+func (u *Unit) GetMaxShields() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.maxShields
+}
+
+// GetShieldArmor returns the armor that applies to hits on shields,
+// Plasma Shields included
+func (u *Unit) GetShieldArmor() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.shieldArmorLocked()
+}
+
+// GetHealthRegen returns health regenerated per second
+func (u *Unit) GetHealthRegen() float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.healthRegen
+}
+
+// GetEnergy returns energy as the HUD shows it (rounded down—a spell
+// costing 75 needs a full 75)
+func (u *Unit) GetEnergy() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return int(math.Floor(u.energy))
+}
+
+// GetEnergyExact returns energy including the fractional part
+func (u *Unit) GetEnergyExact() float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.energy
+}
+
+// GetMaxEnergy returns the energy capacity; 0 for non-casters
+func (u *Unit) GetMaxEnergy() int {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.maxEnergy
+}
+
+// defense snapshots what a hit has to get through
+func (u *Unit) defense() defense {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.defenseLocked()
+}
+
+// defenseLocked is defense for callers that already hold u.mu
+func (u *Unit) defenseLocked() defense {
+	return defense{
+		shields:     u.shields,
+		shieldArmor: u.shieldArmorLocked(),
+		armor:       u.baseArmor + u.armorModifier*u.armorUpgradesLocked(),
+		size:        u.size,
+	}
+}
+
+// regenerate tops up shields (after the delay since the last hit), Zerg
+// health and caster energy for the time since the last tick. A cloaked
+// Wraith's energy drains instead (see drainCloak).
+func (u *Unit) regenerate(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	elapsed := now.Sub(u.lastRegen).Seconds()
+	u.lastRegen = now
+	if elapsed <= 0 || u.state == Dead {
+		return
+	}
+
+	if u.maxShields > 0 && now.Sub(u.lastDamaged) >= u.shieldDelay {
+		u.shields = math.Min(u.shields+u.shieldRegen*elapsed, float64(u.maxShields))
+	}
+	if u.healthRegen > 0 {
+		u.health = math.Min(u.health+u.healthRegen*elapsed, float64(u.maxHealth))
+	}
+	if u.maxEnergy > 0 && !(u.canCloak && u.cloaked) {
+		u.energy = math.Min(u.energy+u.energyRegen*elapsed, float64(u.maxEnergy))
+	}
+}
@@ -0,0 +1,135 @@
+package types
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeStats_Vitals(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{}, &wg)
+	templar := NewUnit("templar", Templar, Position{}, &wg)
+
+	require.Equal(t, 60, zealot.GetShields())
+	require.Equal(t, 60, zealot.GetMaxShields())
+	require.Equal(t, 0, marine.GetMaxShields())
+
+	require.Greater(t, zergling.GetHealthRegen(), 0.0)
+	require.Equal(t, 0.0, marine.GetHealthRegen())
+
+	require.Equal(t, 50, templar.GetEnergy())
+	require.Equal(t, 200, templar.GetMaxEnergy())
+	require.Equal(t, 0, marine.GetMaxEnergy())
+
+	for _, u := range []*Unit{zealot, marine, zergling, templar} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestUnit_TakeDamage_ShieldsFirst(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+
+	require.Equal(t, 100.0, zealot.TakeDamage(50), "shields soak it all")
+	require.Equal(t, 10, zealot.GetShields())
+
+	require.Equal(t, 80.0, zealot.TakeDamage(30), "10 on shields, 20 spills over")
+	require.Equal(t, 0, zealot.GetShields())
+
+	zealot.Shutdown()
+	wg.Wait()
+}
+
+func TestCalculateDamageAgainst_Shields(t *testing.T) {
+	var wg sync.WaitGroup
+	vulture := NewUnit("vulture", Vulture, Position{}, &wg)
+	dragoon := NewUnit("dragoon", Dragoon, Position{}, &wg)
+
+	// Shields take concussive damage at full strength...
+	require.Equal(t, 20.0, vulture.CalculateDamageAgainst(dragoon))
+
+	// ...and only what spills over gets the size penalty and hull armor
+	dragoon.TakeDamage(70)
+	require.Equal(t, 10.0+(10*0.25-1), vulture.CalculateDamageAgainst(dragoon))
+
+	vulture.Shutdown()
+	dragoon.Shutdown()
+	wg.Wait()
+}
+
+func TestCalculateDamageAgainst_ShieldArmor(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("shield-upgrades", strings.NewReader(`{"Zealot": {"shields": {"armor": 2}}}`)))
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+
+	require.Equal(t, 2, zealot.GetShieldArmor())
+	require.Equal(t, 4.0, marine.CalculateDamageAgainst(zealot), "6 - 2 shield armor")
+
+	marine.Shutdown()
+	zealot.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Regeneration(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("fast-regen", strings.NewReader(`
+Zealot:
+  shields:
+    regenRate: 200
+    regenDelay: 0.5
+Zergling:
+  healthRegen: 200
+Templar:
+  energy:
+    regenRate: 1000
+`)))
+
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg, WithClock(clock))
+	zergling := NewUnit("ling", Zergling, Position{}, &wg, WithClock(clock))
+	templar := NewUnit("templar", Templar, Position{}, &wg, WithClock(clock))
+
+	zealot.TakeDamage(40)
+	zergling.TakeDamage(20)
+
+	// One tick: Zerg health doesn't wait; shields sit out the delay after a hit
+	clock.Advance(TickRate)
+	require.Eventually(t, func() bool { return zergling.GetHealthExact() > 15 }, time.Second, time.Millisecond)
+	require.InDelta(t, 15+200*TickRate.Seconds(), zergling.GetHealthExact(), 1e-9)
+	require.Equal(t, 20, zealot.GetShields())
+
+	clock.Advance(time.Second)
+	require.Eventually(t, func() bool {
+		return zergling.GetHealth() == 35 && zealot.GetShields() == 60 && templar.GetEnergy() == 200
+	}, time.Second, time.Millisecond)
+	require.Equal(t, 35.0, zergling.GetHealthExact(), "capped at max health")
+	require.Equal(t, 100, zealot.GetHealth())
+
+	for _, u := range []*Unit{zealot, zergling, templar} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestVitalsStatsData_Validate(t *testing.T) {
+	err := ShieldStatsData{Max: 0, Armor: -1, RegenRate: -1, RegenDelay: -1}.Validate()
+	require.Error(t, err)
+	for _, field := range []string{"shields.max", "shields.armor", "shields.regenRate", "shields.regenDelay"} {
+		require.Contains(t, err.Error(), field)
+	}
+
+	err = EnergyStatsData{Max: 200, Start: 250}.Validate()
+	require.ErrorContains(t, err, "energy.start")
+	require.NoError(t, EnergyStatsData{Max: 200, Start: 50, RegenRate: 0.75}.Validate())
+}
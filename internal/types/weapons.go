@@ -0,0 +1,420 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// WEAPONS & TARGETING - Ground vs Air
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// Most units carry one weapon for ground targets and (sometimes) a different
+// one for air. A Zealot has blades and nothing else—it can't touch a
+// Mutalisk. A Goliath has autocannons for ground and Hellfire missiles for air.
+//
+// 🎴 MTG ANALOGY: "Can block only creatures with flying" / "can't block
+// creatures with flying"—the layer decides what can interact with what.
+//
+// ⚔️ SC:BW ANALOGY: Mutas over a Zealot army. The Zealots stand there.
+//
+// 🎓 LEARNING: Targeting failures are returned as typed errors, so callers
+// branch with errors.Is instead of string-matching.
+//
+// ⏱️ FIRING CYCLE: An attack order keeps firing on every tick until the
+// target dies or the order changes. Each volley is `hits` separate hits
+// (armor applies to each), then the weapon waits out its cooldown. Units
+// with a magazine (Reaver scarabs, Carrier interceptors) spend one round per
+// volley and rebuild rounds one at a time, emitting EventReloaded for each.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// WeaponStatsData describes one weapon in the stat sheet
+type WeaponStatsData struct {
+	Damage     int     `json:"damage"` // Per hit
+	DamageType string  `json:"damageType"`
+	Range      int     `json:"range"`
+	MinRange   int     `json:"minRange,omitempty"` // Can't hit anything closer (sieged tanks)
+	Cooldown   float64 `json:"cooldown"`           // Seconds between volleys
+	Hits       int     `json:"hits,omitempty"`     // Hits per volley (0 = 1)
+
+	Splash *SplashStatsData `json:"splash,omitempty"` // Area damage (see splash.go)
+}
+
+// AmmoStatsData describes a magazine shared by all of a unit's weapons
+type AmmoStatsData struct {
+	Capacity   int     `json:"capacity"`   // Rounds when full
+	ReloadTime float64 `json:"reloadTime"` // Seconds to rebuild one round
+}
+
+// Validate checks a magazine entry
+func (a AmmoStatsData) Validate() error {
+	var errs []error
+	if a.Capacity <= 0 {
+		errs = append(errs, fmt.Errorf("ammo.capacity must be positive, got %d", a.Capacity))
+	}
+	if a.ReloadTime <= 0 {
+		errs = append(errs, fmt.Errorf("ammo.reloadTime must be positive, got %g", a.ReloadTime))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks a weapon entry; field names are prefixed with the slot
+// ("groundWeapon", "airWeapon") so errors point at the right block.
+func (w WeaponStatsData) Validate(slot string) error {
+	var errs []error
+	if w.Damage < 0 {
+		errs = append(errs, fmt.Errorf("%s.damage must not be negative, got %d", slot, w.Damage))
+	}
+	if w.Range < 0 {
+		errs = append(errs, fmt.Errorf("%s.range must not be negative, got %d", slot, w.Range))
+	}
+	if w.MinRange < 0 || (w.MinRange > 0 && w.MinRange >= w.Range) {
+		errs = append(errs, fmt.Errorf("%s.minRange must be between 0 and range, got %d", slot, w.MinRange))
+	}
+	if w.Cooldown < 0 {
+		errs = append(errs, fmt.Errorf("%s.cooldown must not be negative, got %g", slot, w.Cooldown))
+	}
+	if w.Hits < 0 {
+		errs = append(errs, fmt.Errorf("%s.hits must not be negative, got %d", slot, w.Hits))
+	}
+	if _, ok := parseDamageType(w.DamageType); !ok {
+		errs = append(errs, fmt.Errorf("%s: unknown damageType %q", slot, w.DamageType))
+	}
+	if w.Splash != nil {
+		errs = append(errs, w.Splash.Validate(slot))
+	}
+	return errors.Join(errs...)
+}
+
+// Weapon is the runtime form of a weapon: parsed enums, ready for combat math
+type Weapon struct {
+	Damage     int // Per hit, before upgrades
+	DamageType DamageType
+	Range      int
+	MinRange   int // 0 = none
+	Cooldown   time.Duration
+	Hits       int    // Always >= 1
+	Splash     Splash // Zero value = single target
+}
+
+// IsMelee reports whether the weapon is used up close (Zealot blades,
+// Zergling claws). Melee attacks never miss uphill.
+func (w Weapon) IsMelee() bool {
+	return w.Range <= 1
+}
+
+// newWeapon converts a stat sheet entry; nil in, nil out (no weapon in that slot)
+func newWeapon(data *WeaponStatsData) *Weapon {
+	if data == nil {
+		return nil
+	}
+	dt, _ := parseDamageType(data.DamageType)
+	hits := data.Hits
+	if hits < 1 {
+		hits = 1
+	}
+	return &Weapon{
+		Damage:     data.Damage,
+		DamageType: dt,
+		Range:      data.Range,
+		MinRange:   data.MinRange,
+		Cooldown:   seconds(data.Cooldown),
+		Hits:       hits,
+		Splash:     newSplash(data.Splash),
+	}
+}
+
+// magazine tracks limited ammo. Rounds rebuild one at a time while below capacity.
+type magazine struct {
+	capacity   int
+	rounds     int
+	reloadTime time.Duration
+	nextRound  time.Time // Zero when nothing is reloading
+}
+
+// newMagazine converts a stat sheet entry; units without one have unlimited ammo
+func newMagazine(data *AmmoStatsData) *magazine {
+	if data == nil {
+		return nil
+	}
+	return &magazine{capacity: data.Capacity, rounds: data.Capacity, reloadTime: seconds(data.ReloadTime)}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Targeting errors. Wrapped in a *TargetingError with the unit IDs involved.
+var (
+	ErrNoTarget         = errors.New("no target")
+	ErrCannotTarget     = errors.New("cannot target")
+	ErrOutOfRange       = errors.New("target out of range")
+	ErrTargetNotVisible = errors.New("target not visible")
+)
+
+// TargetingError explains why an attack order was rejected
+type TargetingError struct {
+	UnitID   string
+	TargetID string
+	Layer    ElevationLayer // The target's layer when the check ran
+	Reason   string
+	Err      error // ErrNoTarget, ErrCannotTarget, ErrOutOfRange or ErrTargetNotVisible
+}
+
+func (e *TargetingError) Error() string {
+	if e.TargetID == "" {
+		return fmt.Sprintf("%s: %v", e.UnitID, e.Err)
+	}
+	return fmt.Sprintf("%s -> %s (%s): %v: %s", e.UnitID, e.TargetID, e.Layer, e.Err, e.Reason)
+}
+
+func (e *TargetingError) Unwrap() error {
+	return e.Err
+}
+
+// GetGroundWeapon returns the unit's anti-ground weapon, if it has one
+func (u *Unit) GetGroundWeapon() (Weapon, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.groundWeapon == nil {
+		return Weapon{}, false
+	}
+	return *u.groundWeapon, true
+}
+
+// GetAirWeapon returns the unit's anti-air weapon, if it has one
+func (u *Unit) GetAirWeapon() (Weapon, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.airWeapon == nil {
+		return Weapon{}, false
+	}
+	return *u.airWeapon, true
+}
+
+// GetElevationLayer returns the layer the unit currently occupies
+func (u *Unit) GetElevationLayer() ElevationLayer {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.elevationLayer
+}
+
+// primaryWeapon is the weapon GetDamage reports: ground first, then air.
+// Caller must hold u.mu.
+func (u *Unit) primaryWeapon() *Weapon {
+	if u.groundWeapon != nil {
+		return u.groundWeapon
+	}
+	return u.airWeapon
+}
+
+// WeaponAgainst picks the weapon this unit would use on target, or returns a
+// *TargetingError wrapping ErrCannotTarget when no weapon can reach its layer.
+// Burrowed units can't attack at all, except the Lurker, which attacks only
+// while burrowed. Cloaked and burrowed targets need detecting (see cloak.go),
+// and nothing can touch a unit in stasis (see spells.go).
+func (u *Unit) WeaponAgainst(target *Unit) (Weapon, error) {
+	if target == nil {
+		return Weapon{}, &TargetingError{UnitID: u.ID, Err: ErrNoTarget}
+	}
+	layer := target.GetElevationLayer()
+	cannot := func(reason string) (Weapon, error) {
+		return Weapon{}, &TargetingError{UnitID: u.ID, TargetID: target.ID, Layer: layer, Reason: reason, Err: ErrCannotTarget}
+	}
+
+	u.mu.RLock()
+	burrowed, burrowedOnly := u.elevationLayer == Burrowed, u.burrowedOnly
+	u.mu.RUnlock()
+	switch {
+	case burrowed && !burrowedOnly:
+		return cannot("can't attack while burrowed")
+	case !burrowed && burrowedOnly:
+		return cannot("must burrow to attack")
+	}
+
+	if target.hidden() && !u.detects(target) {
+		if layer == Burrowed {
+			return cannot("burrowed target is not detected")
+		}
+		return cannot("cloaked target is not detected")
+	}
+	if target.InStasis() {
+		return cannot("target is in stasis")
+	}
+
+	switch layer {
+	case Air:
+		if w, ok := u.GetAirWeapon(); ok {
+			return w, nil
+		}
+		return cannot("no anti-air weapon")
+	default:
+		if w, ok := u.GetGroundWeapon(); ok {
+			return w, nil
+		}
+		return cannot("no anti-ground weapon")
+	}
+}
+
+// GetAmmo returns rounds left and magazine capacity. ok is false for units
+// with unlimited ammo.
+func (u *Unit) GetAmmo() (rounds, capacity int, ok bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if u.ammo == nil {
+		return 0, 0, false
+	}
+	return u.ammo.rounds, u.ammo.capacity, true
+}
+
+// CanTarget reports whether this unit has a weapon for target's layer
+func (u *Unit) CanTarget(target *Unit) error {
+	_, err := u.WeaponAgainst(target)
+	return err
+}
+
+// CheckRange reports whether target is within reach of the weapon this unit
+// would use on it, returning a *TargetingError wrapping ErrOutOfRange if not.
+func (u *Unit) CheckRange(target *Unit) error {
+	weapon, err := u.WeaponAgainst(target)
+	if err != nil {
+		return err
+	}
+	return u.rangeError(weapon, target)
+}
+
+// rangeError compares squared distance against the combined range: weapon
+// range plus both units' radii, since range is measured edge to edge. A
+// weapon with a minimum range can't hit targets inside it either.
+func (u *Unit) rangeError(weapon Weapon, target *Unit) error {
+	radii := u.GetSize().Radius() + target.GetSize().Radius()
+	combined := float64(weapon.Range) + radii
+	distSq := u.GetPosition().DistanceSq(target.GetPosition())
+	if inner := float64(weapon.MinRange) + radii; weapon.MinRange > 0 && distSq < inner*inner {
+		return &TargetingError{
+			UnitID:   u.ID,
+			TargetID: target.ID,
+			Layer:    target.GetElevationLayer(),
+			Reason:   fmt.Sprintf("distance %.1f inside minimum range %d", math.Sqrt(distSq), weapon.MinRange),
+			Err:      ErrOutOfRange,
+		}
+	}
+	if distSq <= combined*combined {
+		return nil
+	}
+	return &TargetingError{
+		UnitID:   u.ID,
+		TargetID: target.ID,
+		Layer:    target.GetElevationLayer(),
+		Reason:   fmt.Sprintf("distance %.1f exceeds range %d", math.Sqrt(distSq), weapon.Range),
+		Err:      ErrOutOfRange,
+	}
+}
+
+// checkVision returns a *TargetingError wrapping ErrTargetNotVisible when
+// target is beyond this unit's sight range. In a World, what counts is
+// whether the unit's side can see the target (see World.CanSee).
+func (u *Unit) checkVision(target *Unit) error {
+	u.mu.RLock()
+	vision, world := float64(u.visionRange), u.world
+	u.mu.RUnlock()
+	if world != nil {
+		return world.visionError(u, target)
+	}
+	distSq := u.GetPosition().DistanceSq(target.GetPosition())
+	if distSq <= vision*vision {
+		return nil
+	}
+	return &TargetingError{
+		UnitID:   u.ID,
+		TargetID: target.ID,
+		Layer:    target.GetElevationLayer(),
+		Reason:   fmt.Sprintf("distance %.1f beyond vision %d", math.Sqrt(distSq), int(vision)),
+		Err:      ErrTargetNotVisible,
+	}
+}
+
+// perHitDamage is the weapon's damage per hit including attack upgrades.
+// Caller must not hold u.mu.
+func (u *Unit) perHitDamage(weapon Weapon) float64 {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return float64(weapon.Damage + u.attackModifier*u.attackUpgradesLocked())
+}
+
+// volleyDamage previews one volley against target's current shields and
+// hull without applying it. Armor is subtracted per hit, so multi-hit
+// weapons suffer more from armor. Caller must not hold u.mu.
+func (u *Unit) volleyDamage(weapon Weapon, target *Unit) float64 {
+	perHit := u.perHitDamage(weapon)
+	d := target.defense()
+	total := 0.0
+	for i := 0; i < weapon.Hits; i++ {
+		toShields, toHull := d.absorb(perHit, weapon.DamageType)
+		total += toShields + toHull
+	}
+	return total
+}
+
+// tryFire fires one volley if the cooldown has elapsed and a round is
+// available. Returns the damage dealt, splash included, and whether the
+// weapon fired. A shot lost to the high ground or a Dark Swarm still counts
+// as fired (EventMissed, no damage, no splash).
+func (u *Unit) tryFire(weapon Weapon, target *Unit, now time.Time) (float64, bool) {
+	u.mu.Lock()
+	if now.Before(u.cooldownUntil) {
+		u.mu.Unlock()
+		return 0, false
+	}
+	if u.ammo != nil {
+		if u.ammo.rounds == 0 {
+			u.mu.Unlock()
+			return 0, false
+		}
+		u.ammo.rounds--
+		if u.ammo.nextRound.IsZero() {
+			u.ammo.nextRound = now.Add(u.ammo.reloadTime)
+		}
+	}
+	u.cooldownUntil = now.Add(weapon.Cooldown)
+	world := u.world
+	u.mu.Unlock()
+
+	if world != nil && (world.missesUphill(u, target, weapon) || world.shelters(target, weapon, now)) {
+		u.emit(UnitEvent{Type: EventMissed, Source: u, Target: target, Timestamp: now, Data: weapon})
+		return 0, true
+	}
+	dmg, _, killed := target.takeHits(u.perHitDamage(weapon), weapon.DamageType, weapon.Hits, now)
+	u.emit(UnitEvent{Type: EventDamaged, Source: u, Target: target, Timestamp: now, Data: dmg})
+	if killed {
+		target.die(u, now)
+	}
+	return dmg + u.splash(world, weapon, target, now), true
+}
+
+// reload rebuilds rounds whose reload time has passed, one EventReloaded each
+func (u *Unit) reload(now time.Time) {
+	u.mu.Lock()
+	if u.ammo == nil || u.ammo.nextRound.IsZero() {
+		u.mu.Unlock()
+		return
+	}
+	var reloaded []int
+	for !u.ammo.nextRound.IsZero() && !now.Before(u.ammo.nextRound) {
+		u.ammo.rounds++
+		reloaded = append(reloaded, u.ammo.rounds)
+		if u.ammo.rounds < u.ammo.capacity {
+			u.ammo.nextRound = u.ammo.nextRound.Add(u.ammo.reloadTime)
+		} else {
+			u.ammo.nextRound = time.Time{}
+		}
+	}
+	u.mu.Unlock()
+
+	for _, rounds := range reloaded {
+		u.emit(UnitEvent{Type: EventReloaded, Source: u, Timestamp: now, Data: rounds})
+	}
+}
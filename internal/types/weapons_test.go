@@ -0,0 +1,301 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeStats_Weapons(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	goliath := NewUnit("goliath", Goliath, Position{}, &wg)
+	valkyrie := NewUnit("valkyrie", Valkyrie, Position{}, &wg)
+
+	_, ok := zealot.GetAirWeapon()
+	require.False(t, ok, "Zealots have no anti-air weapon")
+
+	ground, ok := goliath.GetGroundWeapon()
+	require.True(t, ok)
+	air, ok := goliath.GetAirWeapon()
+	require.True(t, ok)
+	require.Equal(t, NormalDamage, ground.DamageType)
+	require.Equal(t, ExplosiveDamage, air.DamageType)
+
+	_, ok = valkyrie.GetGroundWeapon()
+	require.False(t, ok, "Valkyries only shoot air")
+	require.Equal(t, 24, valkyrie.GetDamage(), "primary weapon falls back to air")
+
+	zealot.Shutdown()
+	goliath.Shutdown()
+	valkyrie.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_CanTarget(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	mutalisk := NewUnit("muta", Mutalisk, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{}, &wg)
+	valkyrie := NewUnit("valk", Valkyrie, Position{}, &wg)
+
+	require.NoError(t, zealot.CanTarget(zergling))
+	require.NoError(t, marine.CanTarget(mutalisk))
+	require.ErrorIs(t, zealot.CanTarget(mutalisk), ErrCannotTarget)
+	require.ErrorIs(t, valkyrie.CanTarget(zergling), ErrCannotTarget)
+	require.ErrorIs(t, zealot.CanTarget(nil), ErrNoTarget)
+
+	// Burrowed units can't be hit without detection
+	zergling.mu.Lock()
+	zergling.elevationLayer = Burrowed
+	zergling.mu.Unlock()
+	err := marine.CanTarget(zergling)
+	require.ErrorIs(t, err, ErrCannotTarget)
+
+	var targetErr *TargetingError
+	require.True(t, errors.As(err, &targetErr))
+	require.Equal(t, "marine", targetErr.UnitID)
+	require.Equal(t, "ling", targetErr.TargetID)
+	require.Equal(t, Burrowed, targetErr.Layer)
+
+	for _, u := range []*Unit{zealot, marine, mutalisk, zergling, valkyrie} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestUnit_CalculateDamageAgainst_NoWeaponForLayer(t *testing.T) {
+	var wg sync.WaitGroup
+	probe := NewUnit("probe", Probe, Position{}, &wg)
+	battlecruiser := NewUnit("bc", Battlecruiser, Position{}, &wg)
+
+	require.Equal(t, 0.0, probe.CalculateDamageAgainst(battlecruiser))
+
+	probe.Shutdown()
+	battlecruiser.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_HandleAttack_RejectsUntargetable(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	mutalisk := NewUnit("muta", Mutalisk, Position{}, &wg)
+
+	result := make(chan error, 1)
+	require.NoError(t, zealot.SendCommand(Command{Type: CmdAttack, Target: mutalisk, Result: result}))
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrCannotTarget)
+		require.NotErrorIs(t, err, ErrOutOfRange)
+	case <-time.After(time.Second):
+		t.Fatal("no command result")
+	}
+	require.Equal(t, Idle, zealot.GetState())
+	require.Equal(t, 120, mutalisk.GetHealth(), "Mutalisk must not take damage")
+
+	zealot.Shutdown()
+	mutalisk.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_CheckRange(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{X: 1.5}, &wg)
+	ultra := NewUnit("ultra", Ultralisk, Position{X: 1.9}, &wg)
+	far := NewUnit("far", Zergling, Position{X: 10}, &wg)
+
+	// Range 1 + 0.25 + 0.25 reaches exactly 1.5 to a Small target...
+	require.NoError(t, zealot.CheckRange(zergling))
+	// ...and further to a Large one (1 + 0.25 + 0.75)
+	require.NoError(t, zealot.CheckRange(ultra))
+
+	err := zealot.CheckRange(far)
+	require.ErrorIs(t, err, ErrOutOfRange)
+	require.True(t, strings.Contains(err.Error(), "distance 10.0 exceeds range 1"))
+
+	for _, u := range []*Unit{zealot, zergling, ultra, far} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestUnit_HandleAttack_ApproachesOutOfRangeTarget(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{X: 5, Y: 0}, &wg)
+
+	result := make(chan error, 1)
+	require.NoError(t, zealot.SendCommand(Command{Type: CmdAttack, Target: zergling, Result: result}))
+	require.NoError(t, <-result, "out of range is not a reason to refuse the order")
+	require.Equal(t, 35, zergling.GetHealth(), "no damage from across the field")
+
+	require.Eventually(t, func() bool {
+		return zergling.GetHealth() < 35
+	}, 3*time.Second, 10*time.Millisecond, "zealot should walk up and strike")
+	require.NoError(t, zealot.CheckRange(zergling))
+	require.Greater(t, zealot.GetPosition().X, 3.0)
+
+	zealot.Shutdown()
+	zergling.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_HandleAttack_RejectsTargetOutOfVision(t *testing.T) {
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{X: 20}, &wg)
+
+	result := make(chan error, 1)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttack, Target: zergling, Result: result}))
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrTargetNotVisible)
+		require.Contains(t, err.Error(), "beyond vision 7")
+	case <-time.After(time.Second):
+		t.Fatal("no command result")
+	}
+	require.Equal(t, Idle, marine.GetState())
+
+	marine.Shutdown()
+	zergling.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Attack_FailsWhenTargetLeavesVision(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("speedlings", strings.NewReader(`{"Zergling": {"speed": 20, "acceleration": 0}}`)))
+
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{X: 5}, &wg)
+
+	events := zealot.Subscribe(EventTypes(EventOrderFailed))
+
+	require.NoError(t, zealot.SendCommand(Command{Type: CmdAttack, Target: zergling}))
+	require.NoError(t, zergling.SendCommand(Command{Type: CmdMove, Dest: Position{X: 60}}))
+
+	ev := waitForEvent(t, events, EventOrderFailed)
+	err, ok := ev.Data.(error)
+	require.True(t, ok)
+	require.ErrorIs(t, err, ErrTargetNotVisible)
+	require.Equal(t, zergling, ev.Target)
+	require.Eventually(t, func() bool { return zealot.GetState() == Idle }, time.Second, 5*time.Millisecond)
+	require.Nil(t, zealot.GetTarget())
+
+	zealot.Shutdown()
+	zergling.Shutdown()
+	wg.Wait()
+}
+
+func TestWeaponStatsData_Validate(t *testing.T) {
+	err := WeaponStatsData{Damage: -1, DamageType: "Laser", Range: -2}.Validate("airWeapon")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "airWeapon.damage")
+	require.Contains(t, err.Error(), "airWeapon.range")
+	require.Contains(t, err.Error(), `unknown damageType "Laser"`)
+}
+
+func TestUnit_CalculateDamageAgainst_ArmorPerHit(t *testing.T) {
+	var wg sync.WaitGroup
+	zealot := NewUnit("zealot", Zealot, Position{}, &wg)
+	enemy := NewUnit("enemy", Zealot, Position{}, &wg)
+	enemy.TakeDamage(60) // Shields ignore hull armor; strip them first
+
+	require.Equal(t, 16, zealot.GetDamage(), "2 hits × 8")
+	require.Equal(t, 14.0, zealot.CalculateDamageAgainst(enemy), "2 hits × (8 - 1 armor)")
+
+	zealot.Shutdown()
+	enemy.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Attack_KeepsFiringUntilTargetDies(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("fast-marine", strings.NewReader(`{"Marine": {"groundWeapon": {"cooldown": 0.01}}}`)))
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg)
+	zergling := NewUnit("ling", Zergling, Position{X: 1}, &wg)
+
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttack, Target: zergling}))
+
+	require.Eventually(t, func() bool {
+		return zergling.GetHealthExact() == 0 && marine.GetState() == Idle
+	}, 2*time.Second, 10*time.Millisecond, "one order should be enough to kill the Zergling")
+	require.Nil(t, marine.GetTarget())
+
+	marine.Shutdown()
+	zergling.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Attack_RespectsCooldown(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	var wg sync.WaitGroup
+	dragoon := NewUnit("dragoon", Dragoon, Position{}, &wg, WithClock(clock)) // 1.26s cooldown
+	tank := NewUnit("tank", SiegeTank, Position{X: 1}, &wg, WithClock(clock))
+
+	require.NoError(t, sendOrder(t, dragoon, Command{Type: CmdAttack, Target: tank}))
+	advanceUntil(t, clock, func() bool { return tank.GetHealthExact() < 150 })
+	for i := 0; i < 7; i++ { // 300ms more, well inside the cooldown
+		clock.Advance(TickRate)
+	}
+	require.Equal(t, Attacking, dragoon.GetState())
+
+	// 20 explosive vs Large, 1 armor = 19 for exactly one volley so far
+	require.Equal(t, 150.0-19.0, tank.GetHealthExact())
+
+	dragoon.Shutdown()
+	tank.Shutdown()
+	wg.Wait()
+}
+
+func TestUnit_Ammo_SpendsAndReloads(t *testing.T) {
+	resetStatsAfter(t)
+	require.NoError(t, ApplyStatsOverride("fast-reaver", strings.NewReader(`
+Reaver:
+  groundWeapon:
+    cooldown: 0.01
+  ammo:
+    capacity: 2
+    reloadTime: 0.2
+Ultralisk:
+  healthRegen: 0
+`)))
+
+	var wg sync.WaitGroup
+	reaver := NewUnit("reaver", Reaver, Position{}, &wg)
+	ultra := NewUnit("ultra", Ultralisk, Position{X: 1}, &wg)
+	events := reaver.Subscribe(EventTypes(EventReloaded))
+
+	rounds, capacity, ok := reaver.GetAmmo()
+	require.True(t, ok)
+	require.Equal(t, 2, rounds)
+	require.Equal(t, 2, capacity)
+
+	require.NoError(t, reaver.SendCommand(Command{Type: CmdAttack, Target: ultra}))
+
+	// Two scarabs go out back to back, then the Reaver has to wait
+	require.Eventually(t, func() bool {
+		return ultra.GetHealthExact() == 400-2*99
+	}, time.Second, 5*time.Millisecond)
+
+	// Reloading emits EventReloaded and the Reaver fires again
+	ev := waitForEvent(t, events, EventReloaded)
+	require.Equal(t, reaver, ev.Source)
+	require.Eventually(t, func() bool {
+		return ultra.GetHealthExact() < 400-2*99
+	}, time.Second, 5*time.Millisecond)
+
+	reaver.Shutdown()
+	ultra.Shutdown()
+	wg.Wait()
+}
@@ -0,0 +1,296 @@
+package types
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// WORLD - Terrain, Vision and the High-Ground Rules
+// ═══════════════════════════════════════════════════════════════════════════
+//
+// A unit on its own only knows about itself. Add it to a World and it
+// picks up everything that depends on the rest of the battlefield:
+//
+//   - Vision: a faction sees what any of its units (or a faction sharing
+//     vision with it) sees. Ground units can't see up a cliff—tiles higher
+//     than the one they stand on block sight (see Map.LineOfSight). Air
+//     units see over everything.
+//   - Acquisition: a unit can only target what its side can see. No vision
+//     of the high ground, no shooting at it—bring a spotter.
+//   - Uphill attacks: a ranged attack from lower ground at a ground target
+//     on higher ground misses HighGroundMissChance of the time. Each roll
+//     is fixed by the world's seed, the shooter's ID and how many rolls
+//     that shooter has made, so with the same seed every shooter hits and
+//     misses in the same pattern however the goroutines interleave.
+//   - Auto-targeting: the World is the TargetScanner for attack-move and
+//     patrol, offering the nearest enemy the unit's side can see.
+//   - Splash: area weapons hit everything nearby, which takes knowing
+//     what's nearby (see splash.go).
+//   - Spells: area spells find their victims here, and Psionic Storms and
+//     Dark Swarms stay on the ground for a while (see spells.go).
+//
+// 🎴 MTG ANALOGY: Hexproof from the high ground. The creature's right
+// there, but you can't target it until something gives you a way to see it.
+//
+// ⚔️ SC:BW ANALOGY: Sieged Tanks on the high ground of Fighting Spirit's
+// naturals. Dragoons below can't fire back until an Observer shows up—and
+// even then half their shots miss.
+//
+// 🎓 LEARNING: The unit set changes (units join, units die) while unit
+// goroutines are asking vision questions, so it lives behind an RWMutex.
+// "Who's near here?" goes to a SpatialIndex of the same units instead of
+// walking all of them (see spatial.go).
+// There's no shared RNG to lock: one generator drawn from by every
+// goroutine hands out rolls in scheduling order, so a seed couldn't replay
+// a fight. Hashing (seed, shooter, roll number) gives each shooter its own
+// stream that no other goroutine can disturb.
+//
+// ═══════════════════════════════════════════════════════════════════════════
+
+// HighGroundMissChance is the chance a ranged attack fired uphill misses
+// (136 in 256)
+const HighGroundMissChance = 136.0 / 256
+
+// World is the shared battlefield: terrain, every unit on it, and the RNG
+type World struct {
+	terrain *Map // nil = flat open ground
+
+	mu           sync.RWMutex
+	units        map[string]*Unit
+	spatial      *SpatialIndex // The same units, by where they stand
+	maxVision    float64       // Longest vision range in the world; how far HasVision looks for viewers
+	friendlyFire bool          // Splash hits the shooter's own side too (see splash.go)
+	zones        []zone        // Storms and swarms on the ground (see spells.go)
+
+	seed int64 // Fixes every roll (see missRoll)
+}
+
+var _ TargetScanner = (*World)(nil)
+
+// NewWorld creates a world on terrain m (nil for flat ground). seed drives
+// every random roll: with equal seeds, each unit gets the same rolls in
+// the same order.
+func NewWorld(m *Map, seed int64) *World {
+	return &World{
+		terrain: m,
+		units:   make(map[string]*Unit),
+		spatial: NewSpatialIndex(0),
+		seed:    seed,
+	}
+}
+
+// Map returns the world's terrain; nil for flat ground
+func (w *World) Map() *Map {
+	return w.terrain
+}
+
+// Add puts u in the world and makes the world its TargetScanner
+func (w *World) Add(u *Unit) {
+	w.mu.Lock()
+	w.units[u.ID] = u
+	w.mu.Unlock()
+
+	u.mu.Lock()
+	u.world = w
+	u.scanner = w
+	vision := float64(u.visionRange)
+	u.mu.Unlock()
+
+	w.noteVision(vision)
+	w.spatial.Track(u)
+}
+
+// Remove takes u out of the world
+func (w *World) Remove(u *Unit) {
+	w.mu.Lock()
+	delete(w.units, u.ID)
+	w.mu.Unlock()
+	w.spatial.Untrack(u)
+
+	u.mu.Lock()
+	if u.world == w {
+		u.world = nil
+		u.scanner = nil
+	}
+	u.mu.Unlock()
+}
+
+// Unit returns the unit with the given ID, nil if it isn't in the world
+func (w *World) Unit(id string) *Unit {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.units[id]
+}
+
+// Units returns every unit in the world, ordered by ID
+func (w *World) Units() []*Unit {
+	w.mu.RLock()
+	units := make([]*Unit, 0, len(w.units))
+	for _, u := range w.units {
+		units = append(units, u)
+	}
+	w.mu.RUnlock()
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
+
+// noteVision widens how far HasVision looks for viewers to cover a unit
+// that sees vision tiles. It never shrinks: a wider search only costs a
+// few extra candidates.
+func (w *World) noteVision(vision float64) {
+	w.mu.Lock()
+	w.maxVision = max(w.maxVision, vision)
+	w.mu.Unlock()
+}
+
+// levelAt is the terrain level under pos; flat worlds are all level 0
+func (w *World) levelAt(pos Position) int {
+	if w.terrain == nil {
+		return 0
+	}
+	return w.terrain.LevelAt(pos)
+}
+
+// sees reports whether viewer itself can see pos: within vision range and,
+// for units on the ground, not looking up a cliff. Passengers in a
+// transport see nothing.
+func (w *World) sees(viewer *Unit, pos Position) bool {
+	viewer.mu.RLock()
+	from, vision, layer := viewer.position, float64(viewer.visionRange), viewer.elevationLayer
+	blind := viewer.state == Dead || viewer.carrier != nil
+	viewer.mu.RUnlock()
+
+	if blind || from.DistanceSq(pos) > vision*vision {
+		return false
+	}
+	if layer == Air || w.terrain == nil {
+		return true
+	}
+	return w.terrain.LineOfSight(from, pos)
+}
+
+// HasVision reports whether faction f can see pos through any of its own
+// units or those of a faction sharing vision with it
+func (w *World) HasVision(f *Faction, pos Position) bool {
+	if f == nil {
+		return false
+	}
+	w.mu.RLock()
+	reach := w.maxVision
+	w.mu.RUnlock()
+	for _, v := range w.spatial.InRadius(pos, reach, SpatialFilter{}) {
+		if f.SeesFor(v.owner) && w.sees(v, pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSee reports whether viewer's side can see target. Neutral units only
+// have their own eyes.
+func (w *World) CanSee(viewer, target *Unit) bool {
+	pos := target.GetPosition()
+	if w.sees(viewer, pos) {
+		return true
+	}
+	return w.HasVision(viewer.owner, pos)
+}
+
+// visionError explains why u can't target target, or nil if its side sees it
+func (w *World) visionError(u, target *Unit) error {
+	if w.CanSee(u, target) {
+		return nil
+	}
+	reason := "no vision of target"
+	if w.levelAt(target.GetPosition()) > w.levelAt(u.GetPosition()) {
+		reason = "no vision of high ground"
+	}
+	return &TargetingError{UnitID: u.ID, TargetID: target.ID, Layer: target.GetElevationLayer(), Reason: reason, Err: ErrTargetNotVisible}
+}
+
+// ScanForTarget offers the nearest enemy within u's vision range that u's
+// side can see and u can attack
+func (w *World) ScanForTarget(u *Unit) *Unit {
+	u.mu.RLock()
+	from, vision := u.position, float64(u.visionRange)
+	u.mu.RUnlock()
+
+	var best *Unit
+	bestDist := math.Inf(1)
+	for _, c := range w.spatial.InRadius(from, vision, SpatialFilter{}) {
+		if c == u || !IsEnemy(u, c) {
+			continue
+		}
+		d := from.DistanceSq(c.GetPosition())
+		if d >= bestDist {
+			continue
+		}
+		if u.attackError(c) != nil {
+			continue
+		}
+		best, bestDist = c, d
+	}
+	return best
+}
+
+// uphill reports whether an attack from attacker on target is fired up at
+// higher ground. Air units are above it all, on either end.
+func (w *World) uphill(attacker, target *Unit) bool {
+	if attacker.GetElevationLayer() == Air || target.GetElevationLayer() == Air {
+		return false
+	}
+	return w.levelAt(target.GetPosition()) > w.levelAt(attacker.GetPosition())
+}
+
+// rollMiss takes shooter's next roll and checks it against chance
+func (w *World) rollMiss(shooter *Unit, chance float64) bool {
+	shooter.mu.Lock()
+	n := shooter.rolls
+	shooter.rolls++
+	shooter.mu.Unlock()
+	return missRoll(w.seed, shooter.ID, n) < chance
+}
+
+// missRoll is roll number n of shooterID in a world seeded with seed: a
+// number in [0, 1) that depends on those three alone
+//
+// 💡 FNV-1a turns the ID into 64 bits; splitmix64's finalizer scrambles
+// the sum so neighbouring roll numbers give unrelated results.
+func missRoll(seed int64, shooterID string, n uint64) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(shooterID))
+	x := (uint64(seed) ^ h.Sum64()) + n*0x9E3779B97F4A7C15
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return float64(x>>11) / (1 << 53)
+}
+
+// missesUphill decides whether this shot is lost to the high ground.
+// Melee attacks walk up the ramp with the attacker and never miss.
+func (w *World) missesUphill(attacker, target *Unit, weapon Weapon) bool {
+	return !weapon.IsMelee() && w.uphill(attacker, target) && w.rollMiss(attacker, HighGroundMissChance)
+}
+
+// GetWorld returns the world the unit is in, nil if none
+func (u *Unit) GetWorld() *World {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.world
+}
+
+func (w *World) String() string {
+	name := "flat"
+	if w.terrain != nil {
+		name = w.terrain.Name
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return fmt.Sprintf("World(%s, %d units)", name, len(w.units))
+}
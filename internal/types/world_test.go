@@ -0,0 +1,253 @@
+package types
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cliffMapASCII is low ground on the left, a cliff at x=4, high ground on
+// the right
+const cliffMapASCII = `
+....^^^^
+....^^^^
+....^^^^
+`
+
+func newCliffWorld(t *testing.T, seed int64) *World {
+	t.Helper()
+	m, err := ParseMapASCII("cliff", strings.NewReader(cliffMapASCII))
+	require.NoError(t, err)
+	return NewWorld(m, seed)
+}
+
+func TestWorld_NoVisionOfHighGround(t *testing.T) {
+	world := newCliffWorld(t, 1)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{X: 2.5, Y: 1.5}, &wg, WithOwner(red))
+	zealot := NewUnit("zealot", Zealot, Position{X: 5.5, Y: 1.5}, &wg, WithOwner(blue))
+	world.Add(marine)
+	world.Add(zealot)
+	require.Equal(t, world, marine.GetWorld())
+
+	require.False(t, world.CanSee(marine, zealot), "can't see up the cliff")
+	require.True(t, world.CanSee(zealot, marine), "but the zealot can see down")
+
+	result := make(chan error, 1)
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttack, Target: zealot, Result: result}))
+	err := <-result
+	require.ErrorIs(t, err, ErrTargetNotVisible)
+	require.ErrorContains(t, err, "no vision of high ground")
+	require.Nil(t, world.ScanForTarget(marine))
+
+	// A spotter on the same side lifts the fog
+	vessel := NewUnit("vessel", ScienceVessel, Position{X: 3.5, Y: 0.5}, &wg, WithOwner(red))
+	world.Add(vessel)
+	require.True(t, world.HasVision(red, zealot.GetPosition()))
+	require.True(t, world.CanSee(marine, zealot))
+	require.Equal(t, zealot, world.ScanForTarget(marine))
+	require.NoError(t, marine.SendCommand(Command{Type: CmdAttack, Target: zealot, Result: result}))
+	require.NoError(t, <-result)
+
+	world.Remove(vessel)
+	require.Nil(t, vessel.GetWorld())
+	require.False(t, world.HasVision(red, zealot.GetPosition()))
+
+	for _, u := range []*Unit{marine, zealot, vessel} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestWorld_SharedVision(t *testing.T) {
+	world := newCliffWorld(t, 1)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	teal := NewFaction("p3", "Jaedong", Zerg, Teal)
+	blue := NewFaction("p2", "Bisu", Protoss, Blue)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{X: 2.5, Y: 1.5}, &wg, WithOwner(red))
+	overlord := NewUnit("overlord", Overlord, Position{X: 4.5, Y: 1.5}, &wg, WithOwner(teal))
+	zealot := NewUnit("zealot", Zealot, Position{X: 5.5, Y: 1.5}, &wg, WithOwner(blue))
+	for _, u := range []*Unit{marine, overlord, zealot} {
+		world.Add(u)
+	}
+
+	require.False(t, world.CanSee(marine, zealot), "an ally's Overlord isn't enough on its own")
+	teal.SetSharedVision(red, true)
+	require.True(t, world.CanSee(marine, zealot))
+
+	for _, u := range []*Unit{marine, overlord, zealot} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestWorld_ScanForTarget(t *testing.T) {
+	world := NewWorld(nil, 1)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithOwner(red))
+	medic := NewUnit("medic", Medic, Position{X: 1}, &wg, WithOwner(red))
+	near := NewUnit("near", Zergling, Position{X: 3}, &wg, WithOwner(blue))
+	far := NewUnit("far", Zergling, Position{X: 5}, &wg, WithOwner(blue))
+	outside := NewUnit("outside", Zergling, Position{X: 20}, &wg, WithOwner(blue))
+	units := []*Unit{marine, medic, near, far, outside}
+	for _, u := range units {
+		world.Add(u)
+	}
+	require.Equal(t, []*Unit{far, marine, medic, near, outside}, world.Units())
+
+	require.Equal(t, near, world.ScanForTarget(marine), "the closest enemy, never the Medic")
+	near.TakeDamage(1000)
+	require.Equal(t, far, world.ScanForTarget(marine), "dead units don't count")
+
+	for _, u := range units {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
+
+func TestWorld_VisionFollowsTheSpatialIndex(t *testing.T) {
+	clock := NewManualClock(clockStart)
+	world := NewWorld(nil, 1)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+
+	var wg sync.WaitGroup
+	marine := NewUnit("marine", Marine, Position{}, &wg, WithClock(clock), WithOwner(red))
+	ling := NewUnit("ling", Zergling, Position{X: 30}, &wg, WithClock(clock), WithOwner(blue))
+	world.Add(marine)
+	world.Add(ling)
+	require.Nil(t, world.ScanForTarget(marine))
+	require.False(t, world.HasVision(blue, Position{}))
+
+	// The ling runs in: its moves reach the index as it goes
+	require.NoError(t, sendOrder(t, ling, Command{Type: CmdMove, Dest: Position{X: 4}}))
+	advanceUntil(t, clock, func() bool { return world.ScanForTarget(marine) == ling })
+	advanceUntil(t, clock, func() bool { return world.HasVision(blue, Position{}) })
+
+	// A teleport has no EventMoved; the world still sees it at once
+	ling.SetPosition(Position{X: 50})
+	require.Nil(t, world.ScanForTarget(marine))
+	require.True(t, world.HasVision(blue, Position{X: 50}))
+
+	ling.SetPosition(Position{X: 2})
+	require.Equal(t, ling, world.ScanForTarget(marine))
+	world.Remove(ling)
+	require.Nil(t, world.ScanForTarget(marine), "removed units aren't offered")
+	require.False(t, world.HasVision(blue, Position{X: 2}))
+
+	marine.Shutdown()
+	ling.Shutdown()
+	wg.Wait()
+}
+
+// uphillShots has two Marines on low ground shoot at an Ultralisk on the
+// high ground (spotted by a Science Vessel) at the same time, and returns
+// each Marine's first n outcomes
+func uphillShots(t *testing.T, seed int64, n int) map[string][]UnitEventType {
+	t.Helper()
+	clock := NewManualClock(clockStart)
+	world := newCliffWorld(t, seed)
+	red := NewFaction("p1", "Flash", Terran, Red)
+	blue := NewFaction("p2", "Jaedong", Zerg, Blue)
+
+	var wg sync.WaitGroup
+	marines := []*Unit{
+		NewUnit("marine1", Marine, Position{X: 2.5, Y: 1.5}, &wg, WithClock(clock), WithOwner(red)),
+		NewUnit("marine2", Marine, Position{X: 2.5, Y: 0.5}, &wg, WithClock(clock), WithOwner(red)),
+	}
+	vessel := NewUnit("vessel", ScienceVessel, Position{X: 4.5, Y: 1.5}, &wg, WithClock(clock), WithOwner(red))
+	ultra := NewUnit("ultra", Ultralisk, Position{X: 5.5, Y: 1.5}, &wg, WithClock(clock), WithOwner(blue))
+	everyone := append([]*Unit{vessel, ultra}, marines...)
+	for _, u := range everyone {
+		world.Add(u)
+	}
+	shots := make(map[string]*Subscription)
+	for _, m := range marines {
+		shots[m.ID] = m.Subscribe(EventTypes(EventDamaged, EventMissed), WithBuffer(256))
+		require.NoError(t, sendOrder(t, m, Command{Type: CmdAttack, Target: ultra}))
+	}
+	clock.Advance(time.Duration(n) * time.Second)
+
+	got := make(map[string][]UnitEventType)
+	for id, sub := range shots {
+		for len(got[id]) < n {
+			select {
+			case ev := <-sub.Events():
+				got[id] = append(got[id], ev.Type)
+			case <-time.After(2 * time.Second):
+				t.Fatalf("%s: only %d of %d shots", id, len(got[id]), n)
+			}
+		}
+	}
+
+	for _, u := range everyone {
+		u.Shutdown()
+	}
+	wg.Wait()
+	return got
+}
+
+func TestWorld_HighGroundMissChance(t *testing.T) {
+	first := uphillShots(t, 42, 12)
+	for id, outcomes := range first {
+		require.Contains(t, outcomes, EventMissed, id)
+		require.Contains(t, outcomes, EventDamaged, id)
+	}
+	require.NotEqual(t, first["marine1"], first["marine2"], "each shooter rolls its own dice")
+	for i := 0; i < 3; i++ {
+		require.Equal(t, first, uphillShots(t, 42, 12), "same seed, same hits and misses for each shooter")
+	}
+	require.NotEqual(t, first, uphillShots(t, 43, 12))
+
+	// The roll itself lands near the published odds
+	world := NewWorld(nil, 7)
+	shooter := &Unit{ID: "marine"}
+	misses := 0
+	for i := 0; i < 10000; i++ {
+		if world.rollMiss(shooter, HighGroundMissChance) {
+			misses++
+		}
+	}
+	require.InDelta(t, HighGroundMissChance, float64(misses)/10000, 0.02)
+	require.Equal(t, uint64(10000), shooter.rolls)
+}
+
+func TestWorld_NoMissDownhillOrInMelee(t *testing.T) {
+	world := newCliffWorld(t, 1)
+	var wg sync.WaitGroup
+	low := NewUnit("low", Marine, Position{X: 2.5, Y: 1.5}, &wg)
+	high := NewUnit("high", Marine, Position{X: 5.5, Y: 1.5}, &wg)
+	lowLing := NewUnit("ling", Zergling, Position{X: 3.5, Y: 1.5}, &wg)
+	wraith := NewUnit("wraith", Wraith, Position{X: 2.5, Y: 0.5}, &wg)
+
+	marineGun, err := low.WeaponAgainst(high)
+	require.NoError(t, err)
+	claws, err := lowLing.WeaponAgainst(high)
+	require.NoError(t, err)
+	require.True(t, claws.IsMelee())
+	require.False(t, marineGun.IsMelee())
+
+	require.True(t, world.uphill(low, high))
+	require.False(t, world.uphill(high, low))
+	require.False(t, world.uphill(wraith, high), "air units don't fight uphill")
+	for i := 0; i < 100; i++ {
+		require.False(t, world.missesUphill(high, low, marineGun))
+		require.False(t, world.missesUphill(lowLing, high, claws))
+	}
+
+	for _, u := range []*Unit{low, high, lowLing, wraith} {
+		u.Shutdown()
+	}
+	wg.Wait()
+}
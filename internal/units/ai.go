@@ -348,7 +348,9 @@ func NewAggressiveStrategy(engagementRange, retreatThreshold float64) *Aggressiv
 func (as *AggressiveStrategy) ExecuteStrategy(ctx context.Context, faction *types.Faction, enemies []*types.Unit) []types.Command {
 	// TODO: Implement aggressive strategy logic
 	// Strategy should:
-	// 1. Find closest enemy
+	// 1. Find closest enemy (types.IsEnemy decides who counts; allies and
+	//    neutral units are never targets, and world.IsTargetable says
+	//    whether a cloaked or burrowed one is detected)
 	// 2. If in range, attack
 	// 3. If not in range, move to attack
 	// 4. If health low, retreat
@@ -433,6 +435,9 @@ type BattlefieldMap struct {
 	grid     [][]MapCell // 2D grid for spatial queries
 	gridSize float64     // Size of each grid cell
 
+	// Unit positions, kept current by each unit's EventMoved
+	units *types.SpatialIndex
+
 	// Cached spatial data
 	coverPoints []types.Position
 	chokePoints []types.Position
@@ -446,26 +451,37 @@ type MapCell struct {
 	Terrain    TerrainType
 	CoverValue float64
 	Visibility float64       // How visible this cell is
-	Units      []*types.Unit // Units currently in this cell
+	Units      []*types.Unit // Units currently in this cell (snapshot; bm.units is live)
 }
 
 // NewBattlefieldMap creates a new battlefield map
 func NewBattlefieldMap(width, height, gridSize float64) *BattlefieldMap {
 	// TODO: Implement battlefield map creation
 	// Initialize grid and spatial data structures
+	// The unit index uses the same cell size: types.NewSpatialIndex(gridSize)
 	return nil
 }
 
+// TrackUnit starts following a unit's position for radius queries
+func (bm *BattlefieldMap) TrackUnit(unit *types.Unit) {
+	// TODO: bm.units.Track(unit)
+	// The index subscribes to the unit's EventMoved, EventUnloaded and
+	// EventDied and drops it once it dies
+}
+
 // UpdateUnitPosition updates a unit's position in the spatial grid
 func (bm *BattlefieldMap) UpdateUnitPosition(unit *types.Unit, oldPos, newPos types.Position) {
 	// TODO: Implement spatial grid updates
-	// Move unit from old grid cell to new grid cell
+	// Tracked units move themselves (EventMoved); this is for moves that
+	// emit no event, like SetPosition: bm.units.Refresh(unit)
 }
 
 // GetUnitsInRadius returns units within a radius of a position
 func (bm *BattlefieldMap) GetUnitsInRadius(center types.Position, radius float64) []*types.Unit {
 	// TODO: Implement efficient spatial query
-	// Use grid to quickly find nearby units
+	// Use grid to quickly find nearby units:
+	// bm.units.InRadius(center, radius, types.SpatialFilter{})
+	// (bm.units.Nearest and InRect cover "closest k" and box selection)
 	return nil
 }
 
@@ -0,0 +1,28 @@
+package units
+
+import "time"
+
+// ═════════════════════════════════════════════════════════════════════════════
+// 📋 COMMAND PRIORITY: What SendCommand's priority Means
+// ═════════════════════════════════════════════════════════════════════════════
+//
+// The manager's commandQueue is a dispatch.Queue: the most urgent command
+// goes to the next free worker, waiting earns priority (aging), and each
+// unit's commands still run in order, one at a time. See
+// internal/dispatch/queue.go for how.
+// ═════════════════════════════════════════════════════════════════════════════
+
+// Command priorities for SendCommand and BroadcastCommand. Any int works;
+// these name the usual tiers.
+const (
+	PriorityRoutine   = 0   // Rally points, attack-moves, the everyday clicks
+	PriorityUrgent    = 50  // Focus fire, pulling a hurt unit back
+	PriorityEmergency = 100 // Retreat, evacuate—drop everything
+)
+
+// WithAging sets how long a queued command waits to gain one point of
+// priority (dispatch.DefaultAgingStep unless set). Zero or less turns aging
+// off (strict priority, FIFO within a priority).
+func WithAging(step time.Duration) ManagerOption {
+	return func(um *UnitManager) { um.agingStep = step }
+}
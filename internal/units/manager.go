@@ -2,9 +2,12 @@ package units
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/dispatch"
 	"github.com/AdonaIsium/sc_concurrency_challenge_personal/internal/types"
 )
 
@@ -97,15 +100,31 @@ type UnitManager struct {
 	eventListeners []chan UnitManagerEvent // 👀 Pub/Sub: State changes notify observers
 
 	// Worker pools (Bounded concurrency)
-	commandWorkers int                     // 🔢 How many "workers mining the patch"
-	commandQueue   chan QueuedCommand      // 📋 Work queue: Buffered channel = async
-	workerPool     chan chan QueuedCommand // 🏊 Pool: Available workers register here
+	commandWorkers int                            // 🔢 How many "workers mining the patch"
+	commandQueue   *dispatch.Queue[QueuedCommand] // 📋 Work queue: most urgent first, FIFO per unit
+	workerPool     chan chan QueuedCommand        // 🏊 Pool: Available workers register here
+	agingStep      time.Duration                  // ⏳ Wait that earns a queued command +1 priority
+
+	// Spatial queries
+	spatial *types.SpatialIndex // 🗺️ Grid of unit positions, kept current by EventMoved
 
 	// Lifecycle management
 	ctx       context.Context    // 🛑 Cancellation signal
 	cancel    context.CancelFunc // 🚨 Trigger shutdown
 	wg        *sync.WaitGroup    // ⏳ Wait for goroutines to finish
 	isRunning bool               // 🔴 State flag
+	clock     types.Clock        // ⏱️ Timestamps and timeouts (types.SystemClock unless overridden)
+}
+
+// ManagerOption configures a UnitManager in NewUnitManager
+type ManagerOption func(*UnitManager)
+
+// WithClock runs the manager on c instead of the wall clock. Pass the same
+// clock to the units (types.WithClock) so their timestamps line up.
+//
+// ⚔️ SC:BW: Every building and unit in a game reads the same frame counter.
+func WithClock(c types.Clock) ManagerOption {
+	return func(um *UnitManager) { um.clock = c }
 }
 
 // BroadcastCommand represents a command sent to multiple units
@@ -114,7 +133,8 @@ type UnitManager struct {
 //   - TargetIDs: Specific units (like control groups 1-9)
 //   - Predicate: Dynamic filter (like "all Marines with >50 HP")
 //   - MaxTargets: Limit (like "only 6 closest units to this location")
-//   - Priority: Urgent commands jump the queue (like pulling workers)
+//   - Priority: Urgent commands jump the queue (like pulling workers);
+//     see PriorityRoutine / PriorityUrgent / PriorityEmergency
 type BroadcastCommand struct {
 	Command    types.Command
 	TargetIDs  []string               // Empty = all units (F2 in SC2)
@@ -127,7 +147,7 @@ type BroadcastCommand struct {
 //
 // 💡 MTG ANALOGY: This is like spells on the stack waiting to resolve
 //   - Priority: Like split second spells vs. sorceries
-//   - Timestamp: When it was cast (for LIFO resolution)
+//   - Timestamp: When it was cast; waiting ages it toward the front (internal/dispatch)
 //   - Response: Like getting the result of "draw a card" asynchronously
 type QueuedCommand struct {
 	UnitID    string
@@ -163,6 +183,39 @@ const (
 	ManagerShutdown
 )
 
+// Manager errors. Wrapped in a *ManagerError naming the operation and unit.
+// A unit's own refusal (types.ErrUnitDead, a *types.CommandError...) is
+// passed through as-is, so errors.Is works the same at either layer.
+var (
+	ErrNilUnit         = errors.New("nil unit")
+	ErrEmptyUnitID     = errors.New("unit has empty ID")
+	ErrDuplicateUnit   = errors.New("unit already exists")
+	ErrUnitNotFound    = errors.New("unit not found")
+	ErrShutdownTimeout = errors.New("shutdown timed out")
+
+	// The manager's own stop and full-queue refusals
+	ErrShuttingDown = types.ErrShuttingDown
+	ErrBackpressure = types.ErrBackpressure
+)
+
+// ManagerError explains why the manager refused an operation
+type ManagerError struct {
+	Op     string // "AddUnit", "BroadcastCommand", "Shutdown", ...
+	UnitID string // Empty when no single unit is involved
+	Err    error
+}
+
+func (e *ManagerError) Error() string {
+	if e.UnitID == "" {
+		return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Op, e.UnitID, e.Err)
+}
+
+func (e *ManagerError) Unwrap() error {
+	return e.Err
+}
+
 // ═════════════════════════════════════════════════════════════════════════════
 // 🏗️ CONSTRUCTOR: NewUnitManager
 // ═════════════════════════════════════════════════════════════════════════════
@@ -188,6 +241,7 @@ const (
 //     - commandBroadcast: How many broadcast commands can queue? (burst capacity)
 //     - statusUpdates: How many status reports before blocking? (throughput)
 //     - commandQueue: How deep should the work queue be? (latency tolerance)
+//       (It's a dispatch.Queue, not a channel—see internal/dispatch for why)
 //     💡 Think about SC:BW command queuing—too small = units stutter, too large = delay
 //
 // Q3: Why spawn goroutines in the constructor instead of lazily on first use?
@@ -198,7 +252,7 @@ const (
 // ═══════════════════════════════════════════════════════════════════════════════
 
 // NewUnitManager creates a new unit manager with specified worker count
-func NewUnitManager(ctx context.Context, commandWorkers int) *UnitManager {
+func NewUnitManager(ctx context.Context, commandWorkers int, opts ...ManagerOption) *UnitManager {
 	// ┌─────────────────────────────────────────────────────────────────────┐
 	// │ 🥉 HINT LEVEL 1: The Strategic Overview                             │
 	// └─────────────────────────────────────────────────────────────────────┘
@@ -210,8 +264,11 @@ func NewUnitManager(ctx context.Context, commandWorkers int) *UnitManager {
 	// 3. Allocate channels with appropriate buffer sizes:
 	//    - commandBroadcast: 100 (handle bursts)
 	//    - statusUpdates: 1000 (high throughput from many units)
-	//    - commandQueue: 500 (deep work queue)
 	//    - workerPool: commandWorkers (one slot per worker)
+	//    Default the clock to types.SystemClock{} and agingStep to
+	//    dispatch.DefaultAgingStep, then apply opts
+	//    - commandQueue: dispatch.New[QueuedCommand] with room for 500 (deep work
+	//      queue), built AFTER opts so it sees the final clock and aging
 	// 4. Start THREE background goroutines (the "workers" of your Command Center):
 	//    - Status aggregator (fan-in from units)
 	//    - Command dispatcher (fan-out to units)
@@ -232,15 +289,21 @@ func NewUnitManager(ctx context.Context, commandWorkers int) *UnitManager {
 	//     units:            make(map[string]*types.Unit),
 	//     commandBroadcast: make(chan BroadcastCommand, 100),
 	//     statusUpdates:    make(chan types.StatusUpdate, 1000),
-	//     commandQueue:     make(chan QueuedCommand, 500),
 	//     workerPool:       make(chan chan QueuedCommand, commandWorkers),
 	//     eventListeners:   make([]chan UnitManagerEvent, 0, 10), // Pre-allocate
+	//     spatial:          types.NewSpatialIndex(0),
 	//     commandWorkers:   commandWorkers,
 	//     ctx:              childCtx,
 	//     cancel:           cancel,
 	//     wg:               wg,
 	//     isRunning:        true,
+	//     clock:            types.SystemClock{},
+	//     agingStep:        dispatch.DefaultAgingStep,
+	// }
+	// for _, opt := range opts {
+	//     opt(um)
 	// }
+	// um.commandQueue = dispatch.New[QueuedCommand](500, um.clock, um.agingStep)
 	//
 	// // Start the three pillars of the system
 	// go um.statusAggregator()     // Fan-in: Collect status from all units
@@ -260,15 +323,21 @@ func NewUnitManager(ctx context.Context, commandWorkers int) *UnitManager {
 	//     units:            make(map[string]*types.Unit),
 	//     commandBroadcast: make(chan BroadcastCommand, 100),
 	//     statusUpdates:    make(chan types.StatusUpdate, 1000),
-	//     commandQueue:     make(chan QueuedCommand, 500),
 	//     workerPool:       make(chan chan QueuedCommand, commandWorkers),
 	//     eventListeners:   make([]chan UnitManagerEvent, 0, 10),
+	//     spatial:          types.NewSpatialIndex(0), // Default cell size
 	//     commandWorkers:   commandWorkers,
 	//     ctx:              childCtx,
 	//     cancel:           cancel,
 	//     wg:               wg,
 	//     isRunning:        true,
+	//     clock:            types.SystemClock{},
+	//     agingStep:        dispatch.DefaultAgingStep,
+	// }
+	// for _, opt := range opts {
+	//     opt(um)
 	// }
+	// um.commandQueue = dispatch.New[QueuedCommand](500, um.clock, um.agingStep)
 	//
 	// // Launch background systems
 	// go um.statusAggregator()
@@ -310,7 +379,7 @@ func (um *UnitManager) AddUnit(unit *types.Unit) error {
 	// 1. Validate: unit != nil, unit has ID
 	// 2. Lock the manager (write lock)
 	// 3. Check if ID already exists (return error if duplicate)
-	// 4. Add to map
+	// 4. Add to map, and um.spatial.Track(unit) so range queries can find it
 	// 5. Unlock (defer is your friend!)
 	// 6. Start a goroutine to forward unit's status updates to manager
 	// 7. Notify event listeners (UnitAdded event)
@@ -323,20 +392,21 @@ func (um *UnitManager) AddUnit(unit *types.Unit) error {
 	// └─────────────────────────────────────────────────────────────────────┘
 	//
 	// if unit == nil {
-	//     return fmt.Errorf("cannot add nil unit")
+	//     return &ManagerError{Op: "AddUnit", Err: ErrNilUnit}
 	// }
 	//
 	// unitID := unit.GetID() // Assuming you implement this getter
 	// if unitID == "" {
-	//     return fmt.Errorf("unit has empty ID")
+	//     return &ManagerError{Op: "AddUnit", Err: ErrEmptyUnitID}
 	// }
 	//
 	// um.mu.Lock()
 	// if _, exists := um.units[unitID]; exists {
 	//     um.mu.Unlock()
-	//     return fmt.Errorf("unit %s already exists", unitID)
+	//     return &ManagerError{Op: "AddUnit", UnitID: unitID, Err: ErrDuplicateUnit}
 	// }
 	// um.units[unitID] = unit
+	// um.spatial.Track(unit) // Follows the unit's EventMoved from here on
 	// um.mu.Unlock()
 	//
 	// // Forward status updates from unit to manager
@@ -349,7 +419,7 @@ func (um *UnitManager) AddUnit(unit *types.Unit) error {
 	// um.notifyEventListeners(UnitManagerEvent{
 	//     Type: UnitAdded,
 	//     Data: unitID,
-	//     Timestamp: time.Now(),
+	//     Timestamp: um.clock.Now(),
 	// })
 	//
 	// return nil
@@ -375,8 +445,9 @@ func (um *UnitManager) RemoveUnit(unitID string) error {
 	//
 	// 1. Lock the manager
 	// 2. Check if unit exists
+	//    (missing? &ManagerError{Op: "RemoveUnit", UnitID: unitID, Err: ErrUnitNotFound})
 	// 3. Get reference to unit before deleting
-	// 4. Delete from map
+	// 4. Delete from map, and um.spatial.Untrack(unit)
 	// 5. Unlock
 	// 6. Shutdown the unit (it may have goroutines running!)
 	// 7. The goroutine forwarding status updates will exit when unit's channel closes
@@ -438,8 +509,20 @@ func (um *UnitManager) GetUnitsByType(unitType types.UnitType) []*types.Unit {
 // 💡 SC:BW: This is like selecting units in a screen area, or finding targets
 //
 //	for splash damage (Psi Storm, Siege Tank shot)
+//
+// 💭 SCALE: Checking Position.Distance() for every unit in um.units is fine
+// for 50 units and far too slow for 10,000. Ask um.spatial instead—it only
+// looks at the grid cells the circle touches (see types/spatial.go).
 func (um *UnitManager) GetUnitsInRange(center types.Position, radius float64) []*types.Unit {
-	// 🎯 YOUR IMPLEMENTATION HERE (use Position.Distance() from types.go)
+	// ┌─────────────────────────────────────────────────────────────────────┐
+	// │ 🥉 HINT: One call—the index already skips the dead and passengers   │
+	// └─────────────────────────────────────────────────────────────────────┘
+	// return um.spatial.InRadius(center, radius, types.SpatialFilter{})
+	//
+	// Only air units? Only the enemy's? Narrow the filter:
+	// types.SpatialFilter{Layers: []types.ElevationLayer{types.Air}, Factions: []*types.Faction{enemy}}
+
+	// 🎯 YOUR IMPLEMENTATION HERE:
 	return nil
 }
 
@@ -467,9 +550,9 @@ func (um *UnitManager) BroadcastCommand(bc BroadcastCommand) error {
 	// case um.commandBroadcast <- bc:
 	//     return nil
 	// case <-um.ctx.Done():
-	//     return fmt.Errorf("manager shutting down")
+	//     return &ManagerError{Op: "BroadcastCommand", Err: ErrShuttingDown}
 	// default:
-	//     return fmt.Errorf("command broadcast channel full")
+	//     return &ManagerError{Op: "BroadcastCommand", Err: ErrBackpressure}
 	// }
 
 	// 🎯 YOUR IMPLEMENTATION HERE:
@@ -498,15 +581,18 @@ func (um *UnitManager) SendCommand(unitID string, command types.Command, priorit
 	//         UnitID:    unitID,
 	//         Command:   command,
 	//         Priority:  priority,
-	//         Timestamp: time.Now(),
+	//         Timestamp: um.clock.Now(),
 	//         Response:  response,
 	//     }
-	//     select {
-	//     case um.commandQueue <- queuedCmd:
-	//         // Queued successfully, worker will process and send result
-	//     case <-um.ctx.Done():
-	//         response <- CommandResult{Success: false, Error: context.Canceled}
+	//     if err := um.commandQueue.Push(unitID, priority, queuedCmd.Timestamp, queuedCmd); err != nil {
+	//         // Full (ErrBackpressure) or shutting down (ErrShuttingDown)
+	//         response <- CommandResult{
+	//             Error:     &ManagerError{Op: "SendCommand", UnitID: unitID, Err: err},
+	//             UnitID:    unitID,
+	//             Timestamp: queuedCmd.Timestamp,
+	//         }
 	//     }
+	//     // Queued: a worker will process it and send the result
 	// }()
 	// return response
 
@@ -588,12 +674,13 @@ func (um *UnitManager) Shutdown(timeout time.Duration) error {
 	//
 	// 1. Set isRunning = false
 	// 2. Call um.cancel() to signal all goroutines via context
-	// 3. Close command channels (commandBroadcast, commandQueue)
+	// 3. Close commandBroadcast and the commandQueue; answer every command
+	//    close() hands back with ErrShuttingDown (callers are waiting on them)
 	// 4. Shutdown all units in the map
 	// 5. Wait for all goroutines with timeout:
 	//    - Create a done channel
 	//    - Start goroutine that does wg.Wait() then closes done
-	//    - Select between done and time.After(timeout)
+	//    - Select between done and um.clock.After(timeout)
 	// 6. Close event listener channels
 	// 7. Return error if timeout, nil if clean shutdown
 
@@ -606,7 +693,12 @@ func (um *UnitManager) Shutdown(timeout time.Duration) error {
 	//
 	// // Close channels to unblock senders
 	// close(um.commandBroadcast)
-	// close(um.commandQueue)
+	// for _, cmd := range um.commandQueue.Close() {
+	//     cmd.Response <- CommandResult{
+	//         Error:  &ManagerError{Op: "SendCommand", UnitID: cmd.UnitID, Err: ErrShuttingDown},
+	//         UnitID: cmd.UnitID,
+	//     }
+	// }
 	//
 	// // Shutdown all units
 	// um.mu.Lock()
@@ -614,6 +706,7 @@ func (um *UnitManager) Shutdown(timeout time.Duration) error {
 	//     unit.Shutdown() // Assuming Unit has Shutdown method
 	// }
 	// um.mu.Unlock()
+	// um.spatial.Close() // Stops following the units' events
 	//
 	// // Wait with timeout
 	// done := make(chan struct{})
@@ -625,8 +718,8 @@ func (um *UnitManager) Shutdown(timeout time.Duration) error {
 	// select {
 	// case <-done:
 	//     // Clean shutdown
-	// case <-time.After(timeout):
-	//     return fmt.Errorf("shutdown timeout after %v", timeout)
+	// case <-um.clock.After(timeout):
+	//     return &ManagerError{Op: "Shutdown", Err: fmt.Errorf("%w after %v", ErrShutdownTimeout, timeout)}
 	// }
 	//
 	// // Close event listener channels
@@ -674,9 +767,12 @@ func (um *UnitManager) Shutdown(timeout time.Duration) error {
 // THE PATTERN:
 // 1. Create N worker goroutines
 // 2. Each worker registers itself in the workerPool channel
-// 3. When a command arrives in commandQueue, grab an available worker
+// 3. Grab an available worker, then pop the most urgent command for it
 // 4. Send the command to that worker's personal channel
-// 5. Worker processes, then re-registers itself (ready for next command)
+// 5. Worker processes, marks the unit done, then re-registers itself
+//
+// Worker first, THEN pop: the command is chosen the moment someone can run
+// it, so an Emergency command that arrived a second ago still wins.
 func (um *UnitManager) startWorkerPool() {
 	// ┌─────────────────────────────────────────────────────────────────────┐
 	// │ 🥉 HINT LEVEL 1: The Pool Manager                                   │
@@ -685,13 +781,14 @@ func (um *UnitManager) startWorkerPool() {
 	// This function does TWO things:
 	// A) Spawn um.commandWorkers worker goroutines
 	// B) Distribute work from commandQueue to available workers
+	//    (pop blocks until there's a command whose unit isn't busy)
 	//
 	// Sequence:
 	// 1. Add to WaitGroup (for shutdown coordination)
 	// 2. Spawn commandWorkers goroutines running um.commandWorker(id)
 	// 3. Loop until context cancelled:
-	//    a. Pull command from commandQueue
-	//    b. Get available worker from workerPool
+	//    a. Get available worker from workerPool
+	//    b. Pop the most urgent command: um.commandQueue.Pop(um.ctx)
 	//    c. Send command to that worker's channel
 	// 4. When context cancelled, close workerPool and exit
 
@@ -709,19 +806,18 @@ func (um *UnitManager) startWorkerPool() {
 	//
 	// // Distribute work
 	// for {
+	//     var workerChan chan QueuedCommand
 	//     select {
-	//     case cmd := <-um.commandQueue:
-	//         // Got a command, need a worker
-	//         select {
-	//         case workerChan := <-um.workerPool:
-	//             // Got a worker, send them the command
-	//             workerChan <- cmd
-	//         case <-um.ctx.Done():
-	//             return
-	//         }
+	//     case workerChan = <-um.workerPool:
+	//         // Got a worker, now find it the most urgent command
 	//     case <-um.ctx.Done():
 	//         return
 	//     }
+	//     cmd, ok := um.commandQueue.Pop(um.ctx)
+	//     if !ok {
+	//         return // Cancelled or closed
+	//     }
+	//     workerChan <- cmd
 	// }
 
 	// 🎯 YOUR IMPLEMENTATION HERE:
@@ -737,8 +833,9 @@ func (um *UnitManager) startWorkerPool() {
 // 2. Registers in workerPool (says "I'm available!")
 // 3. Waits for work on its channel
 // 4. Processes the command
-// 5. Sends result back via response channel
-// 6. Re-registers (goes back to step 2)
+// 5. Tells the queue the unit is done (its next command may go out)
+// 6. Sends result back via response channel
+// 7. Re-registers (goes back to step 2)
 func (um *UnitManager) commandWorker(workerID int) {
 	// ┌─────────────────────────────────────────────────────────────────────┐
 	// │ 🥉 HINT LEVEL 1: The Worker Loop                                    │
@@ -763,6 +860,7 @@ func (um *UnitManager) commandWorker(workerID int) {
 	//     case cmd := <-workChan:
 	//         // Process command (find unit, send command, return result)
 	//         result := um.processCommand(cmd)
+	//         um.commandQueue.Done(cmd.UnitID) // Release the unit's lane
 	//         cmd.Response <- result
 	//     case <-um.ctx.Done():
 	//         return
@@ -781,9 +879,9 @@ func (um *UnitManager) processCommand(cmd QueuedCommand) CommandResult {
 	// if !exists {
 	//     return CommandResult{
 	//         Success:   false,
-	//         Error:     fmt.Errorf("unit %s not found", cmd.UnitID),
+	//         Error:     &ManagerError{Op: "SendCommand", UnitID: cmd.UnitID, Err: ErrUnitNotFound},
 	//         UnitID:    cmd.UnitID,
-	//         Timestamp: time.Now(),
+	//         Timestamp: um.clock.Now(),
 	//     }
 	// }
 	//
@@ -792,7 +890,7 @@ func (um *UnitManager) processCommand(cmd QueuedCommand) CommandResult {
 	//     Success:   err == nil,
 	//     Error:     err,
 	//     UnitID:    cmd.UnitID,
-	//     Timestamp: time.Now(),
+	//     Timestamp: um.clock.Now(),
 	// }
 
 	// 🎯 YOUR IMPLEMENTATION HERE:
@@ -832,21 +930,19 @@ func (um *UnitManager) commandDispatcher() {
 	//                 UnitID:    unit.GetID(),
 	//                 Command:   bc.Command,
 	//                 Priority:  bc.Priority,
-	//                 Timestamp: time.Now(),
+	//                 Timestamp: um.clock.Now(),
 	//                 Response:  make(chan CommandResult, 1),
 	//             }
-	//             // Send to queue (non-blocking)
-	//             select {
-	//             case um.commandQueue <- queuedCmd:
-	//             default:
-	//                 // Queue full, drop or log error
+	//             // Queue it (never blocks: full → ErrBackpressure)
+	//             if err := um.commandQueue.Push(queuedCmd.UnitID, bc.Priority, queuedCmd.Timestamp, queuedCmd); err != nil {
+	//                 // Queue full or closing, drop or log error
 	//             }
 	//         }
 	//         // Notify listeners
 	//         um.notifyEventListeners(UnitManagerEvent{
 	//             Type: CommandBroadcast,
 	//             Data: bc,
-	//             Timestamp: time.Now(),
+	//             Timestamp: um.clock.Now(),
 	//         })
 	//     case <-um.ctx.Done():
 	//         return
@@ -885,7 +981,7 @@ func (um *UnitManager) statusAggregator() {
 	//         um.notifyEventListeners(UnitManagerEvent{
 	//             Type: StatusUpdateReceived,
 	//             Data: status,
-	//             Timestamp: time.Now(),
+	//             Timestamp: um.clock.Now(),
 	//         })
 	//     case <-um.ctx.Done():
 	//         return